@@ -0,0 +1,732 @@
+// API gRPC de Fuego de Quasar. Replica POST /topsecret y los endpoints de
+// /topsecret_split sobre el mismo repositorio y los mismos cálculos.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: api/quasar/v1/quasar.proto
+
+package quasarv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Position struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	X             float32                `protobuf:"fixed32,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y             float32                `protobuf:"fixed32,2,opt,name=y,proto3" json:"y,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Position) Reset() {
+	*x = Position{}
+	mi := &file_api_quasar_v1_quasar_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Position) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Position) ProtoMessage() {}
+
+func (x *Position) ProtoReflect() protoreflect.Message {
+	mi := &file_api_quasar_v1_quasar_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Position.ProtoReflect.Descriptor instead.
+func (*Position) Descriptor() ([]byte, []int) {
+	return file_api_quasar_v1_quasar_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Position) GetX() float32 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Position) GetY() float32 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+type SatelliteReading struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Distance      float32                `protobuf:"fixed32,2,opt,name=distance,proto3" json:"distance,omitempty"`
+	Message       []string               `protobuf:"bytes,3,rep,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SatelliteReading) Reset() {
+	*x = SatelliteReading{}
+	mi := &file_api_quasar_v1_quasar_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SatelliteReading) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SatelliteReading) ProtoMessage() {}
+
+func (x *SatelliteReading) ProtoReflect() protoreflect.Message {
+	mi := &file_api_quasar_v1_quasar_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SatelliteReading.ProtoReflect.Descriptor instead.
+func (*SatelliteReading) Descriptor() ([]byte, []int) {
+	return file_api_quasar_v1_quasar_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SatelliteReading) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SatelliteReading) GetDistance() float32 {
+	if x != nil {
+		return x.Distance
+	}
+	return 0
+}
+
+func (x *SatelliteReading) GetMessage() []string {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+type SkippedSatellite struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	Detail        string                 `protobuf:"bytes,3,opt,name=detail,proto3" json:"detail,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SkippedSatellite) Reset() {
+	*x = SkippedSatellite{}
+	mi := &file_api_quasar_v1_quasar_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SkippedSatellite) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SkippedSatellite) ProtoMessage() {}
+
+func (x *SkippedSatellite) ProtoReflect() protoreflect.Message {
+	mi := &file_api_quasar_v1_quasar_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SkippedSatellite.ProtoReflect.Descriptor instead.
+func (*SkippedSatellite) Descriptor() ([]byte, []int) {
+	return file_api_quasar_v1_quasar_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SkippedSatellite) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SkippedSatellite) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *SkippedSatellite) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+type LocateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Satellites    []*SatelliteReading    `protobuf:"bytes,1,rep,name=satellites,proto3" json:"satellites,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LocateRequest) Reset() {
+	*x = LocateRequest{}
+	mi := &file_api_quasar_v1_quasar_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LocateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocateRequest) ProtoMessage() {}
+
+func (x *LocateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_quasar_v1_quasar_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocateRequest.ProtoReflect.Descriptor instead.
+func (*LocateRequest) Descriptor() ([]byte, []int) {
+	return file_api_quasar_v1_quasar_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *LocateRequest) GetSatellites() []*SatelliteReading {
+	if x != nil {
+		return x.Satellites
+	}
+	return nil
+}
+
+type LocateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Position      *Position              `protobuf:"bytes,1,opt,name=position,proto3" json:"position,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Skipped       []*SkippedSatellite    `protobuf:"bytes,3,rep,name=skipped,proto3" json:"skipped,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LocateResponse) Reset() {
+	*x = LocateResponse{}
+	mi := &file_api_quasar_v1_quasar_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LocateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocateResponse) ProtoMessage() {}
+
+func (x *LocateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_quasar_v1_quasar_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocateResponse.ProtoReflect.Descriptor instead.
+func (*LocateResponse) Descriptor() ([]byte, []int) {
+	return file_api_quasar_v1_quasar_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *LocateResponse) GetPosition() *Position {
+	if x != nil {
+		return x.Position
+	}
+	return nil
+}
+
+func (x *LocateResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *LocateResponse) GetSkipped() []*SkippedSatellite {
+	if x != nil {
+		return x.Skipped
+	}
+	return nil
+}
+
+type SubmitReadingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SatelliteName string                 `protobuf:"bytes,1,opt,name=satellite_name,json=satelliteName,proto3" json:"satellite_name,omitempty"`
+	Distance      float32                `protobuf:"fixed32,2,opt,name=distance,proto3" json:"distance,omitempty"`
+	Message       []string               `protobuf:"bytes,3,rep,name=message,proto3" json:"message,omitempty"`
+	// if_match tiene el mismo efecto que la cabecera If-Match
+	IfMatch       string `protobuf:"bytes,4,opt,name=if_match,json=ifMatch,proto3" json:"if_match,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitReadingRequest) Reset() {
+	*x = SubmitReadingRequest{}
+	mi := &file_api_quasar_v1_quasar_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitReadingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitReadingRequest) ProtoMessage() {}
+
+func (x *SubmitReadingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_quasar_v1_quasar_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitReadingRequest.ProtoReflect.Descriptor instead.
+func (*SubmitReadingRequest) Descriptor() ([]byte, []int) {
+	return file_api_quasar_v1_quasar_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SubmitReadingRequest) GetSatelliteName() string {
+	if x != nil {
+		return x.SatelliteName
+	}
+	return ""
+}
+
+func (x *SubmitReadingRequest) GetDistance() float32 {
+	if x != nil {
+		return x.Distance
+	}
+	return 0
+}
+
+func (x *SubmitReadingRequest) GetMessage() []string {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *SubmitReadingRequest) GetIfMatch() string {
+	if x != nil {
+		return x.IfMatch
+	}
+	return ""
+}
+
+type SubmitReadingResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Etag          string                 `protobuf:"bytes,1,opt,name=etag,proto3" json:"etag,omitempty"`
+	Version       uint64                 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitReadingResponse) Reset() {
+	*x = SubmitReadingResponse{}
+	mi := &file_api_quasar_v1_quasar_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitReadingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitReadingResponse) ProtoMessage() {}
+
+func (x *SubmitReadingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_quasar_v1_quasar_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitReadingResponse.ProtoReflect.Descriptor instead.
+func (*SubmitReadingResponse) Descriptor() ([]byte, []int) {
+	return file_api_quasar_v1_quasar_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SubmitReadingResponse) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+func (x *SubmitReadingResponse) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type WatchResultRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchResultRequest) Reset() {
+	*x = WatchResultRequest{}
+	mi := &file_api_quasar_v1_quasar_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchResultRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchResultRequest) ProtoMessage() {}
+
+func (x *WatchResultRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_quasar_v1_quasar_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchResultRequest.ProtoReflect.Descriptor instead.
+func (*WatchResultRequest) Descriptor() ([]byte, []int) {
+	return file_api_quasar_v1_quasar_proto_rawDescGZIP(), []int{7}
+}
+
+// Waiting indica que todavía no se puede calcular la posición y el mensaje
+type Waiting struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Error string                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	// waiting_for son los satélites de los que todavía no hay lectura
+	WaitingFor    []string            `protobuf:"bytes,2,rep,name=waiting_for,json=waitingFor,proto3" json:"waiting_for,omitempty"`
+	Skipped       []*SkippedSatellite `protobuf:"bytes,3,rep,name=skipped,proto3" json:"skipped,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Waiting) Reset() {
+	*x = Waiting{}
+	mi := &file_api_quasar_v1_quasar_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Waiting) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Waiting) ProtoMessage() {}
+
+func (x *Waiting) ProtoReflect() protoreflect.Message {
+	mi := &file_api_quasar_v1_quasar_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Waiting.ProtoReflect.Descriptor instead.
+func (*Waiting) Descriptor() ([]byte, []int) {
+	return file_api_quasar_v1_quasar_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Waiting) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Waiting) GetWaitingFor() []string {
+	if x != nil {
+		return x.WaitingFor
+	}
+	return nil
+}
+
+func (x *Waiting) GetSkipped() []*SkippedSatellite {
+	if x != nil {
+		return x.Skipped
+	}
+	return nil
+}
+
+type WatchResultResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// seq es la secuencia del último cambio incluido; 0 en el estado inicial
+	Seq uint64 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	// Types that are valid to be assigned to Result:
+	//
+	//	*WatchResultResponse_Location
+	//	*WatchResultResponse_Waiting
+	Result        isWatchResultResponse_Result `protobuf_oneof:"result"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchResultResponse) Reset() {
+	*x = WatchResultResponse{}
+	mi := &file_api_quasar_v1_quasar_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchResultResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchResultResponse) ProtoMessage() {}
+
+func (x *WatchResultResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_quasar_v1_quasar_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchResultResponse.ProtoReflect.Descriptor instead.
+func (*WatchResultResponse) Descriptor() ([]byte, []int) {
+	return file_api_quasar_v1_quasar_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *WatchResultResponse) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *WatchResultResponse) GetResult() isWatchResultResponse_Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *WatchResultResponse) GetLocation() *LocateResponse {
+	if x != nil {
+		if x, ok := x.Result.(*WatchResultResponse_Location); ok {
+			return x.Location
+		}
+	}
+	return nil
+}
+
+func (x *WatchResultResponse) GetWaiting() *Waiting {
+	if x != nil {
+		if x, ok := x.Result.(*WatchResultResponse_Waiting); ok {
+			return x.Waiting
+		}
+	}
+	return nil
+}
+
+type isWatchResultResponse_Result interface {
+	isWatchResultResponse_Result()
+}
+
+type WatchResultResponse_Location struct {
+	Location *LocateResponse `protobuf:"bytes,2,opt,name=location,proto3,oneof"`
+}
+
+type WatchResultResponse_Waiting struct {
+	Waiting *Waiting `protobuf:"bytes,3,opt,name=waiting,proto3,oneof"`
+}
+
+func (*WatchResultResponse_Location) isWatchResultResponse_Result() {}
+
+func (*WatchResultResponse_Waiting) isWatchResultResponse_Result() {}
+
+var File_api_quasar_v1_quasar_proto protoreflect.FileDescriptor
+
+const file_api_quasar_v1_quasar_proto_rawDesc = "" +
+	"\n" +
+	"\x1aapi/quasar/v1/quasar.proto\x12\x10fuegodequasar.v1\"&\n" +
+	"\bPosition\x12\f\n" +
+	"\x01x\x18\x01 \x01(\x02R\x01x\x12\f\n" +
+	"\x01y\x18\x02 \x01(\x02R\x01y\"\\\n" +
+	"\x10SatelliteReading\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\bdistance\x18\x02 \x01(\x02R\bdistance\x12\x18\n" +
+	"\amessage\x18\x03 \x03(\tR\amessage\"V\n" +
+	"\x10SkippedSatellite\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12\x16\n" +
+	"\x06detail\x18\x03 \x01(\tR\x06detail\"S\n" +
+	"\rLocateRequest\x12B\n" +
+	"\n" +
+	"satellites\x18\x01 \x03(\v2\".fuegodequasar.v1.SatelliteReadingR\n" +
+	"satellites\"\xa0\x01\n" +
+	"\x0eLocateResponse\x126\n" +
+	"\bposition\x18\x01 \x01(\v2\x1a.fuegodequasar.v1.PositionR\bposition\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12<\n" +
+	"\askipped\x18\x03 \x03(\v2\".fuegodequasar.v1.SkippedSatelliteR\askipped\"\x8e\x01\n" +
+	"\x14SubmitReadingRequest\x12%\n" +
+	"\x0esatellite_name\x18\x01 \x01(\tR\rsatelliteName\x12\x1a\n" +
+	"\bdistance\x18\x02 \x01(\x02R\bdistance\x12\x18\n" +
+	"\amessage\x18\x03 \x03(\tR\amessage\x12\x19\n" +
+	"\bif_match\x18\x04 \x01(\tR\aifMatch\"E\n" +
+	"\x15SubmitReadingResponse\x12\x12\n" +
+	"\x04etag\x18\x01 \x01(\tR\x04etag\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x04R\aversion\"\x14\n" +
+	"\x12WatchResultRequest\"~\n" +
+	"\aWaiting\x12\x14\n" +
+	"\x05error\x18\x01 \x01(\tR\x05error\x12\x1f\n" +
+	"\vwaiting_for\x18\x02 \x03(\tR\n" +
+	"waitingFor\x12<\n" +
+	"\askipped\x18\x03 \x03(\v2\".fuegodequasar.v1.SkippedSatelliteR\askipped\"\xa8\x01\n" +
+	"\x13WatchResultResponse\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x04R\x03seq\x12>\n" +
+	"\blocation\x18\x02 \x01(\v2 .fuegodequasar.v1.LocateResponseH\x00R\blocation\x125\n" +
+	"\awaiting\x18\x03 \x01(\v2\x19.fuegodequasar.v1.WaitingH\x00R\awaitingB\b\n" +
+	"\x06result2\x9c\x02\n" +
+	"\rQuasarService\x12K\n" +
+	"\x06Locate\x12\x1f.fuegodequasar.v1.LocateRequest\x1a .fuegodequasar.v1.LocateResponse\x12`\n" +
+	"\rSubmitReading\x12&.fuegodequasar.v1.SubmitReadingRequest\x1a'.fuegodequasar.v1.SubmitReadingResponse\x12\\\n" +
+	"\vWatchResult\x12$.fuegodequasar.v1.WatchResultRequest\x1a%.fuegodequasar.v1.WatchResultResponse0\x01B&Z$fuegodequasar/api/quasar/v1;quasarv1b\x06proto3"
+
+var (
+	file_api_quasar_v1_quasar_proto_rawDescOnce sync.Once
+	file_api_quasar_v1_quasar_proto_rawDescData []byte
+)
+
+func file_api_quasar_v1_quasar_proto_rawDescGZIP() []byte {
+	file_api_quasar_v1_quasar_proto_rawDescOnce.Do(func() {
+		file_api_quasar_v1_quasar_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_quasar_v1_quasar_proto_rawDesc), len(file_api_quasar_v1_quasar_proto_rawDesc)))
+	})
+	return file_api_quasar_v1_quasar_proto_rawDescData
+}
+
+var file_api_quasar_v1_quasar_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_api_quasar_v1_quasar_proto_goTypes = []any{
+	(*Position)(nil),              // 0: fuegodequasar.v1.Position
+	(*SatelliteReading)(nil),      // 1: fuegodequasar.v1.SatelliteReading
+	(*SkippedSatellite)(nil),      // 2: fuegodequasar.v1.SkippedSatellite
+	(*LocateRequest)(nil),         // 3: fuegodequasar.v1.LocateRequest
+	(*LocateResponse)(nil),        // 4: fuegodequasar.v1.LocateResponse
+	(*SubmitReadingRequest)(nil),  // 5: fuegodequasar.v1.SubmitReadingRequest
+	(*SubmitReadingResponse)(nil), // 6: fuegodequasar.v1.SubmitReadingResponse
+	(*WatchResultRequest)(nil),    // 7: fuegodequasar.v1.WatchResultRequest
+	(*Waiting)(nil),               // 8: fuegodequasar.v1.Waiting
+	(*WatchResultResponse)(nil),   // 9: fuegodequasar.v1.WatchResultResponse
+}
+var file_api_quasar_v1_quasar_proto_depIdxs = []int32{
+	1, // 0: fuegodequasar.v1.LocateRequest.satellites:type_name -> fuegodequasar.v1.SatelliteReading
+	0, // 1: fuegodequasar.v1.LocateResponse.position:type_name -> fuegodequasar.v1.Position
+	2, // 2: fuegodequasar.v1.LocateResponse.skipped:type_name -> fuegodequasar.v1.SkippedSatellite
+	2, // 3: fuegodequasar.v1.Waiting.skipped:type_name -> fuegodequasar.v1.SkippedSatellite
+	4, // 4: fuegodequasar.v1.WatchResultResponse.location:type_name -> fuegodequasar.v1.LocateResponse
+	8, // 5: fuegodequasar.v1.WatchResultResponse.waiting:type_name -> fuegodequasar.v1.Waiting
+	3, // 6: fuegodequasar.v1.QuasarService.Locate:input_type -> fuegodequasar.v1.LocateRequest
+	5, // 7: fuegodequasar.v1.QuasarService.SubmitReading:input_type -> fuegodequasar.v1.SubmitReadingRequest
+	7, // 8: fuegodequasar.v1.QuasarService.WatchResult:input_type -> fuegodequasar.v1.WatchResultRequest
+	4, // 9: fuegodequasar.v1.QuasarService.Locate:output_type -> fuegodequasar.v1.LocateResponse
+	6, // 10: fuegodequasar.v1.QuasarService.SubmitReading:output_type -> fuegodequasar.v1.SubmitReadingResponse
+	9, // 11: fuegodequasar.v1.QuasarService.WatchResult:output_type -> fuegodequasar.v1.WatchResultResponse
+	9, // [9:12] is the sub-list for method output_type
+	6, // [6:9] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_api_quasar_v1_quasar_proto_init() }
+func file_api_quasar_v1_quasar_proto_init() {
+	if File_api_quasar_v1_quasar_proto != nil {
+		return
+	}
+	file_api_quasar_v1_quasar_proto_msgTypes[9].OneofWrappers = []any{
+		(*WatchResultResponse_Location)(nil),
+		(*WatchResultResponse_Waiting)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_quasar_v1_quasar_proto_rawDesc), len(file_api_quasar_v1_quasar_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_quasar_v1_quasar_proto_goTypes,
+		DependencyIndexes: file_api_quasar_v1_quasar_proto_depIdxs,
+		MessageInfos:      file_api_quasar_v1_quasar_proto_msgTypes,
+	}.Build()
+	File_api_quasar_v1_quasar_proto = out.File
+	file_api_quasar_v1_quasar_proto_goTypes = nil
+	file_api_quasar_v1_quasar_proto_depIdxs = nil
+}
@@ -0,0 +1,216 @@
+// API gRPC de Fuego de Quasar. Replica POST /topsecret y los endpoints de
+// /topsecret_split sobre el mismo repositorio y los mismos cálculos.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: api/quasar/v1/quasar.proto
+
+package quasarv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	QuasarService_Locate_FullMethodName        = "/fuegodequasar.v1.QuasarService/Locate"
+	QuasarService_SubmitReading_FullMethodName = "/fuegodequasar.v1.QuasarService/SubmitReading"
+	QuasarService_WatchResult_FullMethodName   = "/fuegodequasar.v1.QuasarService/WatchResult"
+)
+
+// QuasarServiceClient is the client API for QuasarService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type QuasarServiceClient interface {
+	// Locate guarda las lecturas recibidas y devuelve la posición y el mensaje,
+	// igual que POST /topsecret
+	Locate(ctx context.Context, in *LocateRequest, opts ...grpc.CallOption) (*LocateResponse, error)
+	// SubmitReading guarda la lectura de un satélite, igual que
+	// POST /topsecret_split/{satellite_name}
+	SubmitReading(ctx context.Context, in *SubmitReadingRequest, opts ...grpc.CallOption) (*SubmitReadingResponse, error)
+	// WatchResult envía el estado actual del flujo dividido y lo recalcula cada
+	// vez que llega o se borra una lectura, igual que GET /stream/topsecret_split
+	WatchResult(ctx context.Context, in *WatchResultRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchResultResponse], error)
+}
+
+type quasarServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewQuasarServiceClient(cc grpc.ClientConnInterface) QuasarServiceClient {
+	return &quasarServiceClient{cc}
+}
+
+func (c *quasarServiceClient) Locate(ctx context.Context, in *LocateRequest, opts ...grpc.CallOption) (*LocateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LocateResponse)
+	err := c.cc.Invoke(ctx, QuasarService_Locate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *quasarServiceClient) SubmitReading(ctx context.Context, in *SubmitReadingRequest, opts ...grpc.CallOption) (*SubmitReadingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SubmitReadingResponse)
+	err := c.cc.Invoke(ctx, QuasarService_SubmitReading_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *quasarServiceClient) WatchResult(ctx context.Context, in *WatchResultRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchResultResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &QuasarService_ServiceDesc.Streams[0], QuasarService_WatchResult_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchResultRequest, WatchResultResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type QuasarService_WatchResultClient = grpc.ServerStreamingClient[WatchResultResponse]
+
+// QuasarServiceServer is the server API for QuasarService service.
+// All implementations must embed UnimplementedQuasarServiceServer
+// for forward compatibility.
+type QuasarServiceServer interface {
+	// Locate guarda las lecturas recibidas y devuelve la posición y el mensaje,
+	// igual que POST /topsecret
+	Locate(context.Context, *LocateRequest) (*LocateResponse, error)
+	// SubmitReading guarda la lectura de un satélite, igual que
+	// POST /topsecret_split/{satellite_name}
+	SubmitReading(context.Context, *SubmitReadingRequest) (*SubmitReadingResponse, error)
+	// WatchResult envía el estado actual del flujo dividido y lo recalcula cada
+	// vez que llega o se borra una lectura, igual que GET /stream/topsecret_split
+	WatchResult(*WatchResultRequest, grpc.ServerStreamingServer[WatchResultResponse]) error
+	mustEmbedUnimplementedQuasarServiceServer()
+}
+
+// UnimplementedQuasarServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedQuasarServiceServer struct{}
+
+func (UnimplementedQuasarServiceServer) Locate(context.Context, *LocateRequest) (*LocateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Locate not implemented")
+}
+func (UnimplementedQuasarServiceServer) SubmitReading(context.Context, *SubmitReadingRequest) (*SubmitReadingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitReading not implemented")
+}
+func (UnimplementedQuasarServiceServer) WatchResult(*WatchResultRequest, grpc.ServerStreamingServer[WatchResultResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchResult not implemented")
+}
+func (UnimplementedQuasarServiceServer) mustEmbedUnimplementedQuasarServiceServer() {}
+func (UnimplementedQuasarServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeQuasarServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to QuasarServiceServer will
+// result in compilation errors.
+type UnsafeQuasarServiceServer interface {
+	mustEmbedUnimplementedQuasarServiceServer()
+}
+
+func RegisterQuasarServiceServer(s grpc.ServiceRegistrar, srv QuasarServiceServer) {
+	// If the following call pancis, it indicates UnimplementedQuasarServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&QuasarService_ServiceDesc, srv)
+}
+
+func _QuasarService_Locate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LocateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuasarServiceServer).Locate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QuasarService_Locate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuasarServiceServer).Locate(ctx, req.(*LocateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuasarService_SubmitReading_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitReadingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuasarServiceServer).SubmitReading(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QuasarService_SubmitReading_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuasarServiceServer).SubmitReading(ctx, req.(*SubmitReadingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuasarService_WatchResult_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchResultRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(QuasarServiceServer).WatchResult(m, &grpc.GenericServerStream[WatchResultRequest, WatchResultResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type QuasarService_WatchResultServer = grpc.ServerStreamingServer[WatchResultResponse]
+
+// QuasarService_ServiceDesc is the grpc.ServiceDesc for QuasarService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var QuasarService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fuegodequasar.v1.QuasarService",
+	HandlerType: (*QuasarServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Locate",
+			Handler:    _QuasarService_Locate_Handler,
+		},
+		{
+			MethodName: "SubmitReading",
+			Handler:    _QuasarService_SubmitReading_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchResult",
+			Handler:       _QuasarService_WatchResult_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/quasar/v1/quasar.proto",
+}
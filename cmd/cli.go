@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"fuegodequasar/internal/platform/auth"
+	"fuegodequasar/internal/platform/backup"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultAddr es la dirección del servidor que usan los comandos por defecto
+const defaultAddr = "http://localhost:8080"
+
+// runCommand ejecuta un subcomando de línea de comandos y devuelve el código de salida
+func runCommand(args []string) int {
+	var err error
+	switch args[0] {
+	case "backup":
+		err = runBackup(args[1:])
+	case "restore":
+		err = runRestore(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\nusage: %s [backup|restore] [flags]\n", args[0], os.Args[0])
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	return 0
+}
+
+// runBackup descarga el estado del servidor a un fichero o a la salida estándar
+func runBackup(args []string) error {
+	flags := flag.NewFlagSet("backup", flag.ContinueOnError)
+	addr := flags.String("addr", defaultAddr, "base URL of the running server")
+	output := flags.String("o", "", "output file (defaults to stdout)")
+	apiKey := flags.String("api-key", os.Getenv("QUASAR_API_KEY"), "admin API key (defaults to $QUASAR_API_KEY)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(*addr, "/")+"/api/v1/admin/backup", nil)
+	if err != nil {
+		return err
+	}
+	setAPIKey(req, *apiKey)
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+
+	var archive backup.Archive
+	if err := json.NewDecoder(resp.Body).Decode(&archive); err != nil {
+		return fmt.Errorf("decoding backup: %w", err)
+	}
+	data, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(append(data, '\n'))
+		return err
+	}
+	if err := os.WriteFile(*output, data, 0o600); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "saved %d satellites to %s\n", len(archive.Satellites), *output)
+	return nil
+}
+
+// runRestore valida un fichero de copia de seguridad y lo envía al servidor
+func runRestore(args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	addr := flags.String("addr", defaultAddr, "base URL of the running server")
+	modeFlag := flags.String("mode", string(backup.ModeMerge), "restore mode: merge or replace")
+	apiKey := flags.String("api-key", os.Getenv("QUASAR_API_KEY"), "admin API key (defaults to $QUASAR_API_KEY)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("usage: restore [-addr URL] [-api-key KEY] [-mode merge|replace] FILE")
+	}
+	mode, err := backup.ParseMode(*modeFlag)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	// Validar localmente antes de enviar para dar el error cuanto antes
+	var archive backup.Archive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return fmt.Errorf("%w: %v", backup.ErrInvalidArchive, err)
+	}
+	if err := archive.Validate(); err != nil {
+		return err
+	}
+
+	url := strings.TrimRight(*addr, "/") + "/api/v1/admin/restore?mode=" + string(mode)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setAPIKey(req, *apiKey)
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+
+	var result backup.Result
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decoding restore result: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "restored %d satellites in %s mode\n", len(result.Restored), result.Mode)
+	return nil
+}
+
+// setAPIKey añade la clave a la petición si se indicó alguna
+func setAPIKey(req *http.Request, key string) {
+	if key != "" {
+		req.Header.Set(auth.APIKeyHeader, key)
+	}
+}
+
+// checkResponse convierte una respuesta no exitosa en error con el cuerpo devuelto
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
@@ -2,13 +2,26 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
 	_ "fuegodequasar/docs"
 	"fuegodequasar/handlers"
+	"fuegodequasar/internal/platform/audit"
+	"fuegodequasar/internal/platform/auth"
+	"fuegodequasar/internal/platform/events"
+	"fuegodequasar/internal/platform/history"
+	"fuegodequasar/internal/platform/jobs"
+	"fuegodequasar/internal/platform/ratelimit"
 	"fuegodequasar/internal/platform/repository"
+	"fuegodequasar/internal/platform/tlsconfig"
 	"log"
+	"math"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -16,14 +29,43 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
+// apiV1Prefix es la ruta base de la API v1
+const apiV1Prefix = "/api/v1"
+
+// rootAliasesDeprecatedAt es la fecha desde la que las rutas en la raíz, sin
+// versión, están obsoletas en favor de las de /api/v1
+var rootAliasesDeprecatedAt = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+
+// rootAliasesSunsetAt es la fecha a partir de la cual las rutas en la raíz
+// dejarán de servirse
+var rootAliasesSunsetAt = time.Date(2027, time.April, 18, 0, 0, 0, 0, time.UTC)
+
 // @title Fuego de Quasar API
 // @version 1.0
 // @description API para el desafío de nivel 3 de Fuego de Quasar.
+// @description Los errores se devuelven como application/problem+json (RFC 7807) con un código estable en el campo code; el catálogo de códigos está en GET /api/v1/problems.
+// @description Las rutas están versionadas bajo /api/v1 y /api/v2. Las mismas rutas sin versión en la raíz son alias obsoletos de /api/v1 y lo indican con las cabeceras Deprecation, Sunset y Link.
+// @description Cada ruta exige uno de los roles station (enviar lecturas), analyst (consultar y calcular) o admin (todo), con una clave en X-API-Key o un JWT en Authorization.
 // @host localhost:8080
 // @BasePath /api
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description Clave estática; el servidor solo guarda su SHA-256
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT firmado con HS256 o EdDSA, con el formato "Bearer {token}" y los roles en el claim roles
 func main() {
+	// Subcomandos de administración (backup, restore) contra un servidor en marcha
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
 	// Configurar el modo de Gin basado en el ambiente
 	if os.Getenv("ENV") == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -39,19 +81,145 @@ func main() {
 		log.Printf("defaulting to port %s", port)
 	}
 
-	// Inicializar el repositorio
-	repo := repository.New()
+	// Puerto del servicio gRPC
+	grpcPort := os.Getenv("GRPC_PORT")
+	if grpcPort == "" {
+		grpcPort = "9090"
+	}
+
+	// Inicializar el repositorio como proyección del flujo de eventos,
+	// registrando en auditoría cada cambio efectivo
+	eventRetention, err := eventLogRetention()
+	if err != nil {
+		log.Fatalf("invalid event retention: %v", err)
+	}
+	eventStore := events.NewStore(eventRetention)
+	auditRetention, err := auditLogRetention()
+	if err != nil {
+		log.Fatalf("invalid audit retention: %v", err)
+	}
+	auditLog := audit.NewLog(auditRetention)
+	var repo repository.RepositoryService = audit.NewRepository(
+		events.NewRepository(eventStore, repository.DefaultConstellation()), auditLog)
+
+	// Inyección de fallos para pruebas de resiliencia, nunca en producción
+	if spec := os.Getenv("FAULT_INJECTION"); spec != "" {
+		if os.Getenv("ENV") == "production" {
+			log.Fatal("FAULT_INJECTION must not be set in production")
+		}
+		config, err := loadFaultConfig(spec)
+		if err != nil {
+			log.Fatalf("failed to load fault injection config: %v", err)
+		}
+		repo = repository.NewFaultInjector(repo, config)
+		log.Printf("fault injection enabled with %d rules", len(config.Rules))
+	}
 
 	// Configurar el router con middleware de recuperación y logging
 	router := gin.New()
 	router.Use(gin.Recovery())
 	router.Use(gin.LoggerWithWriter(os.Stdout))
 
-	// Añadir middleware de CORS
-	router.Use(corsMiddleware())
+	// Añadir middleware de CORS con la política configurada
+	policy, err := corsPolicy()
+	if err != nil {
+		log.Fatalf("invalid CORS config: %v", err)
+	}
+	cors, err := handlers.CORS(policy)
+	if err != nil {
+		log.Fatalf("invalid CORS config: %v", err)
+	}
+	origins, err := handlers.NewOriginMatcher(policy.AllowedOrigins)
+	if err != nil {
+		log.Fatalf("invalid CORS config: %v", err)
+	}
+	router.Use(cors)
+
+	// Autenticación con claves estáticas y JWT; sin credenciales configuradas
+	// la API queda abierta, lo que no se admite en producción
+	authenticator, err := loadAuthenticator()
+	if err != nil {
+		log.Fatalf("invalid authentication config: %v", err)
+	}
+	if authenticator == nil {
+		if os.Getenv("ENV") == "production" {
+			log.Fatal("API_KEYS_FILE or a JWT key must be set in production")
+		}
+		log.Print("authentication disabled: every request has every role")
+	}
+
+	// Identificar cada petición y a su autor para la auditoría
+	router.Use(handlers.RequestID(), handlers.AuditActor(), handlers.Authenticate(authenticator))
+
+	// Límites de peticiones por cliente, tras autenticar para poder usar la
+	// identidad como clave
+	limiter, err := loadRateLimiter()
+	if err != nil {
+		log.Fatalf("invalid rate limits: %v", err)
+	}
+	router.Use(handlers.RateLimit(limiter))
+
+	// Permiso de depuración para pedir el diagnóstico de los cálculos
+	router.Use(handlers.DebugAccess(os.Getenv("DEBUG_TOKEN")))
+
+	// Historial de lecturas con la retención configurada
+	retention, err := readingRetention()
+	if err != nil {
+		log.Fatalf("invalid reading retention: %v", err)
+	}
+	readings := history.NewStore(retention)
+
+	// Límites de validación de las lecturas
+	limits, err := validationLimits()
+	if err != nil {
+		log.Fatalf("invalid validation limits: %v", err)
+	}
+	handlers.SetValidationLimits(limits)
+
+	// Trabajos asíncronos de localización
+	jobConfig, err := jobsConfig()
+	if err != nil {
+		log.Fatalf("invalid jobs config: %v", err)
+	}
+	jobManager := jobs.NewManager(jobConfig)
+
+	// Credenciales de los satélites para exigir lecturas firmadas
+	signatures, err := loadSatelliteCredentials()
+	if err != nil {
+		log.Fatalf("invalid satellite credentials: %v", err)
+	}
+	if signatures != nil {
+		log.Print("satellite signatures required on split readings")
+	}
+
+	// Canal WebSocket de las estaciones, solo si hay tokens configurados
+	stationTokens, err := parseStationTokens(os.Getenv("STATION_TOKENS"))
+	if err != nil {
+		log.Fatalf("invalid station tokens: %v", err)
+	}
+	switch {
+	case len(stationTokens) > 0 && signatures != nil:
+		log.Print("station ingestion disabled: satellite signatures are required and frames are not signed")
+	case len(stationTokens) > 0:
+		log.Printf("station ingestion enabled for %d stations", len(stationTokens))
+	}
 
-	// Configurar las rutas
-	handlers.SetupRoutes(router, repo)
+	// Los streams (SSE, WebSocket y WatchResult) terminan al cancelar
+	// streamsCtx al apagar; las demás peticiones no dependen de él
+	streamsCtx, cancelStreams := context.WithCancel(context.Background())
+
+	// Configurar las rutas bajo /api/v1 y, como alias obsoletos, en la raíz
+	v1 := router.Group(apiV1Prefix)
+	legacy := router.Group("/", handlers.Deprecated(apiV1Prefix, rootAliasesDeprecatedAt, rootAliasesSunsetAt))
+	for _, group := range []gin.IRouter{v1, legacy} {
+		handlers.SetupRoutes(streamsCtx, group, repo, readings, signatures)
+		handlers.SetupAdminRoutes(group, repo, auditLog, eventStore, limiter)
+		handlers.SetupJobRoutes(group, repo, jobManager)
+		handlers.SetupStationRoutes(streamsCtx, group, repo, readings, stationTokens, signatures, origins)
+	}
+
+	// La API v2 solo incluye los recursos que cambian respecto de v1
+	handlers.SetupV2Routes(router.Group("/api/v2"), repo, readings, signatures)
 
 	// Añadir la ruta de Swagger
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -61,7 +229,8 @@ func main() {
 		c.Status(http.StatusOK)
 	})
 
-	// Crear el servidor HTTP con timeouts
+	// Crear el servidor HTTP con timeouts. Los streams amplían su propio plazo
+	// de escritura.
 	srv := &http.Server{
 		Addr:         ":" + port,
 		Handler:      router,
@@ -70,13 +239,47 @@ func main() {
 		IdleTimeout:  120 * time.Second,
 	}
 
-	// Iniciar el servidor en una goroutine
+	// TLS propio, con el certificado recargado al cambiar sus ficheros y mTLS
+	// opcional; sin certificado se sirve HTTP plano, como detrás de Cloud Run
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+	tlsConfig, err := loadTLSConfig(watchCtx)
+	if err != nil {
+		log.Fatalf("invalid TLS config: %v", err)
+	}
+	srv.TLSConfig = tlsConfig
+
+	// Servidor gRPC con el mismo repositorio en su propio puerto y el mismo TLS
+	var grpcOptions []grpc.ServerOption
+	if tlsConfig != nil {
+		grpcOptions = append(grpcOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	grpcServer := handlers.NewGRPCServer(streamsCtx, repo, readings, authenticator, signatures, grpcOptions...)
+	grpcListener, err := net.Listen("tcp", ":"+grpcPort)
+	if err != nil {
+		log.Fatalf("failed to listen for gRPC: %v", err)
+	}
+
+	// Iniciar los servidores en goroutines
 	go func() {
-		log.Printf("starting server on port %s", port)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if tlsConfig != nil {
+			log.Printf("starting TLS server on port %s", port)
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			log.Printf("starting server on port %s", port)
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			log.Fatalf("failed to start server: %v", err)
 		}
 	}()
+	go func() {
+		log.Printf("starting gRPC server on port %s", grpcPort)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Fatalf("failed to start gRPC server: %v", err)
+		}
+	}()
 
 	// Configurar canal para señales de apagado
 	quit := make(chan os.Signal, 1)
@@ -88,27 +291,384 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// Terminar solo los streams abiertos, que de otro modo retrasarían el
+	// apagado; las peticiones en curso y las que lleguen mientras terminan los
+	// trabajos conservan su contexto hasta que srv.Shutdown las espera
+	cancelStreams()
+
+	// Apagar el servidor gRPC en paralelo con el HTTP
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+
+	// Terminar los trabajos en curso mientras el servidor sigue respondiendo a
+	// las consultas de su estado; los que no acaben a tiempo se cancelan
+	if err := jobManager.Shutdown(ctx); err != nil {
+		log.Printf("jobs canceled during shutdown: %v", err)
+	}
+
 	// Intentar shutdown graceful
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatal("server forced to shutdown:", err)
 	}
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		grpcServer.Stop()
+		log.Fatal("gRPC server forced to shutdown:", ctx.Err())
+	}
 
 	log.Println("server exited gracefully")
 }
 
-// corsMiddleware configura las cabeceras CORS
-func corsMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
+// readingRetention lee la retención del historial de lecturas de READING_RETENTION
+// (duración, por defecto 24h) y READING_HISTORY_MAX (lecturas por satélite, por
+// defecto 10000). El valor 0 desactiva el límite correspondiente.
+func readingRetention() (history.Retention, error) {
+	retention := history.Retention{MaxAge: 24 * time.Hour, MaxPerSatellite: 10000}
+	if value := os.Getenv("READING_RETENTION"); value != "" {
+		maxAge, err := time.ParseDuration(value)
+		if err != nil || maxAge < 0 {
+			return history.Retention{}, fmt.Errorf("READING_RETENTION=%q is not a valid duration", value)
+		}
+		retention.MaxAge = maxAge
+	}
+	if value := os.Getenv("READING_HISTORY_MAX"); value != "" {
+		maxPerSatellite, err := strconv.Atoi(value)
+		if err != nil || maxPerSatellite < 0 {
+			return history.Retention{}, fmt.Errorf("READING_HISTORY_MAX=%q is not a valid count", value)
+		}
+		retention.MaxPerSatellite = maxPerSatellite
+	}
+	return retention, nil
+}
+
+// auditLogRetention lee la retención del registro de auditoría de
+// AUDIT_RETENTION (duración, por defecto 720h) y AUDIT_LOG_MAX (entradas, por
+// defecto 100000). El valor 0 desactiva el límite correspondiente.
+func auditLogRetention() (audit.Retention, error) {
+	retention := audit.Retention{MaxAge: 30 * 24 * time.Hour, MaxEntries: 100000}
+	if value := os.Getenv("AUDIT_RETENTION"); value != "" {
+		maxAge, err := time.ParseDuration(value)
+		if err != nil || maxAge < 0 {
+			return audit.Retention{}, fmt.Errorf("AUDIT_RETENTION=%q is not a valid duration", value)
+		}
+		retention.MaxAge = maxAge
+	}
+	if value := os.Getenv("AUDIT_LOG_MAX"); value != "" {
+		maxEntries, err := strconv.Atoi(value)
+		if err != nil || maxEntries < 0 {
+			return audit.Retention{}, fmt.Errorf("AUDIT_LOG_MAX=%q is not a valid count", value)
+		}
+		retention.MaxEntries = maxEntries
+	}
+	return retention, nil
+}
+
+// eventLogRetention lee la retención del flujo de eventos de EVENT_RETENTION
+// (duración, por defecto 720h) y EVENT_LOG_MAX (eventos, por defecto 100000).
+// El valor 0 desactiva el límite correspondiente. Los eventos que salen de la
+// retención se compactan en una instantánea y marcan el horizonte de
+// reproducción de /admin/replay.
+func eventLogRetention() (events.Retention, error) {
+	retention := events.Retention{MaxAge: 30 * 24 * time.Hour, MaxEvents: 100000}
+	if value := os.Getenv("EVENT_RETENTION"); value != "" {
+		maxAge, err := time.ParseDuration(value)
+		if err != nil || maxAge < 0 {
+			return events.Retention{}, fmt.Errorf("EVENT_RETENTION=%q is not a valid duration", value)
+		}
+		retention.MaxAge = maxAge
+	}
+	if value := os.Getenv("EVENT_LOG_MAX"); value != "" {
+		maxEvents, err := strconv.Atoi(value)
+		if err != nil || maxEvents < 0 {
+			return events.Retention{}, fmt.Errorf("EVENT_LOG_MAX=%q is not a valid count", value)
+		}
+		retention.MaxEvents = maxEvents
+	}
+	return retention, nil
+}
+
+// loadAuthenticator crea el autenticador a partir de API_KEYS_FILE (fichero
+// JSON con las claves estáticas hasheadas), JWT_HS256_SECRET (secreto de los
+// tokens HS256), JWT_ED25519_PUBLIC_KEY (fichero PEM con la clave pública de
+// los tokens EdDSA), JWT_ISSUER y JWT_AUDIENCE. Devuelve nil si no hay ninguna
+// credencial configurada.
+func loadAuthenticator() (auth.Authenticator, error) {
+	var chain auth.Chain
+	if path := os.Getenv("API_KEYS_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		keys, err := auth.ParseAPIKeys(data)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, keys)
+	}
+
+	config := auth.JWTConfig{
+		HS256Secret: []byte(os.Getenv("JWT_HS256_SECRET")),
+		Issuer:      os.Getenv("JWT_ISSUER"),
+		Audience:    os.Getenv("JWT_AUDIENCE"),
+		Leeway:      30 * time.Second,
+	}
+	if path := os.Getenv("JWT_ED25519_PUBLIC_KEY"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if config.Ed25519Key, err = auth.ParseEd25519PublicKey(data); err != nil {
+			return nil, err
+		}
+	}
+	if len(config.HS256Secret) > 0 || len(config.Ed25519Key) > 0 {
+		verifier, err := auth.NewJWTVerifier(config)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, verifier)
+	}
+
+	if len(chain) == 0 {
+		return nil, nil
+	}
+	return chain, nil
+}
+
+// loadSatelliteCredentials lee de SATELLITE_CREDENTIALS_FILE (fichero JSON con
+// el secreto o la clave pública de cada satélite) las credenciales con las que
+// se comprueban las lecturas firmadas. SIGNATURE_WINDOW es la antigüedad
+// máxima de una firma, por defecto 5m. Devuelve nil si no hay fichero.
+func loadSatelliteCredentials() (*auth.SignatureVerifier, error) {
+	path := os.Getenv("SATELLITE_CREDENTIALS_FILE")
+	if path == "" {
+		return nil, nil
+	}
+	window := 5 * time.Minute
+	if value := os.Getenv("SIGNATURE_WINDOW"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("SIGNATURE_WINDOW=%q is not a positive duration", value)
+		}
+		window = parsed
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return auth.ParseSatelliteCredentials(data, window)
+}
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
+// loadTLSConfig lee la configuración TLS de TLS_CERT_FILE y TLS_KEY_FILE
+// (certificado y clave en PEM), TLS_CLIENT_CA_FILE (CA de los certificados de
+// cliente, activa mTLS), TLS_CLIENT_AUTH (optional o require, por defecto
+// optional) y TLS_RELOAD_INTERVAL (cada cuánto se comprueba si el certificado
+// ha cambiado, por defecto 30s). Devuelve nil si no hay certificado. El
+// certificado se vigila hasta que se cancela ctx.
+func loadTLSConfig(ctx context.Context) (*tls.Config, error) {
+	config := tlsconfig.Config{
+		CertFile:     os.Getenv("TLS_CERT_FILE"),
+		KeyFile:      os.Getenv("TLS_KEY_FILE"),
+		ClientCAFile: os.Getenv("TLS_CLIENT_CA_FILE"),
+	}
+	if config.CertFile == "" && config.KeyFile == "" {
+		if config.ClientCAFile != "" {
+			return nil, fmt.Errorf("TLS_CLIENT_CA_FILE needs TLS_CERT_FILE and TLS_KEY_FILE")
 		}
+		return nil, nil
+	}
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	switch value := os.Getenv("TLS_CLIENT_AUTH"); value {
+	case "", "optional":
+	case "require":
+		config.RequireClientCert = true
+	default:
+		return nil, fmt.Errorf("TLS_CLIENT_AUTH=%q must be optional or require", value)
+	}
+	interval := 30 * time.Second
+	if value := os.Getenv("TLS_RELOAD_INTERVAL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("TLS_RELOAD_INTERVAL=%q is not a positive duration", value)
+		}
+		interval = parsed
+	}
+
+	reloader, err := tlsconfig.NewReloader(config.CertFile, config.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	serverConfig, err := tlsconfig.ServerConfig(config, reloader)
+	if err != nil {
+		return nil, err
+	}
+	go reloader.Watch(ctx, interval)
+	return serverConfig, nil
+}
+
+// loadRateLimiter lee de RATE_LIMITS_FILE (fichero JSON con la lista de
+// reglas por ruta) los límites de peticiones. Devuelve nil si no hay fichero.
+func loadRateLimiter() (*ratelimit.Limiter, error) {
+	path := os.Getenv("RATE_LIMITS_FILE")
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := ratelimit.ParseRules(data)
+	if err != nil {
+		return nil, err
+	}
+	return ratelimit.NewLimiter(rules), nil
+}
 
-		c.Next()
+// corsPolicy lee la política CORS de CORS_ALLOWED_ORIGINS (orígenes exactos,
+// patrones como https://*.example.com o *, separados por comas),
+// CORS_ALLOWED_METHODS, CORS_ALLOWED_HEADERS y CORS_MAX_AGE (por defecto 10m).
+// Lo que no se configura toma el valor de handlers.DefaultCORSPolicy; con
+// CORS_ALLOWED_ORIGINS vacía no se admite ningún origen.
+func corsPolicy() (handlers.CORSPolicy, error) {
+	policy := handlers.DefaultCORSPolicy
+	if value, ok := os.LookupEnv("CORS_ALLOWED_ORIGINS"); ok {
+		policy.AllowedOrigins = splitList(value)
+	}
+	if value := os.Getenv("CORS_ALLOWED_METHODS"); value != "" {
+		policy.AllowedMethods = splitList(strings.ToUpper(value))
+	}
+	if value := os.Getenv("CORS_ALLOWED_HEADERS"); value != "" {
+		policy.AllowedHeaders = splitList(value)
+	}
+	if value := os.Getenv("CORS_MAX_AGE"); value != "" {
+		maxAge, err := time.ParseDuration(value)
+		if err != nil || maxAge < 0 {
+			return handlers.CORSPolicy{}, fmt.Errorf("CORS_MAX_AGE=%q is not a valid duration", value)
+		}
+		policy.MaxAge = maxAge
+	}
+	return policy, nil
+}
+
+// splitList separa una lista de valores separados por comas, sin vacíos
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// validationLimits lee los límites de las lecturas de MAX_MESSAGE_WORDS
+// (palabras de un mensaje, por defecto 100), MAX_SATELLITES (satélites de una
+// transmisión, por defecto 16) y MAX_DISTANCE (por defecto 1000000)
+func validationLimits() (handlers.ValidationLimits, error) {
+	limits := handlers.DefaultValidationLimits
+	if value := os.Getenv("MAX_MESSAGE_WORDS"); value != "" {
+		words, err := strconv.Atoi(value)
+		if err != nil || words < 1 {
+			return handlers.ValidationLimits{}, fmt.Errorf("MAX_MESSAGE_WORDS=%q is not a positive count", value)
+		}
+		limits.MaxWords = words
+	}
+	if value := os.Getenv("MAX_SATELLITES"); value != "" {
+		satellites, err := strconv.Atoi(value)
+		if err != nil || satellites < 1 {
+			return handlers.ValidationLimits{}, fmt.Errorf("MAX_SATELLITES=%q is not a positive count", value)
+		}
+		limits.MaxSatellites = satellites
+	}
+	if value := os.Getenv("MAX_DISTANCE"); value != "" {
+		distance, err := strconv.ParseFloat(value, 64)
+		if err != nil || !(distance > 0) || math.IsInf(distance, 0) {
+			return handlers.ValidationLimits{}, fmt.Errorf("MAX_DISTANCE=%q is not a positive finite number", value)
+		}
+		limits.MaxDistance = distance
+	}
+	return limits, nil
+}
+
+// jobsConfig lee la configuración de los trabajos asíncronos de JOB_WORKERS
+// (trabajos simultáneos, por defecto 4), JOB_QUEUE_SIZE (trabajos en espera,
+// por defecto 100) y JOB_RETENTION (cuánto se conserva un trabajo terminado,
+// por defecto 1h)
+func jobsConfig() (jobs.Config, error) {
+	config := jobs.Config{Workers: 4, QueueSize: 100, Retention: time.Hour}
+	if value := os.Getenv("JOB_WORKERS"); value != "" {
+		workers, err := strconv.Atoi(value)
+		if err != nil || workers < 1 {
+			return jobs.Config{}, fmt.Errorf("JOB_WORKERS=%q is not a positive count", value)
+		}
+		config.Workers = workers
+	}
+	if value := os.Getenv("JOB_QUEUE_SIZE"); value != "" {
+		queueSize, err := strconv.Atoi(value)
+		if err != nil || queueSize < 1 {
+			return jobs.Config{}, fmt.Errorf("JOB_QUEUE_SIZE=%q is not a positive count", value)
+		}
+		config.QueueSize = queueSize
+	}
+	if value := os.Getenv("JOB_RETENTION"); value != "" {
+		retention, err := time.ParseDuration(value)
+		if err != nil || retention < 0 {
+			return jobs.Config{}, fmt.Errorf("JOB_RETENTION=%q is not a valid duration", value)
+		}
+		config.Retention = retention
+	}
+	return config, nil
+}
+
+// parseStationTokens lee los tokens de las estaciones con el formato
+// estacion[:satélite|satélite...]=token separados por comas. Sin lista de
+// satélites la estación solo envía lecturas del satélite de su mismo nombre;
+// "*" confía en ella para todos.
+func parseStationTokens(spec string) (handlers.StationTokens, error) {
+	tokens := make(handlers.StationTokens)
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		station, token, ok := strings.Cut(pair, "=")
+		station, token = strings.TrimSpace(station), strings.TrimSpace(token)
+		name, list, hasList := strings.Cut(station, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || token == "" {
+			return nil, fmt.Errorf("STATION_TOKENS entry %q must be station[:satellite|...]=token", station)
+		}
+		satellites := []string{name}
+		if hasList {
+			satellites = nil
+			for _, satellite := range strings.Split(list, "|") {
+				if satellite = strings.TrimSpace(satellite); satellite == "" {
+					return nil, fmt.Errorf("STATION_TOKENS entry %q has an empty satellite", station)
+				}
+				satellites = append(satellites, satellite)
+			}
+		}
+		if _, exists := tokens[token]; exists {
+			return nil, fmt.Errorf("STATION_TOKENS repeats the token of station %q", name)
+		}
+		tokens[token] = handlers.Station{Name: name, Satellites: satellites}
+	}
+	return tokens, nil
+}
+
+// loadFaultConfig lee la configuración de fallos desde JSON en línea o desde un fichero
+func loadFaultConfig(spec string) (repository.FaultConfig, error) {
+	data := []byte(spec)
+	if !strings.HasPrefix(strings.TrimSpace(spec), "{") {
+		var err error
+		if data, err = os.ReadFile(spec); err != nil {
+			return repository.FaultConfig{}, err
+		}
 	}
+	return repository.ParseFaultConfig(data)
 }
@@ -15,70 +15,297 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/topsecret": {
-            "post": {
-                "description": "Recibe información de los satélites y retorna posición y mensaje",
-                "consumes": [
-                    "application/json"
+        "/v1/admin/audit": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Devuelve los cambios sobre los satélites filtrados por intervalo de tiempo y satélite",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "topsecret"
+                    "admin"
                 ],
-                "summary": "Decodifica mensaje y posición",
+                "summary": "Consulta el registro de auditoría",
                 "parameters": [
                     {
-                        "description": "Datos de los satélites",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.TopSecretRequest"
-                        }
+                        "type": "string",
+                        "description": "Inicio del intervalo (RFC 3339, incluido)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fin del intervalo (RFC 3339, excluido)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Nombre del satélite",
+                        "name": "satellite",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Número máximo de entradas, las más recientes",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Ejemplo de respuesta\" example({\"position\":{\"x\":426.4001,\"y\":-252.80016},\"message\":\"este es un mensaje secreto\"})",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.TopSecretResponse"
+                            "$ref": "#/definitions/handlers.AuditLogResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/backup": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Descarga un archivo JSON versionado con la constelación y las lecturas actuales",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Exporta el estado del repositorio",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/backup.Archive"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     }
                 }
             }
         },
-        "/topsecret_split": {
+        "/v1/admin/events": {
             "get": {
-                "description": "Recupera la posición y mensaje usando los datos guardados de los satélites",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve los eventos con secuencia posterior a since. Los eventos que salen de la retención se compactan en una instantánea y dejan de listarse: horizon es la secuencia del último evento compactado.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Consulta el flujo de eventos",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Secuencia a partir de la cual listar (excluida)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Número máximo de eventos (máximo 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.EventsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/quotas": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve cuántas peticiones se han aceptado hoy (UTC) a cada cliente en cada regla de límite de peticiones y cuánto les queda de su cuota diaria",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Consulta las cuotas diarias",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.QuotasResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/replay": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Proyecta el flujo de eventos hasta un instante o número de secuencia y calcula la posición y el mensaje que habría devuelto GET /topsecret_split en ese momento. Solo se puede reproducir a partir del horizonte del flujo (ver GET /admin/events): los puntos anteriores responden 400.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reproduce el estado en un punto del pasado",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Instante a reproducir (RFC 3339, incluido)",
+                        "name": "at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Último número de secuencia a aplicar",
+                        "name": "seq",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReplayResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Valida un archivo generado por /admin/backup y lo aplica. En modo merge se guardan los satélites del archivo sin tocar los demás; en modo replace el repositorio queda exactamente como el archivo.",
                 "consumes": [
                     "application/json"
                 ],
@@ -86,40 +313,76 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "topsecret_split"
+                    "admin"
+                ],
+                "summary": "Importa el estado del repositorio",
+                "parameters": [
+                    {
+                        "enum": [
+                            "merge",
+                            "replace"
+                        ],
+                        "type": "string",
+                        "default": "merge",
+                        "description": "Modo de importación",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Archivo de copia de seguridad",
+                        "name": "archive",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/backup.Archive"
+                        }
+                    }
                 ],
-                "summary": "Decodifica mensaje y posición usando información parcial",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.TopSecretResponse"
+                            "$ref": "#/definitions/backup.Result"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     }
                 }
             }
         },
-        "/topsecret_split/{satellite_name}": {
-            "post": {
-                "description": "Permite guardar la distancia y mensaje de un satélite individualmente",
+        "/v1/admin/satellites/{satellite_name}/status": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Los satélites en mantenimiento o retirados se excluyen de los cálculos y los degradados pesan menos",
                 "consumes": [
                     "application/json"
                 ],
@@ -127,9 +390,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "topsecret_split"
+                    "admin"
                 ],
-                "summary": "Guarda información parcial de un satélite",
+                "summary": "Cambia el estado operativo de un satélite",
                 "parameters": [
                     {
                         "type": "string",
@@ -139,81 +402,1935 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Distancia y mensaje del satélite",
+                        "description": "Nuevo estado",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.TopSecretSplitRequest"
+                            "$ref": "#/definitions/handlers.SatelliteStatusRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Actualización exitosa"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/repository.Status"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handlers.Position": {
-            "description": "Coordenadas de la fuente",
-            "type": "object",
-            "properties": {
-                "x": {
-                    "type": "number",
-                    "example": 426.4001
-                },
-                "y": {
-                    "type": "number",
-                    "example": -252.80016
-                }
-            }
         },
-        "handlers.SatelliteInfo": {
-            "description": "Información individual de un satélite",
-            "type": "object",
-            "properties": {
-                "distance": {
-                    "type": "number",
-                    "example": 927.75
-                },
-                "message": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
+        "/v1/jobs": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
                     },
-                    "example": [
-                        "[\"este\"",
-                        " \"\"",
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Encola el cálculo de una transmisión (satellites) o de un lote (items) para resolverlo en segundo plano, con las mismas reglas que /topsecret/batch: no se guardan lecturas. El resultado se consulta en GET /jobs/{job_id}: un TopSecretResponse para una transmisión o un BatchResponse para un lote.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Encola un trabajo de localización",
+                "parameters": [
+                    {
+                        "description": "Transmisión o lote a localizar",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.JobRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/jobs.Job"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL del trabajo"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/jobs/{job_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve el estado del trabajo y, cuando termina, su resultado o su error. Un trabajo fallido incluye en problem el mismo Problem que respondería el endpoint síncrono. Los trabajos terminados se conservan durante el tiempo de retención configurado.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Consulta un trabajo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID del trabajo",
+                        "name": "job_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/jobs.Job"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancela un trabajo en cola o en curso. Un trabajo en curso pasa a cancelado en cuanto el cálculo atiende la cancelación.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Cancela un trabajo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID del trabajo",
+                        "name": "job_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/jobs.Job"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/problems": {
+            "get": {
+                "description": "Lista los códigos estables que pueden aparecer en el campo code de un error application/problem+json",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "problems"
+                ],
+                "summary": "Catálogo de errores",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handlers.ProblemType"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/problems/{code}": {
+            "get": {
+                "description": "Documentación del tipo de error al que apunta el campo type de un problema",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "problems"
+                ],
+                "summary": "Describe un código de error",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código del error",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ProblemType"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/satellites/{satellite_name}/readings": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve las lecturas recibidas en un intervalo, paginadas, y la media, desviación típica, mínimo y máximo de la distancia por ventana de tiempo",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "satellites"
+                ],
+                "summary": "Consulta el historial de lecturas de un satélite",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Nombre del satélite",
+                        "name": "satellite_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Inicio del intervalo (RFC 3339, incluido)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fin del intervalo (RFC 3339, excluido)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Lecturas a saltar",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Lecturas por página (máximo 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Duración de cada ventana de estadísticas, por ejemplo 1h; vacío usa todo el intervalo",
+                        "name": "window",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SatelliteReadingsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/stream/topsecret_split": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mantiene abierta una conexión Server-Sent Events que envía el estado actual al conectar y lo recalcula cada vez que llega o se borra una lectura.\nEventos: \"location\" con la posición y el mensaje (TopSecretResponse), \"waiting\" con los satélites de los que falta lectura (StreamWaitingResponse) y \"heartbeat\" cada 15 segundos (StreamHeartbeat).\nEl id de cada evento es la secuencia del último cambio incluido; al reconectar con Last-Event-ID se reenvía el estado actual.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "topsecret_split"
+                ],
+                "summary": "Stream de posición y mensaje del flujo dividido",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Último id recibido, al reconectar",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TopSecretResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/topsecret": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Recibe información de los satélites y retorna posición y mensaje",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topsecret"
+                ],
+                "summary": "Decodifica mensaje y posición",
+                "parameters": [
+                    {
+                        "description": "Datos de los satélites",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TopSecretRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Incluye el diagnóstico del cálculo; requiere permiso de depuración",
+                        "name": "debug",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token que concede el permiso de depuración",
+                        "name": "X-Debug-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ejemplo de respuesta\" example({\"position\":{\"x\":426.4001,\"y\":-252.80016},\"message\":\"este es un mensaje secreto\"})",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TopSecretResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/topsecret/batch": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Calcula la posición y el mensaje de cada transmisión del lote de forma concurrente. Cada transmisión se resuelve de forma independiente con las posiciones y estados de los satélites registrados, sin guardar sus lecturas. Un fallo en una transmisión no hace fallar el lote.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topsecret"
+                ],
+                "summary": "Localiza un lote de transmisiones",
+                "parameters": [
+                    {
+                        "description": "Transmisiones a localizar (máximo 10000)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/topsecret_split": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Recupera la posición y mensaje usando los datos guardados de los satélites",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topsecret_split"
+                ],
+                "summary": "Decodifica mensaje y posición usando información parcial",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Incluye el diagnóstico del cálculo; requiere permiso de depuración",
+                        "name": "debug",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token que concede el permiso de depuración",
+                        "name": "X-Debug-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TopSecretResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Elimina la distancia y mensaje guardados de todos los satélites, conservando sus posiciones",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topsecret_split"
+                ],
+                "summary": "Borra todas las lecturas parciales",
+                "responses": {
+                    "204": {
+                        "description": "Lecturas borradas"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/topsecret_split/{satellite_name}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve la distancia y mensaje guardados de un satélite junto con sus metadatos",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topsecret_split"
+                ],
+                "summary": "Consulta la lectura parcial de un satélite",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Nombre del satélite",
+                        "name": "satellite_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag de la versión que ya tiene el cliente",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SatelliteReadingResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Versión actual del satélite"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "La versión del cliente sigue vigente"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Permite guardar la distancia y mensaje de un satélite individualmente\nSi el servidor exige firmas, la lectura debe llevar en X-Signature la firma en base64 (HMAC-SHA256 o Ed25519) de \"satellite_name\\ntimestamp\\nsha256_hex(cuerpo)\" y en X-Signature-Timestamp ese instante en segundos Unix. Cada firma solo se acepta una vez.\nCon mTLS, un certificado de cliente cuyo CN o SAN (DNS o urn:satellite:\u003cnombre\u003e) sea el satélite sirve como credencial y sustituye a la firma; un certificado de otro satélite se rechaza con 403.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topsecret_split"
+                ],
+                "summary": "Guarda información parcial de un satélite",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Nombre del satélite",
+                        "name": "satellite_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Distancia y mensaje del satélite",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TopSecretSplitRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag de la versión sobre la que se escribe",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Firma del satélite, si el servidor exige firmas",
+                        "name": "X-Signature",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Instante de la firma en segundos Unix",
+                        "name": "X-Signature-Timestamp",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Actualización exitosa",
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Versión guardada del satélite"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Elimina la distancia y mensaje guardados de un satélite, conservando su posición\nSi el servidor exige firmas, solo puede borrarla una conexión con el certificado de cliente del satélite o un administrador.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "topsecret_split"
+                ],
+                "summary": "Borra la lectura parcial de un satélite",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Nombre del satélite",
+                        "name": "satellite_name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Lectura borrada"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/ws/topsecret_split": {
+            "get": {
+                "description": "Abre una conexión WebSocket de larga duración. La estación se autentica una vez con {\"type\":\"auth\",\"token\":\"...\"} y después envía frames {\"type\":\"reading\",\"id\":\"42\",\"satellite\":\"kenobi\",\"distance\":100,\"message\":[\"este\",\"\",\"un\",\"\",\"\"]}.\nCada token solo puede enviar lecturas de los satélites de su estación; las demás se rechazan con un \"nack\" forbidden.\nCada lectura se valida y se guarda igual que en POST /topsecret_split/{satellite_name}; el servidor responde con un frame \"ack\" o \"nack\" y, si se aceptó, con un frame \"result\" con la posición y el mensaje o el motivo por el que aún no se pueden calcular.\nEl canal no existe si el servidor exige lecturas firmadas por su satélite. Las conexiones con cabecera Origin solo se aceptan de los orígenes que admite la política CORS.",
+                "tags": [
+                    "topsecret_split"
+                ],
+                "summary": "Canal WebSocket de ingesta de lecturas",
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.StationReply"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/topsecret": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Igual que POST /v1/topsecret, pero con la posición en doble precisión y los satélites usados con su peso y residuo. A diferencia de v1, si la trilateración falla responde location_unavailable en lugar de la posición (0, 0).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Decodifica mensaje y posición (v2)",
+                "parameters": [
+                    {
+                        "description": "Datos de los satélites",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TopSecretRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Incluye el diagnóstico del cálculo; requiere permiso de depuración",
+                        "name": "debug",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token que concede el permiso de depuración",
+                        "name": "X-Debug-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.LocationV2"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/topsecret_split": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Igual que GET /v1/topsecret_split, con la representación de v2",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Decodifica mensaje y posición usando información parcial (v2)",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Incluye el diagnóstico del cálculo; requiere permiso de depuración",
+                        "name": "debug",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token que concede el permiso de depuración",
+                        "name": "X-Debug-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.LocationV2"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "audit.Actor": {
+            "type": "object",
+            "properties": {
+                "client_ip": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "audit.Entry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor": {
+                    "$ref": "#/definitions/audit.Actor"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "new": {
+                    "$ref": "#/definitions/repository.Satellite"
+                },
+                "old": {
+                    "$ref": "#/definitions/repository.Satellite"
+                },
+                "satellite": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "backup.Archive": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "satellites": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/repository.Satellite"
+                    }
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "backup.Mode": {
+            "type": "string",
+            "enum": [
+                "merge",
+                "replace"
+            ],
+            "x-enum-varnames": [
+                "ModeMerge",
+                "ModeReplace"
+            ]
+        },
+        "backup.Result": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "$ref": "#/definitions/backup.Mode"
+                },
+                "restored": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "events.Event": {
+            "type": "object",
+            "properties": {
+                "distance": {
+                    "type": "number"
+                },
+                "kind": {
+                    "$ref": "#/definitions/events.Kind"
+                },
+                "message": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "position": {
+                    "$ref": "#/definitions/repository.Point"
+                },
+                "satellite": {
+                    "type": "string"
+                },
+                "seq": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/repository.Status"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version es la versión que deja el evento en el satélite. Los eventos que\ngenera una misma escritura comparten versión.",
+                    "type": "integer"
+                }
+            }
+        },
+        "events.Kind": {
+            "type": "string",
+            "enum": [
+                "satellite_registered",
+                "satellite_updated",
+                "satellite_removed",
+                "reading_received",
+                "reading_cleared",
+                "transmission_closed"
+            ],
+            "x-enum-varnames": [
+                "KindSatelliteRegistered",
+                "KindSatelliteUpdated",
+                "KindSatelliteRemoved",
+                "KindReadingReceived",
+                "KindReadingCleared",
+                "KindTransmissionClosed"
+            ]
+        },
+        "handlers.AuditLogResponse": {
+            "description": "Cambios registrados sobre los satélites, en orden cronológico",
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/audit.Entry"
+                    }
+                }
+            }
+        },
+        "handlers.BatchItem": {
+            "description": "Transmisión a localizar dentro de un lote",
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "session-1/0001"
+                },
+                "satellites": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.SatelliteInfo"
+                    }
+                }
+            }
+        },
+        "handlers.BatchItemResult": {
+            "description": "Resultado de una transmisión del lote",
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "session-1/0001"
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "problem": {
+                    "description": "Problem es el error de la transmisión, con el mismo formato que en la API",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    ]
+                },
+                "result": {
+                    "$ref": "#/definitions/handlers.TopSecretResponse"
+                }
+            }
+        },
+        "handlers.BatchRequest": {
+            "description": "Lote de transmisiones a localizar",
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.BatchItem"
+                    }
+                }
+            }
+        },
+        "handlers.BatchResponse": {
+            "description": "Resultados de un lote",
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.BatchItemResult"
+                    }
+                },
+                "summary": {
+                    "$ref": "#/definitions/handlers.BatchSummary"
+                }
+            }
+        },
+        "handlers.BatchSummary": {
+            "description": "Resumen de un lote",
+            "type": "object",
+            "properties": {
+                "duration_ms": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "succeeded": {
+                    "type": "integer",
+                    "example": 990
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 1000
+                }
+            }
+        },
+        "handlers.DiagnosticSatellite": {
+            "description": "Satélite usado en el cálculo",
+            "type": "object",
+            "properties": {
+                "distance": {
+                    "type": "number",
+                    "example": 485.7
+                },
+                "name": {
+                    "type": "string",
+                    "example": "kenobi"
+                },
+                "padding": {
+                    "description": "Padding son las palabras vacías añadidas al inicio de su mensaje",
+                    "type": "integer",
+                    "example": 0
+                },
+                "position": {
+                    "$ref": "#/definitions/handlers.Position"
+                },
+                "residual": {
+                    "description": "Residual es |posición - satélite| - distancia para la posición estimada",
+                    "type": "number",
+                    "example": 0.0003
+                },
+                "weight": {
+                    "type": "number",
+                    "example": 1
+                }
+            }
+        },
+        "handlers.EventsResponse": {
+            "description": "Eventos del flujo en orden de secuencia",
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/events.Event"
+                    }
+                },
+                "horizon": {
+                    "description": "Horizon es la secuencia del último evento compactado; los anteriores ya\nno se listan ni se pueden reproducir",
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "handlers.FieldError": {
+            "description": "Campo o parámetro inválido",
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "Field es la ruta del campo en el cuerpo (p. ej. satellites.distance) o\nel nombre del parámetro",
+                    "type": "string",
+                    "example": "satellites.distance"
+                },
+                "in": {
+                    "description": "In indica dónde está el campo: body, query, path o header",
+                    "type": "string",
+                    "example": "body"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "expected number"
+                }
+            }
+        },
+        "handlers.JobRequest": {
+            "description": "Trabajo de localización a encolar",
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.BatchItem"
+                    }
+                },
+                "satellites": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.SatelliteInfo"
+                    }
+                }
+            }
+        },
+        "handlers.LocateDiagnostics": {
+            "description": "Diagnóstico del cálculo, solo con ?debug=true y permiso de depuración",
+            "type": "object",
+            "properties": {
+                "determinant": {
+                    "description": "Determinant es el determinante del sistema lineal; cerca de 0 indica\nsatélites casi colineales",
+                    "type": "number",
+                    "example": -480000
+                },
+                "estimate": {
+                    "description": "Estimate es la posición calculada en doble precisión",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handlers.PrecisePosition"
+                        }
+                    ]
+                },
+                "iterations": {
+                    "description": "Iterations son las iteraciones de refinamiento del método ponderado",
+                    "type": "integer",
+                    "example": 4
+                },
+                "merge": {
+                    "description": "Merge explica de qué satélite sale cada palabra del mensaje",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.MergedWord"
+                    }
+                },
+                "satellites": {
+                    "description": "Satellites son los satélites usados, en el orden en que entran al cálculo",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.DiagnosticSatellite"
+                    }
+                },
+                "solver": {
+                    "description": "Solver es el método usado: trilateration (tres satélites activos) o\nweighted_least_squares",
+                    "type": "string",
+                    "example": "trilateration"
+                },
+                "solver_error": {
+                    "description": "SolverError es el fallo del método. La trilateración exacta devuelve\n(0, 0) cuando falla, así que puede aparecer junto a una posición.",
+                    "type": "string"
+                },
+                "tolerance": {
+                    "description": "Tolerance es el residuo máximo admitido por el método, si lo limita",
+                    "type": "number",
+                    "example": 10
+                }
+            }
+        },
+        "handlers.LocationV2": {
+            "description": "Posición, mensaje y satélites que participaron en el cálculo",
+            "type": "object",
+            "properties": {
+                "debug": {
+                    "description": "Debug es el diagnóstico del cálculo, solo con ?debug=true",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handlers.LocateDiagnostics"
+                        }
+                    ]
+                },
+                "message": {
+                    "type": "string",
+                    "example": "este es un mensaje secreto"
+                },
+                "position": {
+                    "$ref": "#/definitions/handlers.PrecisePosition"
+                },
+                "satellites": {
+                    "description": "Satellites son los satélites usados, en el orden en que entran al cálculo",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.SatelliteContribution"
+                    }
+                },
+                "skipped": {
+                    "description": "Skipped lista los satélites que no participaron en el cálculo",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.SkippedSatellite"
+                    }
+                }
+            }
+        },
+        "handlers.MergedWord": {
+            "description": "Palabra del mensaje reconstruido",
+            "type": "object",
+            "properties": {
+                "discarded": {
+                    "description": "Discarded son palabras distintas que traían satélites posteriores",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "position": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "source": {
+                    "description": "Source es el satélite del que se tomó; vacío si ninguno la tenía",
+                    "type": "string",
+                    "example": "kenobi"
+                },
+                "word": {
+                    "type": "string",
+                    "example": "este"
+                }
+            }
+        },
+        "handlers.Position": {
+            "description": "Coordenadas de la fuente",
+            "type": "object",
+            "properties": {
+                "x": {
+                    "type": "number",
+                    "example": 426.4001
+                },
+                "y": {
+                    "type": "number",
+                    "example": -252.80016
+                }
+            }
+        },
+        "handlers.PrecisePosition": {
+            "description": "Coordenadas de la fuente en doble precisión",
+            "type": "object",
+            "properties": {
+                "x": {
+                    "type": "number",
+                    "example": -487.2859125
+                },
+                "y": {
+                    "type": "number",
+                    "example": 1557.014225
+                }
+            }
+        },
+        "handlers.Problem": {
+            "description": "Error con formato RFC 7807",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handlers.ProblemCode"
+                        }
+                    ],
+                    "example": "not_enough_satellites"
+                },
+                "debug": {
+                    "description": "Debug es el diagnóstico del cálculo, solo con ?debug=true",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handlers.LocateDiagnostics"
+                        }
+                    ]
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "Not enough satellite data"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/api/v1/topsecret_split"
+                },
+                "missing": {
+                    "description": "Missing son los satélites de los que falta lectura",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "sato"
+                    ]
+                },
+                "request_id": {
+                    "description": "RequestID es el identificador de la petición, el mismo que X-Request-ID",
+                    "type": "string",
+                    "example": "6f1c2a9e0b7d4e3f8a5b6c7d8e9f0a1b"
+                },
+                "residuals": {
+                    "description": "Residuals son los residuos de la última estimación de la posición",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "skipped": {
+                    "description": "Skipped son los satélites excluidos del cálculo por su estado",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.SkippedSatellite"
+                    }
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not enough satellite data"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "/api/v1/problems/not_enough_satellites"
+                }
+            }
+        },
+        "handlers.ProblemCode": {
+            "type": "string",
+            "enum": [
+                "invalid_request",
+                "validation_failed",
+                "payload_too_large",
+                "unauthorized",
+                "forbidden",
+                "invalid_signature",
+                "invalid_parameter",
+                "batch_too_large",
+                "invalid_archive",
+                "satellite_not_found",
+                "not_enough_satellites",
+                "location_unavailable",
+                "message_undecodable",
+                "precondition_failed",
+                "version_conflict",
+                "job_not_found",
+                "job_finished",
+                "problem_not_found",
+                "rate_limited",
+                "quota_exceeded",
+                "queue_full",
+                "shutting_down",
+                "canceled",
+                "internal_error"
+            ],
+            "x-enum-varnames": [
+                "CodeInvalidRequest",
+                "CodeValidationFailed",
+                "CodePayloadTooLarge",
+                "CodeUnauthorized",
+                "CodeForbidden",
+                "CodeInvalidSignature",
+                "CodeInvalidParameter",
+                "CodeBatchTooLarge",
+                "CodeInvalidArchive",
+                "CodeSatelliteNotFound",
+                "CodeNotEnoughSatellites",
+                "CodeLocationUnavailable",
+                "CodeMessageUndecodable",
+                "CodePreconditionFailed",
+                "CodeVersionConflict",
+                "CodeJobNotFound",
+                "CodeJobFinished",
+                "CodeProblemNotFound",
+                "CodeRateLimited",
+                "CodeQuotaExceeded",
+                "CodeQueueFull",
+                "CodeShuttingDown",
+                "CodeCanceled",
+                "CodeInternal"
+            ]
+        },
+        "handlers.ProblemType": {
+            "description": "Entrada del catálogo de errores",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handlers.ProblemCode"
+                        }
+                    ],
+                    "example": "not_enough_satellites"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not enough satellite data"
+                }
+            }
+        },
+        "handlers.QuotasResponse": {
+            "description": "Peticiones aceptadas hoy (UTC) por cada cliente en cada regla",
+            "type": "object",
+            "properties": {
+                "quotas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ratelimit.Usage"
+                    }
+                }
+            }
+        },
+        "handlers.ReplayResponse": {
+            "description": "Satélites y resultado que la API habría devuelto en ese punto del flujo",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code es el código del catálogo de errores correspondiente a Error",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handlers.ProblemCode"
+                        }
+                    ],
+                    "example": "not_enough_satellites"
+                },
+                "error": {
+                    "description": "Error es el error que habría devuelto GET /topsecret_split, si lo hubo",
+                    "type": "string",
+                    "example": "Not enough satellite data"
+                },
+                "result": {
+                    "description": "Result es la respuesta de GET /topsecret_split, si se podía calcular",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handlers.TopSecretResponse"
+                        }
+                    ]
+                },
+                "satellites": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/repository.Satellite"
+                    }
+                },
+                "seq": {
+                    "description": "Seq es la secuencia del último evento aplicado",
+                    "type": "integer",
+                    "example": 42
+                },
+                "skipped": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.SkippedSatellite"
+                    }
+                }
+            }
+        },
+        "handlers.SatelliteContribution": {
+            "description": "Satélite usado en el cálculo",
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "kenobi"
+                },
+                "residual": {
+                    "description": "Residual es |posición - satélite| - distancia",
+                    "type": "number",
+                    "example": 0.0003
+                },
+                "weight": {
+                    "type": "number",
+                    "example": 1
+                }
+            }
+        },
+        "handlers.SatelliteInfo": {
+            "description": "Información individual de un satélite",
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "distance": {
+                    "type": "number",
+                    "example": 927.75
+                },
+                "message": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "[\"este\"",
+                        " \"\"",
+                        " \"\"",
+                        " \"mensaje\"",
+                        " \"\"]"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "kenobi"
+                }
+            }
+        },
+        "handlers.SatelliteReadingResponse": {
+            "description": "Lectura actual de un satélite junto con sus metadatos",
+            "type": "object",
+            "properties": {
+                "distance": {
+                    "type": "number",
+                    "example": 927.75
+                },
+                "message": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "[\"este\"",
+                        " \"\"",
                         " \"\"",
                         " \"mensaje\"",
                         " \"\"]"
@@ -222,6 +2339,133 @@ const docTemplate = `{
                 "name": {
                     "type": "string",
                     "example": "kenobi"
+                },
+                "position": {
+                    "$ref": "#/definitions/handlers.Position"
+                },
+                "status": {
+                    "$ref": "#/definitions/repository.Status"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-01-01T00:00:00Z"
+                }
+            }
+        },
+        "handlers.SatelliteReadingsResponse": {
+            "description": "Lecturas de un satélite en orden cronológico con estadísticas por ventana",
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "offset": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "readings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/history.Reading"
+                    }
+                },
+                "satellite": {
+                    "type": "string",
+                    "example": "kenobi"
+                },
+                "stats": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/history.WindowStats"
+                    }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 250
+                }
+            }
+        },
+        "handlers.SatelliteStatusRequest": {
+            "description": "Nuevo estado operativo de un satélite",
+            "type": "object",
+            "required": [
+                "state"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "antenna recalibration"
+                },
+                "state": {
+                    "enum": [
+                        "active",
+                        "degraded",
+                        "maintenance",
+                        "decommissioned"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/repository.StatusState"
+                        }
+                    ],
+                    "example": "maintenance"
+                }
+            }
+        },
+        "handlers.SkippedSatellite": {
+            "description": "Satélite excluido del cálculo",
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "description": "Detail es el motivo registrado junto al estado operativo, si lo hay",
+                    "type": "string",
+                    "example": "antenna recalibration"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "sato"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "maintenance"
+                }
+            }
+        },
+        "handlers.StationReply": {
+            "description": "Frame enviado a una estación",
+            "type": "object",
+            "properties": {
+                "etag": {
+                    "type": "string",
+                    "example": "\"4\""
+                },
+                "id": {
+                    "type": "string",
+                    "example": "42"
+                },
+                "problem": {
+                    "description": "Problem es el error, con el mismo formato que en la API REST",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    ]
+                },
+                "result": {
+                    "$ref": "#/definitions/handlers.TopSecretResponse"
+                },
+                "satellite": {
+                    "type": "string",
+                    "example": "kenobi"
+                },
+                "station": {
+                    "type": "string",
+                    "example": "station-1"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "ack"
                 }
             }
         },
@@ -241,12 +2485,27 @@ const docTemplate = `{
             "description": "Respuesta con posición y mensaje decodificado",
             "type": "object",
             "properties": {
+                "debug": {
+                    "description": "Debug es el diagnóstico del cálculo, solo con ?debug=true",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handlers.LocateDiagnostics"
+                        }
+                    ]
+                },
                 "message": {
                     "type": "string",
                     "example": "este es un mensaje secreto"
                 },
                 "position": {
                     "$ref": "#/definitions/handlers.Position"
+                },
+                "skipped": {
+                    "description": "Skipped lista los satélites que no participaron en el cálculo",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.SkippedSatellite"
+                    }
                 }
             }
         },
@@ -263,6 +2522,227 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "history.Reading": {
+            "type": "object",
+            "properties": {
+                "distance": {
+                    "type": "number"
+                },
+                "message": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "satellite": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "history.WindowStats": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "max": {
+                    "type": "number"
+                },
+                "mean": {
+                    "type": "number"
+                },
+                "min": {
+                    "type": "number"
+                },
+                "stddev": {
+                    "type": "number"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "jobs.Job": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "6f1c2a9e0b7d4e3f8a5b6c7d8e9f0a1b"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "batch"
+                },
+                "problem": {
+                    "description": "Problem es el detalle del error cuando el trabajo falla con un Failure"
+                },
+                "result": {
+                    "description": "Result es el resultado del trabajo cuando termina con éxito"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/jobs.Status"
+                        }
+                    ],
+                    "example": "running"
+                }
+            }
+        },
+        "jobs.Status": {
+            "type": "string",
+            "enum": [
+                "queued",
+                "running",
+                "succeeded",
+                "failed",
+                "canceled"
+            ],
+            "x-enum-varnames": [
+                "StatusQueued",
+                "StatusRunning",
+                "StatusSucceeded",
+                "StatusFailed",
+                "StatusCanceled"
+            ]
+        },
+        "ratelimit.Usage": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string",
+                    "example": "kenobi"
+                },
+                "quota": {
+                    "description": "Quota es la cuota diaria de la regla; 0 si no tiene",
+                    "type": "integer",
+                    "example": 10000
+                },
+                "remaining": {
+                    "type": "integer",
+                    "example": 9880
+                },
+                "resets_at": {
+                    "type": "string"
+                },
+                "route": {
+                    "type": "string",
+                    "example": "POST /topsecret_split/:satellite_name"
+                },
+                "used": {
+                    "type": "integer",
+                    "example": 120
+                }
+            }
+        },
+        "repository.Point": {
+            "type": "object",
+            "properties": {
+                "x": {
+                    "type": "number"
+                },
+                "y": {
+                    "type": "number"
+                }
+            }
+        },
+        "repository.Satellite": {
+            "type": "object",
+            "properties": {
+                "distance": {
+                    "type": "number"
+                },
+                "message": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "position": {
+                    "$ref": "#/definitions/repository.Point"
+                },
+                "status": {
+                    "description": "Status es el estado operativo del receptor",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/repository.Status"
+                        }
+                    ]
+                },
+                "updated_at": {
+                    "description": "UpdatedAt es el momento de la última escritura sobre el satélite",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version se incrementa en uno con cada escritura; 0 indica que el satélite no existe",
+                    "type": "integer"
+                }
+            }
+        },
+        "repository.Status": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                },
+                "since": {
+                    "type": "string"
+                },
+                "state": {
+                    "$ref": "#/definitions/repository.StatusState"
+                }
+            }
+        },
+        "repository.StatusState": {
+            "type": "string",
+            "enum": [
+                "active",
+                "degraded",
+                "maintenance",
+                "decommissioned"
+            ],
+            "x-enum-varnames": [
+                "StatusActive",
+                "StatusDegraded",
+                "StatusMaintenance",
+                "StatusDecommissioned"
+            ]
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "Clave estática; el servidor solo guarda su SHA-256",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "JWT firmado con HS256 o EdDSA, con el formato \"Bearer {token}\" y los roles en el claim roles",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -274,7 +2754,7 @@ var SwaggerInfo = &swag.Spec{
 	BasePath:         "/api",
 	Schemes:          []string{},
 	Title:            "Fuego de Quasar API",
-	Description:      "API para el desafío de nivel 3 de Fuego de Quasar.",
+	Description:      "API para el desafío de nivel 3 de Fuego de Quasar.\nLos errores se devuelven como application/problem+json (RFC 7807) con un código estable en el campo code; el catálogo de códigos está en GET /api/v1/problems.\nLas rutas están versionadas bajo /api/v1 y /api/v2. Las mismas rutas sin versión en la raíz son alias obsoletos de /api/v1 y lo indican con las cabeceras Deprecation, Sunset y Link.\nCada ruta exige uno de los roles station (enviar lecturas), analyst (consultar y calcular) o admin (todo), con una clave en X-API-Key o un JWT en Authorization.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"fuegodequasar/internal/platform/audit"
+	"fuegodequasar/internal/platform/auth"
+	"fuegodequasar/internal/platform/backup"
+	"fuegodequasar/internal/platform/events"
+	"fuegodequasar/internal/platform/ratelimit"
+	"fuegodequasar/internal/platform/repository"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditLogResponse representa el resultado de una consulta al registro de auditoría
+// @Description Cambios registrados sobre los satélites, en orden cronológico
+type AuditLogResponse struct {
+	Entries []audit.Entry `json:"entries"`
+}
+
+// SatelliteStatusRequest representa el cambio de estado operativo de un satélite
+// @Description Nuevo estado operativo de un satélite
+type SatelliteStatusRequest struct {
+	State  repository.StatusState `json:"state" binding:"required,oneof=active degraded maintenance decommissioned" example:"maintenance" enums:"active,degraded,maintenance,decommissioned"`
+	Reason string                 `json:"reason" example:"antenna recalibration"`
+}
+
+// EventsResponse representa una página del flujo de eventos
+// @Description Eventos del flujo en orden de secuencia
+type EventsResponse struct {
+	Events []events.Event `json:"events"`
+	// Horizon es la secuencia del último evento compactado; los anteriores ya
+	// no se listan ni se pueden reproducir
+	Horizon uint64 `json:"horizon" example:"0"`
+}
+
+// ReplayResponse representa el estado reproducido en un punto del flujo de eventos
+// @Description Satélites y resultado que la API habría devuelto en ese punto del flujo
+type ReplayResponse struct {
+	// Seq es la secuencia del último evento aplicado
+	Seq        uint64                 `json:"seq" example:"42"`
+	Satellites []repository.Satellite `json:"satellites"`
+	// Result es la respuesta de GET /topsecret_split, si se podía calcular
+	Result *TopSecretResponse `json:"result,omitempty"`
+	// Error es el error que habría devuelto GET /topsecret_split, si lo hubo
+	Error string `json:"error,omitempty" example:"Not enough satellite data"`
+	// Code es el código del catálogo de errores correspondiente a Error
+	Code    ProblemCode        `json:"code,omitempty" example:"not_enough_satellites"`
+	Skipped []SkippedSatellite `json:"skipped,omitempty"`
+}
+
+// SetupAdminRoutes configura las rutas HTTP de administración
+func SetupAdminRoutes(router gin.IRouter, repo repository.RepositoryService, auditLog *audit.Log, eventStore *events.Store, limiter *ratelimit.Limiter) {
+	admin := router.Group("/admin", RequireRole(auth.RoleAdmin))
+	// GET /admin/audit
+	admin.GET("/audit", handleGetAuditLog(auditLog))
+	// GET /admin/backup
+	admin.GET("/backup", handleGetBackup(repo))
+	// POST /admin/restore
+	admin.POST("/restore", handleRestoreBackup(repo))
+	// PUT /admin/satellites/{satellite_name}/status
+	admin.PUT("/satellites/:satellite_name/status", handleSetSatelliteStatus(repo))
+	// GET /admin/events
+	admin.GET("/events", handleGetEvents(eventStore))
+	// GET /admin/replay
+	admin.GET("/replay", handleReplay(eventStore))
+	// GET /admin/quotas
+	admin.GET("/quotas", handleGetQuotas(limiter))
+}
+
+// @Summary Consulta el registro de auditoría
+// @Description Devuelve los cambios sobre los satélites filtrados por intervalo de tiempo y satélite
+// @Tags admin
+// @Produce json
+// @Param from query string false "Inicio del intervalo (RFC 3339, incluido)"
+// @Param to query string false "Fin del intervalo (RFC 3339, excluido)"
+// @Param satellite query string false "Nombre del satélite"
+// @Param limit query int false "Número máximo de entradas, las más recientes"
+// @Success 200 {object} AuditLogResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/audit [get]
+func handleGetAuditLog(auditLog *audit.Log) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := audit.Filter{Satellite: c.Query("satellite")}
+
+		var err error
+		if filter.From, err = parseTimeQuery(c, "from"); err != nil {
+			paramProblem(c, "query", "from", "Invalid 'from' time, expected RFC 3339", "expected RFC 3339")
+			return
+		}
+		if filter.To, err = parseTimeQuery(c, "to"); err != nil {
+			paramProblem(c, "query", "to", "Invalid 'to' time, expected RFC 3339", "expected RFC 3339")
+			return
+		}
+		if limit := c.Query("limit"); limit != "" {
+			if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit < 0 {
+				paramProblem(c, "query", "limit", "Invalid 'limit', expected a non-negative integer", "expected a non-negative integer")
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, AuditLogResponse{Entries: auditLog.Query(filter)})
+	}
+}
+
+// @Summary Exporta el estado del repositorio
+// @Description Descarga un archivo JSON versionado con la constelación y las lecturas actuales
+// @Tags admin
+// @Produce json
+// @Success 200 {object} backup.Archive
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/backup [get]
+func handleGetBackup(repo repository.RepositoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		archive, err := backup.Export(c.Request.Context(), repo)
+		if err != nil {
+			abortProblem(c, CodeInternal, "Failed to export satellites")
+			return
+		}
+
+		filename := "fuegodequasar-backup-" + archive.CreatedAt.Format("20060102T150405Z") + ".json"
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+		c.JSON(http.StatusOK, archive)
+	}
+}
+
+// @Summary Importa el estado del repositorio
+// @Description Valida un archivo generado por /admin/backup y lo aplica. En modo merge se guardan los satélites del archivo sin tocar los demás; en modo replace el repositorio queda exactamente como el archivo.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param mode query string false "Modo de importación" Enums(merge, replace) default(merge)
+// @Param archive body backup.Archive true "Archivo de copia de seguridad"
+// @Success 200 {object} backup.Result
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/restore [post]
+func handleRestoreBackup(repo repository.RepositoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		mode, err := backup.ParseMode(c.Query("mode"))
+		if err != nil {
+			paramProblem(c, "query", "mode", "Invalid 'mode', expected merge or replace", "expected merge or replace")
+			return
+		}
+
+		var archive backup.Archive
+		if err := c.ShouldBindJSON(&archive); err != nil {
+			respondProblem(c, bindingProblem(err))
+			return
+		}
+
+		result, err := backup.Import(c.Request.Context(), repo, archive, mode)
+		if errors.Is(err, backup.ErrInvalidArchive) {
+			abortProblem(c, CodeInvalidArchive, err.Error())
+			return
+		}
+		if err != nil {
+			abortProblem(c, CodeInternal, "Failed to restore satellites")
+			return
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// @Summary Cambia el estado operativo de un satélite
+// @Description Los satélites en mantenimiento o retirados se excluyen de los cálculos y los degradados pesan menos
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param satellite_name path string true "Nombre del satélite"
+// @Param request body SatelliteStatusRequest true "Nuevo estado"
+// @Success 200 {object} repository.Status
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/satellites/{satellite_name}/status [put]
+func handleSetSatelliteStatus(repo repository.RepositoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request SatelliteStatusRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			respondProblem(c, bindingProblem(err))
+			return
+		}
+
+		var status repository.Status
+		_, err := updateSatellite(c.Request.Context(), repo, c.Param("satellite_name"), false, "", func(satellite *repository.Satellite) {
+			// Since solo cambia cuando cambia el estado, no al corregir el motivo
+			since := satellite.Status.Since
+			if satellite.Status.Effective() != request.State || since.IsZero() {
+				since = time.Now().UTC()
+			}
+			satellite.Status = repository.Status{State: request.State, Reason: request.Reason, Since: since}
+			status = satellite.Status
+		})
+		switch {
+		case errors.Is(err, repository.ErrSatelliteNotFound):
+			abortProblem(c, CodeSatelliteNotFound, "Satellite not found")
+			return
+		case errors.Is(err, repository.ErrVersionConflict):
+			abortProblem(c, CodeVersionConflict, "Satellite is being modified concurrently")
+			return
+		case err != nil:
+			abortProblem(c, CodeInternal, "Failed to save satellite status")
+			return
+		}
+
+		c.JSON(http.StatusOK, status)
+	}
+}
+
+// @Summary Consulta el flujo de eventos
+// @Description Devuelve los eventos con secuencia posterior a since. Los eventos que salen de la retención se compactan en una instantánea y dejan de listarse: horizon es la secuencia del último evento compactado.
+// @Tags admin
+// @Produce json
+// @Param since query int false "Secuencia a partir de la cual listar (excluida)" default(0)
+// @Param limit query int false "Número máximo de eventos (máximo 1000)" default(100)
+// @Success 200 {object} EventsResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/events [get]
+func handleGetEvents(eventStore *events.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var since uint64
+		limit := 100
+		var err error
+		if value := c.Query("since"); value != "" {
+			if since, err = strconv.ParseUint(value, 10, 64); err != nil {
+				paramProblem(c, "query", "since", "Invalid 'since', expected a non-negative integer", "expected a non-negative integer")
+				return
+			}
+		}
+		if value := c.Query("limit"); value != "" {
+			if limit, err = strconv.Atoi(value); err != nil || limit < 1 || limit > 1000 {
+				paramProblem(c, "query", "limit", "Invalid 'limit', expected an integer between 1 and 1000", "expected an integer between 1 and 1000")
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, EventsResponse{Events: eventStore.Since(since, limit), Horizon: eventStore.Horizon()})
+	}
+}
+
+// @Summary Reproduce el estado en un punto del pasado
+// @Description Proyecta el flujo de eventos hasta un instante o número de secuencia y calcula la posición y el mensaje que habría devuelto GET /topsecret_split en ese momento. Solo se puede reproducir a partir del horizonte del flujo (ver GET /admin/events): los puntos anteriores responden 400.
+// @Tags admin
+// @Produce json
+// @Param at query string false "Instante a reproducir (RFC 3339, incluido)"
+// @Param seq query int false "Último número de secuencia a aplicar"
+// @Success 200 {object} ReplayResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/replay [get]
+func handleReplay(eventStore *events.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var projection *events.Projection
+		switch {
+		case c.Query("at") != "" && c.Query("seq") != "":
+			abortProblem(c, CodeInvalidParameter, "Use either 'at' or 'seq', not both")
+			return
+		case c.Query("at") != "":
+			at, err := time.Parse(time.RFC3339, c.Query("at"))
+			if err != nil {
+				paramProblem(c, "query", "at", "Invalid 'at' time, expected RFC 3339", "expected RFC 3339")
+				return
+			}
+			if projection, err = eventStore.ReplayUntil(at); err != nil {
+				paramProblem(c, "query", "at", "'at' is before the replay horizon", "must not be before the replay horizon")
+				return
+			}
+		case c.Query("seq") != "":
+			seq, err := strconv.ParseUint(c.Query("seq"), 10, 64)
+			if err != nil {
+				paramProblem(c, "query", "seq", "Invalid 'seq', expected a non-negative integer", "expected a non-negative integer")
+				return
+			}
+			if projection, err = eventStore.ReplayUntilSeq(seq); err != nil {
+				paramProblem(c, "query", "seq", fmt.Sprintf("'seq' is before the replay horizon %d", eventStore.Horizon()), "must not be before the replay horizon")
+				return
+			}
+		default:
+			projection = eventStore.Replay()
+		}
+
+		response := ReplayResponse{Seq: projection.Seq, Satellites: projection.Satellites()}
+		result, err := locate(response.Satellites)
+		response.Skipped = result.Skipped
+		if err != nil {
+			response.Error = locateErrorMessage(err)
+			response.Code = locateProblem(err, nil).Code
+		} else {
+			result.Skipped = nil
+			response.Result = &result
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// parseTimeQuery lee un parámetro de consulta en formato RFC 3339; vacío devuelve el tiempo cero
+func parseTimeQuery(c *gin.Context, name string) (time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
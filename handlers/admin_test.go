@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fuegodequasar/internal/platform/events"
+	"fuegodequasar/internal/platform/repository"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSetSatelliteStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PUT("/admin/satellites/:satellite_name/status", handleSetSatelliteStatus(repository.New()))
+
+	tests := []struct {
+		name      string
+		satellite string
+		body      string
+		want      int
+		wantCode  ProblemCode
+		wantError FieldError
+	}{
+		{"maintenance", "kenobi", `{"state":"maintenance","reason":"antenna recalibration"}`, http.StatusOK, "", FieldError{}},
+		{"unknown state", "kenobi", `{"state":"sleeping"}`, http.StatusBadRequest, CodeValidationFailed,
+			FieldError{Field: "state", In: "body", Message: "must be one of active, degraded, maintenance, decommissioned"}},
+		{"missing state", "kenobi", `{"reason":"antenna recalibration"}`, http.StatusBadRequest, CodeValidationFailed,
+			FieldError{Field: "state", In: "body", Message: "is required"}},
+		{"unknown satellite", "yoda", `{"state":"active"}`, http.StatusNotFound, CodeSatelliteNotFound, FieldError{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/admin/satellites/"+tt.satellite+"/status", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			var problem Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("decode problem: %v", err)
+			}
+			if problem.Code != tt.wantCode {
+				t.Fatalf("code = %q, want %q", problem.Code, tt.wantCode)
+			}
+			if tt.wantError != (FieldError{}) && (len(problem.Errors) != 1 || problem.Errors[0] != tt.wantError) {
+				t.Fatalf("errors = %+v, want [%+v]", problem.Errors, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestReplayHorizon(t *testing.T) {
+	// Las 3 altas de la constelación salen de la retención con las 2 lecturas
+	store := events.NewStore(events.Retention{MaxEvents: 2})
+	repo := events.NewRepository(store, repository.DefaultConstellation())
+	saveTransmission(t, repo, transmission("", -100, 75.5))
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/admin/events", handleGetEvents(store))
+	router.GET("/admin/replay", handleReplay(store))
+
+	w := serve(router, http.MethodGet, "/admin/events", "", nil)
+	var page EventsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("decode events: %v: %s", err, w.Body)
+	}
+	if page.Horizon != 4 || len(page.Events) != 2 || page.Events[0].Seq != 5 {
+		t.Fatalf("events = %+v, want the 2 events after horizon 4", page)
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		want      int
+		wantField string
+		wantSeq   uint64
+	}{
+		{"latest", "", http.StatusOK, "", 6},
+		{"at the horizon", "?seq=4", http.StatusOK, "", 4},
+		{"before the horizon", "?seq=3", http.StatusBadRequest, "seq", 0},
+		{"time before the horizon", "?at=2000-01-01T00:00:00Z", http.StatusBadRequest, "at", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodGet, "/admin/replay"+tt.query, "", nil)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if tt.wantField != "" {
+				if problem := decodeProblem(t, w); problem.Code != CodeInvalidParameter || len(problem.Errors) != 1 || problem.Errors[0].Field != tt.wantField {
+					t.Fatalf("problem = %+v, want an invalid %q", problem, tt.wantField)
+				}
+				return
+			}
+			var replay ReplayResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &replay); err != nil {
+				t.Fatalf("decode replay: %v: %s", err, w.Body)
+			}
+			if replay.Seq != tt.wantSeq || len(replay.Satellites) != 3 {
+				t.Fatalf("replay = %+v, want the constellation at seq %d", replay, tt.wantSeq)
+			}
+		})
+	}
+}
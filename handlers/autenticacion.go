@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fuegodequasar/internal/platform/audit"
+	"fuegodequasar/internal/platform/auth"
+	"io"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// authChallenge es la cabecera WWW-Authenticate de las respuestas 401
+const authChallenge = `Bearer realm="fuegodequasar", ApiKey realm="fuegodequasar"`
+
+// satelliteVerifiedKey es la clave del contexto de gin que indica que el
+// certificado de cliente ya identificó al satélite de la ruta
+const satelliteVerifiedKey = "satellite_verified"
+
+// maxSignedBodyBytes limita el cuerpo que se lee para comprobar su firma
+const maxSignedBodyBytes = 64 << 10
+
+// anonymousPrincipal es el principal de todas las peticiones cuando no hay
+// autenticación configurada: tiene todos los roles, como antes de que la API
+// exigiera credenciales
+var anonymousPrincipal = auth.Principal{
+	Subject: "anonymous",
+	Roles:   []auth.Role{auth.RoleStation, auth.RoleAnalyst, auth.RoleAdmin},
+	Method:  "none",
+}
+
+// Authenticate identifica al cliente con authenticator y deja el principal en
+// el contexto de la petición. Unas credenciales inválidas se rechazan con 401
+// en cualquier ruta; una petición sin credenciales sigue adelante y son los
+// grupos con RequireRole los que la rechazan. Con authenticator nil no se
+// exige autenticación y toda petición actúa como anonymousPrincipal.
+//
+// Debe ir después de AuditActor: el actor de la auditoría pasa a ser el
+// principal autenticado en lugar de la cabecera X-Caller-ID.
+func Authenticate(authenticator auth.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authenticator == nil {
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), anonymousPrincipal))
+			c.Next()
+			return
+		}
+
+		principal, err := authenticator.Authenticate(c.Request.Context(), c.Request.Header)
+		switch {
+		case errors.Is(err, auth.ErrNoCredentials):
+			c.Next()
+			return
+		case err != nil:
+			c.Header("WWW-Authenticate", authChallenge)
+			abortProblem(c, CodeUnauthorized, "Invalid credentials")
+			return
+		}
+
+		ctx := auth.WithPrincipal(c.Request.Context(), principal)
+		actor := audit.ActorFrom(ctx)
+		actor.ID = principal.Subject
+		c.Request = c.Request.WithContext(audit.WithActor(ctx, actor))
+		c.Next()
+	}
+}
+
+// RequireRole exige que el principal tenga alguno de los roles; admin cumple
+// cualquiera. Sin principal responde 401 y con un rol insuficiente 403.
+func RequireRole(roles ...auth.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := auth.PrincipalFrom(c.Request.Context())
+		if !ok {
+			c.Header("WWW-Authenticate", authChallenge)
+			abortProblem(c, CodeUnauthorized, "Authentication required")
+			return
+		}
+		if !principal.HasRole(roles...) {
+			abortProblem(c, CodeForbidden, "Insufficient role for this operation")
+			return
+		}
+		c.Next()
+	}
+}
+
+// ClientCertificate comprueba que el certificado de cliente verificado de la
+// conexión (mTLS), si lo hay, identifica al satélite del parámetro
+// satellite_name por su CN o un SAN; si no, rechaza la petición con 403. Si la
+// petición no trae otras credenciales, el certificado la autentica como
+// estación de ese satélite. Sin certificado no hace nada.
+//
+// Debe ir después de Authenticate y antes de RequireRole.
+func ClientCertificate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		satellites := auth.VerifiedSatellites(c.Request.TLS)
+		if len(satellites) == 0 {
+			c.Next()
+			return
+		}
+		if !slices.Contains(satellites, c.Param("satellite_name")) {
+			abortProblem(c, CodeForbidden, "Client certificate does not identify this satellite")
+			return
+		}
+		c.Set(satelliteVerifiedKey, true)
+
+		if _, ok := auth.PrincipalFrom(c.Request.Context()); !ok {
+			principal := auth.CertificatePrincipal(satellites)
+			ctx := auth.WithPrincipal(c.Request.Context(), principal)
+			actor := audit.ActorFrom(ctx)
+			actor.ID = principal.Subject
+			c.Request = c.Request.WithContext(audit.WithActor(ctx, actor))
+		}
+		c.Next()
+	}
+}
+
+// RequireSatelliteCredential protege las operaciones sobre un satélite que no
+// llevan cuerpo que firmar, como borrar su lectura. Si se exigen firmas, solo
+// las acepta de una conexión cuyo certificado identificó al satélite o de
+// admin; si no, basta el rol de estación.
+//
+// Debe ir después de ClientCertificate.
+func RequireSatelliteCredential(signatures *auth.SignatureVerifier) gin.HandlerFunc {
+	requireRole := RequireRole(unsignedReadingsRole(signatures))
+	return func(c *gin.Context) {
+		if c.GetBool(satelliteVerifiedKey) {
+			c.Next()
+			return
+		}
+		requireRole(c)
+	}
+}
+
+// RequireSatelliteSignature exige que el cuerpo de la petición lo haya firmado
+// el satélite del parámetro satellite_name, con las cabeceras X-Signature y
+// X-Signature-Timestamp. Con signatures nil no se exige firma, y tampoco si
+// ClientCertificate ya identificó al satélite.
+func RequireSatelliteSignature(signatures *auth.SignatureVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if signatures == nil || c.GetBool(satelliteVerifiedKey) {
+			c.Next()
+			return
+		}
+
+		// La firma cubre el cuerpo tal como llega: se lee entero y se repone
+		// para que el handler lo pueda decodificar
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxSignedBodyBytes+1))
+		if err != nil {
+			abortProblem(c, CodeInvalidRequest, "Failed to read request body")
+			return
+		}
+		if len(body) > maxSignedBodyBytes {
+			abortProblem(c, CodePayloadTooLarge, "Request body is too large")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		err = signatures.Verify(c.Param("satellite_name"), c.GetHeader(auth.TimestampHeader), c.GetHeader(auth.SignatureHeader), body)
+		if err != nil {
+			abortProblem(c, CodeInvalidSignature, signatureErrorMessage(err))
+			return
+		}
+		c.Next()
+	}
+}
+
+// signatureErrorMessage traduce un error de verificación al texto de la API
+func signatureErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, auth.ErrUnknownSatellite):
+		return "Satellite has no registered credential"
+	case errors.Is(err, auth.ErrMissingSignature):
+		return "Missing X-Signature or X-Signature-Timestamp header"
+	case errors.Is(err, auth.ErrStaleTimestamp):
+		return "Signature timestamp is outside the replay window"
+	case errors.Is(err, auth.ErrReplayedSignature):
+		return "Signature was already used"
+	}
+	return "Signature does not match the request"
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"fuegodequasar/internal/platform/auth"
+	"fuegodequasar/internal/platform/history"
+	"fuegodequasar/internal/platform/repository"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAuthenticateAndRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keys, err := auth.ParseAPIKeys([]byte(`[` +
+		`{"id":"station","sha256":"` + auth.HashAPIKey("station-key") + `","roles":["station"]},` +
+		`{"id":"analyst","sha256":"` + auth.HashAPIKey("analyst-key") + `","roles":["analyst"]},` +
+		`{"id":"admin","sha256":"` + auth.HashAPIKey("admin-key") + `","roles":["admin"]}]`))
+	if err != nil {
+		t.Fatalf("ParseAPIKeys: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		authenticator auth.Authenticator
+		key           string
+		path          string
+		want          int
+	}{
+		{"station on station route", keys, "station-key", "/station", http.StatusOK},
+		{"station on analyst route", keys, "station-key", "/analyst", http.StatusForbidden},
+		{"analyst on analyst route", keys, "analyst-key", "/analyst", http.StatusOK},
+		{"admin on any route", keys, "admin-key", "/station", http.StatusOK},
+		{"no credentials on public route", keys, "", "/public", http.StatusOK},
+		{"no credentials on protected route", keys, "", "/analyst", http.StatusUnauthorized},
+		// Unas credenciales inválidas se rechazan también en las rutas públicas
+		{"invalid key on public route", keys, "guess", "/public", http.StatusUnauthorized},
+		{"no authentication configured", nil, "", "/analyst", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(AuditActor(), Authenticate(tt.authenticator))
+			ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+			router.GET("/public", ok)
+			router.GET("/station", RequireRole(auth.RoleStation), ok)
+			router.GET("/analyst", RequireRole(auth.RoleAnalyst), ok)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.key != "" {
+				req.Header.Set(auth.APIKeyHeader, tt.key)
+			}
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") != authChallenge {
+				t.Fatalf("WWW-Authenticate = %q, want %q", w.Header().Get("WWW-Authenticate"), authChallenge)
+			}
+		})
+	}
+}
+
+func TestRequireSatelliteSignature(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	signatures, err := auth.ParseSatelliteCredentials([]byte(`[{"satellite":"kenobi","hmac_secret":"`+
+		base64.StdEncoding.EncodeToString(secret)+`"}]`), time.Minute)
+	if err != nil {
+		t.Fatalf("ParseSatelliteCredentials: %v", err)
+	}
+	sign := func(satellite string, body string) (string, string) {
+		ts := time.Now().Unix()
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(auth.SigningString(satellite, ts, []byte(body)))
+		return strconv.FormatInt(ts, 10), base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+	body := `{"distance":100,"message":["este","","un"]}`
+	timestamp, signature := sign("kenobi", body)
+	otherTimestamp, otherSignature := sign("kenobi", `{"distance":1,"message":["otro"]}`)
+
+	tests := []struct {
+		name        string
+		signatures  *auth.SignatureVerifier
+		satellite   string
+		body        string
+		timestamp   string
+		signature   string
+		certificate string
+		want        int
+	}{
+		{"signed", signatures, "kenobi", body, timestamp, signature, "", http.StatusOK},
+		{"signatures not required", nil, "kenobi", body, "", "", "", http.StatusOK},
+		{"unsigned", signatures, "kenobi", body, "", "", "", http.StatusUnauthorized},
+		{"signature of another body", signatures, "kenobi", body, otherTimestamp, otherSignature, "", http.StatusUnauthorized},
+		{"satellite without credential", signatures, "sato", body, timestamp, signature, "", http.StatusUnauthorized},
+		{"too large", signatures, "kenobi", strings.Repeat(" ", maxSignedBodyBytes+1), timestamp, signature, "", http.StatusRequestEntityTooLarge},
+		// El certificado del satélite sustituye a la firma
+		{"client certificate", signatures, "kenobi", body, "", "", "kenobi", http.StatusOK},
+		{"certificate of another satellite", signatures, "kenobi", body, "", "", "skywalker", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(AuditActor(), Authenticate(nil))
+			var received string
+			router.POST("/topsecret_split/:satellite_name", ClientCertificate(), RequireSatelliteSignature(tt.signatures), func(c *gin.Context) {
+				data, _ := io.ReadAll(c.Request.Body)
+				received = string(data)
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/topsecret_split/"+tt.satellite, strings.NewReader(tt.body))
+			req.Header.Set(auth.TimestampHeader, tt.timestamp)
+			req.Header.Set(auth.SignatureHeader, tt.signature)
+			if tt.certificate != "" {
+				cert := &x509.Certificate{Subject: pkix.Name{CommonName: tt.certificate}}
+				req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}, VerifiedChains: [][]*x509.Certificate{{cert}}}
+			}
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			// El handler recibe el cuerpo que se verificó
+			if w.Code == http.StatusOK && received != tt.body {
+				t.Fatalf("handler read %q, want %q", received, tt.body)
+			}
+		})
+	}
+
+	// La petición firmada del primer caso no se acepta otra vez
+	router := gin.New()
+	router.POST("/topsecret_split/:satellite_name", RequireSatelliteSignature(signatures), func(c *gin.Context) { c.Status(http.StatusOK) })
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/topsecret_split/kenobi", strings.NewReader(body))
+	req.Header.Set(auth.TimestampHeader, timestamp)
+	req.Header.Set(auth.SignatureHeader, signature)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("replayed request status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestDeleteSatelliteReadingCredential(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keys, err := auth.ParseAPIKeys([]byte(`[` +
+		`{"id":"station","sha256":"` + auth.HashAPIKey("station-key") + `","roles":["station"]},` +
+		`{"id":"admin","sha256":"` + auth.HashAPIKey("admin-key") + `","roles":["admin"]}]`))
+	if err != nil {
+		t.Fatalf("ParseAPIKeys: %v", err)
+	}
+	signatures, err := auth.ParseSatelliteCredentials([]byte(`[{"satellite":"kenobi","hmac_secret":"`+
+		base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))+`"}]`), time.Minute)
+	if err != nil {
+		t.Fatalf("ParseSatelliteCredentials: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		signatures  *auth.SignatureVerifier
+		key         string
+		certificate string
+		want        int
+	}{
+		{"station without signatures", nil, "station-key", "", http.StatusNoContent},
+		// Con firmas una estación cualquiera no puede borrar la lectura de otro satélite
+		{"station with signatures", signatures, "station-key", "", http.StatusForbidden},
+		{"admin with signatures", signatures, "admin-key", "", http.StatusNoContent},
+		{"certificate of the satellite", signatures, "", "kenobi", http.StatusNoContent},
+		{"station with the certificate of the satellite", signatures, "station-key", "kenobi", http.StatusNoContent},
+		{"certificate of another satellite", signatures, "", "skywalker", http.StatusForbidden},
+		{"no credentials", signatures, "", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(AuditActor(), Authenticate(keys))
+			SetupRoutes(context.Background(), router, repository.New(), history.NewStore(history.Retention{}), tt.signatures)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodDelete, "/topsecret_split/kenobi", nil)
+			if tt.key != "" {
+				req.Header.Set(auth.APIKeyHeader, tt.key)
+			}
+			if tt.certificate != "" {
+				cert := &x509.Certificate{Subject: pkix.Name{CommonName: tt.certificate}}
+				req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}, VerifiedChains: [][]*x509.Certificate{{cert}}}
+			}
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSPolicy es la política CORS de la API
+type CORSPolicy struct {
+	// AllowedOrigins son los orígenes admitidos: un origen exacto
+	// (https://app.example.com), un patrón con comodín en el primer nivel del
+	// host (https://*.example.com) o "*" para cualquiera. Solo los orígenes
+	// exactos pueden enviar credenciales.
+	AllowedOrigins []string
+	// AllowedMethods son los métodos que se pueden pedir en el preflight
+	AllowedMethods []string
+	// AllowedHeaders son las cabeceras que puede enviar el navegador
+	AllowedHeaders []string
+	// ExposedHeaders son las cabeceras de la respuesta que puede leer el navegador
+	ExposedHeaders []string
+	// MaxAge es cuánto puede guardar el navegador la respuesta del preflight
+	MaxAge time.Duration
+}
+
+// DefaultCORSPolicy admite cualquier origen sin credenciales, con todos los
+// métodos y cabeceras que usa la API
+var DefaultCORSPolicy = CORSPolicy{
+	AllowedOrigins: []string{"*"},
+	AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions},
+	AllowedHeaders: []string{
+		"Content-Type", "Accept", "Accept-Encoding", "Cache-Control", "X-Requested-With",
+		"Authorization", "X-API-Key", "X-Caller-ID", "X-Request-ID", "X-Debug-Token",
+		"If-Match", "If-None-Match", "X-Signature", "X-Signature-Timestamp",
+	},
+	ExposedHeaders: []string{
+		"ETag", "Location", "Retry-After", "X-Request-ID", "Deprecation", "Sunset", "Link",
+		"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset",
+	},
+	MaxAge: 10 * time.Minute,
+}
+
+// originPattern es un origen admitido ya analizado
+type originPattern struct {
+	// prefix y suffix rodean la parte del host que cubre el comodín; sin
+	// comodín, prefix es el origen completo
+	prefix, suffix string
+	wildcard       bool
+}
+
+// matches indica si origin encaja con el patrón. El comodín cubre uno o más
+// niveles del host, pero nunca el esquema, el puerto ni otro separador.
+func (p originPattern) matches(origin string) bool {
+	if !p.wildcard {
+		return origin == p.prefix
+	}
+	if len(origin) <= len(p.prefix)+len(p.suffix) || !strings.HasPrefix(origin, p.prefix) || !strings.HasSuffix(origin, p.suffix) {
+		return false
+	}
+	labels := origin[len(p.prefix) : len(origin)-len(p.suffix)]
+	for _, r := range labels {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// parseOriginPattern valida un origen admitido de la política
+func parseOriginPattern(origin string) (originPattern, error) {
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		u.User != nil || u.Path != "" || u.RawQuery != "" || u.Fragment != "" {
+		return originPattern{}, fmt.Errorf("origin %q must be scheme://host[:port]", origin)
+	}
+	if origin != strings.ToLower(origin) {
+		return originPattern{}, fmt.Errorf("origin %q must be lowercase", origin)
+	}
+	if !strings.Contains(origin, "*") {
+		return originPattern{prefix: origin}, nil
+	}
+	prefix := u.Scheme + "://*."
+	if !strings.HasPrefix(origin, prefix) || strings.Count(origin, "*") != 1 || strings.HasPrefix(origin, prefix+".") {
+		return originPattern{}, fmt.Errorf("origin %q may only use a wildcard as the first host label", origin)
+	}
+	return originPattern{prefix: u.Scheme + "://", suffix: origin[len(prefix)-1:], wildcard: true}, nil
+}
+
+// OriginMatcher decide qué orígenes admite una lista de orígenes de CORSPolicy
+type OriginMatcher struct {
+	anyOrigin bool
+	patterns  []originPattern
+}
+
+// NewOriginMatcher valida los orígenes admitidos, con el formato de
+// CORSPolicy.AllowedOrigins
+func NewOriginMatcher(origins []string) (*OriginMatcher, error) {
+	m := &OriginMatcher{}
+	for _, origin := range origins {
+		if origin == "*" {
+			m.anyOrigin = true
+			continue
+		}
+		pattern, err := parseOriginPattern(origin)
+		if err != nil {
+			return nil, err
+		}
+		m.patterns = append(m.patterns, pattern)
+	}
+	return m, nil
+}
+
+// Allows indica si se admite el origen
+func (m *OriginMatcher) Allows(origin string) bool {
+	allowed, _ := m.match(origin)
+	return allowed
+}
+
+// match indica si se admite el origen y si puede enviar credenciales. Los
+// orígenes exactos tienen preferencia: son los únicos con credenciales.
+func (m *OriginMatcher) match(origin string) (allowed, credentials bool) {
+	for _, pattern := range m.patterns {
+		if pattern.matches(origin) {
+			allowed = true
+			if !pattern.wildcard {
+				return true, true
+			}
+		}
+	}
+	return allowed || m.anyOrigin, false
+}
+
+// CORS aplica la política a las peticiones de navegador. Responde a los
+// preflight de los orígenes admitidos y rechaza con 403 los de los demás; al
+// resto de peticiones solo les añade las cabeceras si el origen es admitido.
+func CORS(policy CORSPolicy) (gin.HandlerFunc, error) {
+	origins, err := NewOriginMatcher(policy.AllowedOrigins)
+	if err != nil {
+		return nil, err
+	}
+	if policy.MaxAge < 0 {
+		return nil, fmt.Errorf("max age must not be negative")
+	}
+
+	methods := strings.Join(policy.AllowedMethods, ", ")
+	headers := strings.Join(policy.AllowedHeaders, ", ")
+	exposed := strings.Join(policy.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(policy.MaxAge / time.Second))
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		// La respuesta depende del origen aunque no se admita, para que las
+		// cachés no sirvan a un origen la de otro
+		c.Writer.Header().Add("Vary", "Origin")
+
+		allowed, credentials := origins.match(origin)
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if !allowed {
+			if preflight {
+				abortProblem(c, CodeForbidden, "Origin is not allowed")
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if credentials {
+			c.Header("Access-Control-Allow-Origin", origin)
+			c.Header("Access-Control-Allow-Credentials", "true")
+		} else if origins.anyOrigin && len(origins.patterns) == 0 {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+
+		if preflight {
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+			c.Header("Access-Control-Allow-Methods", methods)
+			c.Header("Access-Control-Allow-Headers", headers)
+			c.Header("Access-Control-Max-Age", maxAge)
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		if exposed != "" {
+			c.Header("Access-Control-Expose-Headers", exposed)
+		}
+		c.Next()
+	}, nil
+}
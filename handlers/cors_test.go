@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNewOriginMatcher(t *testing.T) {
+	tests := []struct {
+		name    string
+		origins []string
+		wantErr bool
+	}{
+		{"exact", []string{"https://app.example.com"}, false},
+		{"with port", []string{"http://localhost:3000"}, false},
+		{"wildcard", []string{"https://*.example.com"}, false},
+		{"any", []string{"*"}, false},
+		{"no scheme", []string{"app.example.com"}, true},
+		{"other scheme", []string{"ftp://app.example.com"}, true},
+		{"path", []string{"https://app.example.com/"}, true},
+		{"query", []string{"https://app.example.com?x=1"}, true},
+		{"user info", []string{"https://user@app.example.com"}, true},
+		{"uppercase", []string{"https://App.example.com"}, true},
+		{"wildcard not first label", []string{"https://app.*.com"}, true},
+		{"two wildcards", []string{"https://*.*.example.com"}, true},
+		{"partial label wildcard", []string{"https://app*.example.com"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewOriginMatcher(tt.origins); (err != nil) != tt.wantErr {
+				t.Fatalf("NewOriginMatcher(%q) = %v, want error %v", tt.origins, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOriginMatcherMatch(t *testing.T) {
+	tests := []struct {
+		name            string
+		origins         []string
+		origin          string
+		wantAllowed     bool
+		wantCredentials bool
+	}{
+		{"exact", []string{"https://app.example.com"}, "https://app.example.com", true, true},
+		{"exact other scheme", []string{"https://app.example.com"}, "http://app.example.com", false, false},
+		{"exact other port", []string{"https://app.example.com"}, "https://app.example.com:8443", false, false},
+		{"wildcard one label", []string{"https://*.example.com"}, "https://app.example.com", true, false},
+		{"wildcard several labels", []string{"https://*.example.com"}, "https://eu.app.example.com", true, false},
+		{"wildcard needs a label", []string{"https://*.example.com"}, "https://example.com", false, false},
+		{"wildcard not a suffix trick", []string{"https://*.example.com"}, "https://evil-example.com", false, false},
+		{"wildcard other domain", []string{"https://*.example.com"}, "https://example.com.evil.com", false, false},
+		{"wildcard no port", []string{"https://*.example.com"}, "https://app.example.com:8443", false, false},
+		{"wildcard no path separator", []string{"https://*.example.com"}, "https://evil.com/.example.com", false, false},
+		{"wildcard no user info", []string{"https://*.example.com"}, "https://evil.com@app.example.com", false, false},
+		{"any", []string{"*"}, "https://evil.com", true, false},
+		{"exact wins over wildcard", []string{"https://*.example.com", "https://app.example.com"}, "https://app.example.com", true, true},
+		{"exact wins over any", []string{"*", "https://app.example.com"}, "https://app.example.com", true, true},
+		{"none", nil, "https://app.example.com", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewOriginMatcher(tt.origins)
+			if err != nil {
+				t.Fatalf("NewOriginMatcher() = %v", err)
+			}
+			allowed, credentials := m.match(tt.origin)
+			if allowed != tt.wantAllowed || credentials != tt.wantCredentials {
+				t.Fatalf("match(%q) = %v, %v, want %v, %v", tt.origin, allowed, credentials, tt.wantAllowed, tt.wantCredentials)
+			}
+			if m.Allows(tt.origin) != tt.wantAllowed {
+				t.Fatalf("Allows(%q) = %v, want %v", tt.origin, !tt.wantAllowed, tt.wantAllowed)
+			}
+		})
+	}
+}
+
+func TestCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name            string
+		origins         []string
+		method          string
+		origin          string
+		preflight       bool
+		want            int
+		wantAllowOrigin string
+		wantCredentials string
+	}{
+		{"no origin", []string{"https://app.example.com"}, http.MethodGet, "", false, http.StatusOK, "", ""},
+		{"exact origin", []string{"https://app.example.com"}, http.MethodGet, "https://app.example.com", false, http.StatusOK, "https://app.example.com", "true"},
+		{"wildcard origin", []string{"https://*.example.com"}, http.MethodGet, "https://app.example.com", false, http.StatusOK, "https://app.example.com", ""},
+		{"any origin", []string{"*"}, http.MethodGet, "https://app.example.com", false, http.StatusOK, "*", ""},
+		// Sin cabeceras CORS el navegador no deja leer la respuesta
+		{"other origin", []string{"https://app.example.com"}, http.MethodGet, "https://evil.com", false, http.StatusOK, "", ""},
+		{"preflight", []string{"https://app.example.com"}, http.MethodOptions, "https://app.example.com", true, http.StatusNoContent, "https://app.example.com", "true"},
+		{"preflight other origin", []string{"https://app.example.com"}, http.MethodOptions, "https://evil.com", true, http.StatusForbidden, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := DefaultCORSPolicy
+			policy.AllowedOrigins = tt.origins
+			cors, err := CORS(policy)
+			if err != nil {
+				t.Fatalf("CORS() = %v", err)
+			}
+			router := gin.New()
+			router.Use(cors)
+			router.GET("/topsecret_split", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "/topsecret_split", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+			}
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantAllowOrigin {
+				t.Fatalf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantAllowOrigin)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials"); got != tt.wantCredentials {
+				t.Fatalf("Access-Control-Allow-Credentials = %q, want %q", got, tt.wantCredentials)
+			}
+			if tt.origin != "" && w.Header().Get("Vary") == "" {
+				t.Fatal("response does not vary on Origin")
+			}
+			if tt.preflight && tt.want == http.StatusNoContent && w.Header().Get("Access-Control-Max-Age") != "600" {
+				t.Fatalf("Access-Control-Max-Age = %q, want 600", w.Header().Get("Access-Control-Max-Age"))
+			}
+		})
+	}
+}
+
+func TestCORSRejectsInvalidPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy CORSPolicy
+	}{
+		{"invalid origin", CORSPolicy{AllowedOrigins: []string{"example.com"}}},
+		{"negative max age", CORSPolicy{AllowedOrigins: []string{"*"}, MaxAge: -time.Second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CORS(tt.policy); err == nil {
+				t.Fatal("CORS() accepted an invalid policy")
+			}
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fuegodequasar/internal/platform/audit"
+	"fuegodequasar/internal/platform/auth"
+	"fuegodequasar/internal/platform/history"
+	"fuegodequasar/internal/platform/repository"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/net/websocket"
+)
+
+// Parámetros del canal de ingesta de las estaciones
+const (
+	// stationAuthTimeout es el plazo para enviar el frame de autenticación
+	stationAuthTimeout = 10 * time.Second
+	// stationIdleTimeout cierra la conexión si la estación no envía nada en ese tiempo
+	stationIdleTimeout = 5 * time.Minute
+	// stationWriteTimeout es el plazo de cada frame de respuesta
+	stationWriteTimeout = 10 * time.Second
+	// stationMaxFrameBytes limita el tamaño de cada frame recibido
+	stationMaxFrameBytes = 64 << 10
+)
+
+// Tipos de frame del canal de ingesta
+const (
+	stationFrameAuth    = "auth"
+	stationFrameAuthOK  = "auth_ok"
+	stationFrameReading = "reading"
+	stationFrameAck     = "ack"
+	stationFrameNack    = "nack"
+	stationFrameResult  = "result"
+	stationFrameError   = "error"
+)
+
+// AnySatellite en Station.Satellites permite enviar lecturas de cualquier satélite
+const AnySatellite = "*"
+
+// Station es una estación y los satélites de los que puede enviar lecturas
+type Station struct {
+	Name string
+	// Satellites son los satélites que recibe la estación; AnySatellite
+	// confía en ella para todos
+	Satellites []string
+}
+
+// allows indica si la estación puede enviar lecturas del satélite
+func (s Station) allows(satellite string) bool {
+	return slices.Contains(s.Satellites, satellite) || slices.Contains(s.Satellites, AnySatellite)
+}
+
+// StationTokens asocia cada token de estación con su estación
+type StationTokens map[string]Station
+
+// lookup devuelve la estación de un token comparando en tiempo constante
+func (t StationTokens) lookup(token string) (Station, bool) {
+	var station Station
+	for candidate, s := range t {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+			station = s
+		}
+	}
+	return station, station.Name != ""
+}
+
+// StationFrame es un frame que envía una estación. El primero debe ser de tipo
+// "auth" con el token; los siguientes, de tipo "reading" con una lectura.
+// @Description Frame enviado por una estación
+type StationFrame struct {
+	Type  string `json:"type" example:"reading"`
+	Token string `json:"token,omitempty"`
+	// ID es un identificador elegido por la estación que se repite en las respuestas
+	ID        string `json:"id,omitempty" example:"42"`
+	Satellite string `json:"satellite,omitempty" example:"kenobi"`
+	// IfMatch tiene el mismo efecto que la cabecera If-Match en la API REST
+	IfMatch string `json:"if_match,omitempty" example:"\"3\""`
+	TopSecretSplitRequest
+}
+
+// StationReply es un frame que envía el servidor: "auth_ok" tras autenticar,
+// "ack" o "nack" por cada lectura, "result" con el cálculo tras cada lectura
+// aceptada y "error" antes de cerrar la conexión. Los "nack", los "error" y
+// los "result" sin cálculo llevan el motivo en Problem.
+// @Description Frame enviado a una estación
+type StationReply struct {
+	Type      string             `json:"type" example:"ack"`
+	ID        string             `json:"id,omitempty" example:"42"`
+	Station   string             `json:"station,omitempty" example:"station-1"`
+	Satellite string             `json:"satellite,omitempty" example:"kenobi"`
+	ETag      string             `json:"etag,omitempty" example:"\"4\""`
+	Result    *TopSecretResponse `json:"result,omitempty"`
+	// Problem es el error, con el mismo formato que en la API REST
+	Problem *Problem `json:"problem,omitempty"`
+}
+
+// SetupStationRoutes registra el canal WebSocket por el que las estaciones
+// envían lecturas de los satélites que tiene asignados su token. Sin tokens configurados el canal no se registra, y tampoco
+// si las lecturas deben ir firmadas (signatures no es nil): los frames no
+// llevan la firma de su satélite. Las conexiones desde un navegador solo se
+// aceptan de los orígenes que admite origins, como en CORS. Las conexiones se
+// cierran al cancelarse ctx.
+func SetupStationRoutes(ctx context.Context, router gin.IRouter, repo repository.RepositoryService, readings *history.Store, tokens StationTokens, signatures *auth.SignatureVerifier, origins *OriginMatcher) {
+	if len(tokens) == 0 || signatures != nil {
+		return
+	}
+	// GET /ws/topsecret_split
+	router.GET("/ws/topsecret_split", handleStationIngest(ctx, repo, readings, tokens, origins))
+}
+
+// @Summary Canal WebSocket de ingesta de lecturas
+// @Description Abre una conexión WebSocket de larga duración. La estación se autentica una vez con {"type":"auth","token":"..."} y después envía frames {"type":"reading","id":"42","satellite":"kenobi","distance":100,"message":["este","","un","",""]}.
+// @Description Cada token solo puede enviar lecturas de los satélites de su estación; las demás se rechazan con un "nack" forbidden.
+// @Description Cada lectura se valida y se guarda igual que en POST /topsecret_split/{satellite_name}; el servidor responde con un frame "ack" o "nack" y, si se aceptó, con un frame "result" con la posición y el mensaje o el motivo por el que aún no se pueden calcular.
+// @Description El canal no existe si el servidor exige lecturas firmadas por su satélite. Las conexiones con cabecera Origin solo se aceptan de los orígenes que admite la política CORS.
+// @Tags topsecret_split
+// @Success 101 {object} StationReply
+// @Failure 403 {object} Problem
+// @Router /v1/ws/topsecret_split [get]
+func handleStationIngest(streams context.Context, repo repository.RepositoryService, readings *history.Store, tokens StationTokens, origins *OriginMatcher) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Las estaciones no son navegadores y no envían Origin; si lo envía un
+		// navegador, debe ser un origen admitido
+		if origin := c.GetHeader("Origin"); origin != "" && (origins == nil || !origins.Allows(origin)) {
+			abortProblem(c, CodeForbidden, "Origin is not allowed")
+			return
+		}
+		server := websocket.Server{
+			// El origen ya se comprobó; la identidad se comprueba con el token
+			Handshake: func(*websocket.Config, *http.Request) error { return nil },
+			Handler: func(ws *websocket.Conn) {
+				ws.MaxPayloadBytes = stationMaxFrameBytes
+				ingest := &stationConn{
+					ws:        ws,
+					repo:      repo,
+					readings:  readings,
+					instance:  c.Request.URL.Path,
+					requestID: c.GetString(requestIDKey),
+				}
+				// La conexión ya no pertenece al servidor HTTP, que no la
+				// cierra al apagarse: la cierra la cancelación de streams
+				ctx, cancel := context.WithCancel(c.Request.Context())
+				defer cancel()
+				stop := context.AfterFunc(streams, cancel)
+				defer stop()
+				ingest.serve(ctx, c.ClientIP(), tokens)
+			},
+		}
+		server.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// stationConn atiende la conexión de una estación
+type stationConn struct {
+	ws       *websocket.Conn
+	repo     repository.RepositoryService
+	readings *history.Store
+	// station es la estación autenticada
+	station Station
+	// instance y requestID identifican la conexión en los problemas
+	instance  string
+	requestID string
+}
+
+func (s *stationConn) serve(ctx context.Context, clientIP string, tokens StationTokens) {
+	// Cerrar la conexión al cancelarse el contexto, p. ej. al apagar el servidor
+	stop := context.AfterFunc(ctx, func() { s.ws.Close() })
+	defer stop()
+	defer s.ws.Close()
+
+	station, ok := s.authenticate(tokens)
+	if !ok {
+		return
+	}
+	s.station = station
+	ctx = audit.WithActor(ctx, audit.Actor{ID: station.Name, ClientIP: clientIP, RequestID: s.requestID})
+	if !s.reply(StationReply{Type: stationFrameAuthOK, Station: station.Name}) {
+		return
+	}
+
+	for {
+		var frame StationFrame
+		err := s.receive(&frame, stationIdleTimeout)
+		if isInvalidFrame(err) {
+			// El frame se leyó entero: se rechaza y la conexión sigue
+			if !s.fail(stationFrameNack, "", newProblem(CodeInvalidRequest, "Invalid request format")) {
+				return
+			}
+			continue
+		}
+		if err != nil {
+			s.closeOnError(err)
+			return
+		}
+		if frame.Type != stationFrameReading {
+			s.fail(stationFrameError, frame.ID, newProblem(CodeInvalidRequest, "Unexpected frame type"))
+			return
+		}
+		if !s.handleReading(ctx, frame) {
+			return
+		}
+	}
+}
+
+// authenticate espera el frame de autenticación y devuelve la estación
+func (s *stationConn) authenticate(tokens StationTokens) (Station, bool) {
+	var frame StationFrame
+	if err := s.receive(&frame, stationAuthTimeout); err != nil {
+		s.closeOnError(err)
+		return Station{}, false
+	}
+	if frame.Type != stationFrameAuth {
+		s.fail(stationFrameError, "", newProblem(CodeUnauthorized, "Authentication required"))
+		return Station{}, false
+	}
+	station, ok := tokens.lookup(frame.Token)
+	if !ok {
+		s.fail(stationFrameError, "", newProblem(CodeUnauthorized, "Invalid station token"))
+		return Station{}, false
+	}
+	return station, true
+}
+
+// handleReading guarda una lectura por el mismo camino que la API REST y
+// responde con el resultado. Devuelve false si la conexión ya no sirve.
+func (s *stationConn) handleReading(ctx context.Context, frame StationFrame) bool {
+	// Las mismas reglas que en POST /topsecret_split/{satellite_name}
+	var invalid []FieldError
+	if frame.Satellite == "" {
+		invalid = append(invalid, FieldError{Field: "satellite", In: "body", Message: "is required"})
+	}
+	var validationErrs validator.ValidationErrors
+	if errors.As(validate(frame.TopSecretSplitRequest), &validationErrs) {
+		invalid = append(invalid, fieldErrors(validationErrs)...)
+	}
+	if len(invalid) > 0 {
+		problem := newProblem(CodeValidationFailed, "Frame failed validation")
+		problem.Errors = invalid
+		return s.fail(stationFrameNack, frame.ID, problem)
+	}
+	if !s.station.allows(frame.Satellite) {
+		return s.fail(stationFrameNack, frame.ID, newProblem(CodeForbidden, "Station is not allowed to submit readings for this satellite"))
+	}
+
+	version, err := saveReading(ctx, s.repo, s.readings, frame.Satellite, false, frame.IfMatch, frame.Distance, frame.Message)
+	if err != nil {
+		return s.fail(stationFrameNack, frame.ID, saveReadingProblem(err))
+	}
+	if !s.reply(StationReply{Type: stationFrameAck, ID: frame.ID, Satellite: frame.Satellite, ETag: satelliteETag(version)}) {
+		return false
+	}
+
+	satellites, err := s.repo.GetAllSatellites(ctx)
+	if err != nil {
+		return s.fail(stationFrameResult, frame.ID, newProblem(CodeInternal, "Failed to retrieve satellites"))
+	}
+	response, err := locate(satellites)
+	if err != nil {
+		return s.fail(stationFrameResult, frame.ID, locateProblem(err, response.Skipped))
+	}
+	return s.reply(StationReply{Type: stationFrameResult, ID: frame.ID, Result: &response})
+}
+
+// receive lee un frame JSON con el plazo indicado
+func (s *stationConn) receive(frame *StationFrame, timeout time.Duration) error {
+	if err := s.ws.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+	return websocket.JSON.Receive(s.ws, frame)
+}
+
+// reply envía un frame. Devuelve false si no se pudo escribir.
+func (s *stationConn) reply(frame StationReply) bool {
+	if err := s.ws.SetWriteDeadline(time.Now().Add(stationWriteTimeout)); err != nil {
+		return false
+	}
+	if err := websocket.JSON.Send(s.ws, frame); err != nil {
+		log.Printf("station: failed to send %s frame: %v", frame.Type, err)
+		return false
+	}
+	return true
+}
+
+// fail envía un frame con el problema indicado. Devuelve false si no se pudo escribir.
+func (s *stationConn) fail(frameType, id string, problem Problem) bool {
+	problem.Instance = s.instance
+	problem.RequestID = s.requestID
+	return s.reply(StationReply{Type: frameType, ID: id, Problem: &problem})
+}
+
+// closeOnError informa a la estación de por qué se cierra la conexión; si se
+// cerró o expiró no queda nadie a quien avisar
+func (s *stationConn) closeOnError(err error) {
+	switch {
+	case isInvalidFrame(err):
+		s.fail(stationFrameError, "", newProblem(CodeInvalidRequest, "Invalid request format"))
+	case errors.Is(err, websocket.ErrFrameTooLarge):
+		s.fail(stationFrameError, "", newProblem(CodePayloadTooLarge, "Frame too large"))
+	}
+}
+
+// isInvalidFrame indica si err se debe a un frame que no es JSON válido
+func isInvalidFrame(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &syntaxErr) || errors.As(err, &typeErr)
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"fuegodequasar/internal/platform/auth"
+	"fuegodequasar/internal/platform/history"
+	"fuegodequasar/internal/platform/repository"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+func TestSetupStationRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	origins, err := NewOriginMatcher([]string{"https://app.example.com"})
+	if err != nil {
+		t.Fatalf("NewOriginMatcher: %v", err)
+	}
+	signatures, err := auth.ParseSatelliteCredentials([]byte(`[]`), time.Minute)
+	if err != nil {
+		t.Fatalf("ParseSatelliteCredentials: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		tokens     StationTokens
+		signatures *auth.SignatureVerifier
+		origin     string
+		want       int
+	}{
+		// Sin cabeceras de upgrade la petición no llega a ser WebSocket
+		{"no origin", StationTokens{"t": {Name: "kenobi", Satellites: []string{"kenobi"}}}, nil, "", http.StatusBadRequest},
+		{"allowed origin", StationTokens{"t": {Name: "kenobi", Satellites: []string{"kenobi"}}}, nil, "https://app.example.com", http.StatusBadRequest},
+		{"other origin", StationTokens{"t": {Name: "kenobi", Satellites: []string{"kenobi"}}}, nil, "https://evil.example.com", http.StatusForbidden},
+		{"no tokens", nil, nil, "", http.StatusNotFound},
+		{"signatures required", StationTokens{"t": {Name: "kenobi", Satellites: []string{"kenobi"}}}, signatures, "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			SetupStationRoutes(context.Background(), router, repository.New(), history.NewStore(history.Retention{}), tt.tokens, tt.signatures, origins)
+
+			// El canal toma la conexión, así que hace falta un servidor real
+			server := httptest.NewServer(router)
+			defer server.Close()
+			req, err := http.NewRequest(http.MethodGet, server.URL+"/ws/topsecret_split", nil)
+			if err != nil {
+				t.Fatalf("NewRequest: %v", err)
+			}
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("GET: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+// dialStation abre el canal de ingesta sobre repo con los tokens indicados
+func dialStation(t *testing.T, repo repository.RepositoryService, tokens StationTokens) *websocket.Conn {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	// El cliente de x/net/websocket siempre envía Origin
+	origins, err := NewOriginMatcher([]string{"https://station.example.com"})
+	if err != nil {
+		t.Fatalf("NewOriginMatcher: %v", err)
+	}
+	router := gin.New()
+	SetupStationRoutes(context.Background(), router, repo, history.NewStore(history.Retention{}), tokens, nil, origins)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws/topsecret_split", "", "https://station.example.com")
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { ws.Close() })
+	return ws
+}
+
+// exchange envía un frame y devuelve la respuesta de la estación
+func exchange(t *testing.T, ws *websocket.Conn, frame StationFrame) StationReply {
+	t.Helper()
+	if err := websocket.JSON.Send(ws, frame); err != nil {
+		t.Fatalf("send %s frame: %v", frame.Type, err)
+	}
+	return receiveReply(t, ws)
+}
+
+// receiveReply lee el siguiente frame del servidor
+func receiveReply(t *testing.T, ws *websocket.Conn) StationReply {
+	t.Helper()
+	if err := ws.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatalf("SetReadDeadline: %v", err)
+	}
+	var reply StationReply
+	if err := websocket.JSON.Receive(ws, &reply); err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	return reply
+}
+
+func TestStationAuthentication(t *testing.T) {
+	tokens := StationTokens{"secret": {Name: "station-1", Satellites: []string{"kenobi"}}}
+
+	tests := []struct {
+		name        string
+		frame       StationFrame
+		wantType    string
+		wantStation string
+		wantCode    ProblemCode
+	}{
+		{"valid token", StationFrame{Type: stationFrameAuth, Token: "secret"}, stationFrameAuthOK, "station-1", ""},
+		{"invalid token", StationFrame{Type: stationFrameAuth, Token: "guess"}, stationFrameError, "", CodeUnauthorized},
+		{"empty token", StationFrame{Type: stationFrameAuth}, stationFrameError, "", CodeUnauthorized},
+		{"reading before auth", StationFrame{Type: stationFrameReading, Token: "secret"}, stationFrameError, "", CodeUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := dialStation(t, repository.New(), tokens)
+			reply := exchange(t, ws, tt.frame)
+			if reply.Type != tt.wantType || reply.Station != tt.wantStation {
+				t.Fatalf("reply = %+v, want %q from %q", reply, tt.wantType, tt.wantStation)
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			if reply.Problem == nil || reply.Problem.Code != tt.wantCode {
+				t.Fatalf("problem = %+v, want %q", reply.Problem, tt.wantCode)
+			}
+			// Tras un error de autenticación el servidor cierra la conexión
+			var next StationReply
+			if err := websocket.JSON.Receive(ws, &next); err == nil {
+				t.Fatalf("received %+v after the error, want the connection closed", next)
+			}
+		})
+	}
+}
+
+func TestStationReadings(t *testing.T) {
+	repo := repository.New()
+	item := transmission("", -100, 75.5)
+	ws := dialStation(t, repo, StationTokens{"kenobi-token": {Name: "kenobi", Satellites: []string{"kenobi"}}})
+	if reply := exchange(t, ws, StationFrame{Type: stationFrameAuth, Token: "kenobi-token"}); reply.Type != stationFrameAuthOK {
+		t.Fatalf("auth reply = %+v", reply)
+	}
+	reading := func(id string, sat SatelliteInfo) StationFrame {
+		return StationFrame{Type: stationFrameReading, ID: id, Satellite: sat.Name, TopSecretSplitRequest: TopSecretSplitRequest{Distance: sat.Distance, Message: sat.Message}}
+	}
+
+	// Una lectura aceptada recibe un ack con su versión y después el resultado
+	ack := exchange(t, ws, reading("1", item.Satellites[0]))
+	if ack.Type != stationFrameAck || ack.ID != "1" || ack.Satellite != "kenobi" || ack.ETag != `"2"` {
+		t.Fatalf("ack = %+v, want kenobi at version 2", ack)
+	}
+	result := receiveReply(t, ws)
+	if result.Type != stationFrameResult || result.ID != "1" || result.Result != nil || result.Problem == nil || result.Problem.Code != CodeNotEnoughSatellites {
+		t.Fatalf("result = %+v, want %q", result, CodeNotEnoughSatellites)
+	}
+
+	tests := []struct {
+		name     string
+		frame    StationFrame
+		wantCode ProblemCode
+	}{
+		// El token de kenobi no puede enviar lecturas de otros satélites
+		{"other satellite", reading("2", item.Satellites[1]), CodeForbidden},
+		{"invalid reading", StationFrame{Type: stationFrameReading, ID: "3", Satellite: "kenobi"}, CodeValidationFailed},
+		{"stale if-match", func() StationFrame {
+			frame := reading("4", item.Satellites[0])
+			frame.IfMatch = `"1"`
+			return frame
+		}(), CodePreconditionFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// El nack no cierra la conexión ni va seguido de un resultado
+			nack := exchange(t, ws, tt.frame)
+			if nack.Type != stationFrameNack || nack.ID != tt.frame.ID || nack.Problem == nil || nack.Problem.Code != tt.wantCode {
+				t.Fatalf("reply = %+v, want a %q nack", nack, tt.wantCode)
+			}
+		})
+	}
+	if skywalker, _ := repo.GetSatellite(context.Background(), "skywalker"); skywalker.Version != 1 {
+		t.Fatalf("skywalker version = %d after a forbidden reading, want 1", skywalker.Version)
+	}
+
+	// Una estación de confianza envía las demás lecturas y recibe la posición
+	relay := dialStation(t, repo, StationTokens{"relay-token": {Name: "relay", Satellites: []string{AnySatellite}}})
+	exchange(t, relay, StationFrame{Type: stationFrameAuth, Token: "relay-token"})
+	for i, sat := range item.Satellites[1:] {
+		if ack := exchange(t, relay, reading(sat.Name, sat)); ack.Type != stationFrameAck {
+			t.Fatalf("ack = %+v", ack)
+		}
+		result = receiveReply(t, relay)
+		if i == 0 {
+			continue
+		}
+		if result.Result == nil || math.Abs(float64(result.Result.Position.X+100)) > 0.01 || result.Result.Message != "este es un mensaje" {
+			t.Fatalf("result = %+v, want (-100, 75.5)", result)
+		}
+	}
+}
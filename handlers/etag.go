@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+)
+
+// satelliteETag construye el ETag fuerte que identifica una versión de un satélite
+func satelliteETag(version uint64) string {
+	return `"` + strconv.FormatUint(version, 10) + `"`
+}
+
+// etagMatches indica si alguna de las etiquetas de una cabecera If-Match o
+// If-None-Match corresponde a la versión indicada. "*" coincide con cualquier
+// satélite existente.
+func etagMatches(header string, version uint64) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return version > 0
+		}
+		// Las etiquetas débiles se comparan igual que las fuertes: la versión es la misma
+		tag = strings.TrimPrefix(tag, "W/")
+		if tag == satelliteETag(version) {
+			return true
+		}
+	}
+	return false
+}
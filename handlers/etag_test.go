@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"fuegodequasar/internal/platform/repository"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+// conflictingRepository simula otra petición que siempre escribe primero:
+// toda escritura condicionada falla con ErrVersionConflict
+type conflictingRepository struct {
+	repository.RepositoryService
+	attempts atomic.Int32
+}
+
+func (r *conflictingRepository) SaveSatelliteIfVersion(context.Context, repository.Satellite, uint64) error {
+	r.attempts.Add(1)
+	return repository.ErrVersionConflict
+}
+
+func TestEtagMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		version uint64
+		want    bool
+	}{
+		{"same version", `"3"`, 3, true},
+		{"other version", `"2"`, 3, false},
+		{"weak tag", `W/"3"`, 3, true},
+		{"list", `"1", W/"2" ,"3"`, 3, true},
+		{"list without version", `"1", "2"`, 3, false},
+		{"any", `*`, 3, true},
+		// "*" solo coincide con un satélite que existe
+		{"any without satellite", `*`, 0, false},
+		{"unquoted", `3`, 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := etagMatches(tt.header, tt.version); got != tt.want {
+				t.Fatalf("etagMatches(%q, %d) = %v, want %v", tt.header, tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSatelliteETagOnGet(t *testing.T) {
+	// repository.New() deja a kenobi en la versión 1
+	router := newTestRouter(repository.New())
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        int
+	}{
+		{"no header", "", http.StatusOK},
+		{"current version", `"1"`, http.StatusNotModified},
+		{"weak tag", `W/"1"`, http.StatusNotModified},
+		{"any", `*`, http.StatusNotModified},
+		{"list with current version", `"7", "1"`, http.StatusNotModified},
+		{"other version", `"7"`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{}
+			if tt.ifNoneMatch != "" {
+				headers["If-None-Match"] = tt.ifNoneMatch
+			}
+			w := serve(router, http.MethodGet, "/topsecret_split/kenobi", "", headers)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if etag := w.Header().Get("ETag"); etag != `"1"` {
+				t.Fatalf("ETag = %q, want %q", etag, `"1"`)
+			}
+			if tt.want == http.StatusNotModified && w.Body.Len() != 0 {
+				t.Fatalf("304 response has a body: %s", w.Body)
+			}
+		})
+	}
+}
+
+func TestSatelliteETagOnPost(t *testing.T) {
+	reading := `{"distance":100,"message":["este","","un"]}`
+
+	tests := []struct {
+		name      string
+		satellite string
+		ifMatch   string
+		want      int
+		wantCode  ProblemCode
+		wantETag  string
+	}{
+		{"no header", "kenobi", "", http.StatusOK, "", `"2"`},
+		{"current version", "kenobi", `"1"`, http.StatusOK, "", `"2"`},
+		{"weak tag", "kenobi", `W/"1"`, http.StatusOK, "", `"2"`},
+		{"any", "kenobi", `*`, http.StatusOK, "", `"2"`},
+		{"stale version", "kenobi", `"0"`, http.StatusPreconditionFailed, CodePreconditionFailed, ""},
+		{"other version", "kenobi", `"5"`, http.StatusPreconditionFailed, CodePreconditionFailed, ""},
+		{"unknown satellite", "yoda", `*`, http.StatusNotFound, CodeSatelliteNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.New()
+			router := newTestRouter(repo)
+			headers := map[string]string{}
+			if tt.ifMatch != "" {
+				headers["If-Match"] = tt.ifMatch
+			}
+			w := serve(router, http.MethodPost, "/topsecret_split/"+tt.satellite, reading, headers)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if etag := w.Header().Get("ETag"); etag != tt.wantETag {
+				t.Fatalf("ETag = %q, want %q", etag, tt.wantETag)
+			}
+			if tt.wantCode != "" {
+				if problem := decodeProblem(t, w); problem.Code != tt.wantCode {
+					t.Fatalf("code = %q, want %q", problem.Code, tt.wantCode)
+				}
+				// Una precondición fallida no escribe nada
+				if kenobi, _ := repo.GetSatellite(context.Background(), "kenobi"); kenobi.Version != 1 {
+					t.Fatalf("kenobi version = %d, want 1", kenobi.Version)
+				}
+				return
+			}
+			// El ETag devuelto es el que sirve después GET
+			if get := serve(router, http.MethodGet, "/topsecret_split/kenobi", "", nil); get.Header().Get("ETag") != tt.wantETag {
+				t.Fatalf("GET ETag = %q, want %q", get.Header().Get("ETag"), tt.wantETag)
+			}
+		})
+	}
+}
+
+func TestSatelliteSaveConflict(t *testing.T) {
+	reading := `{"distance":100,"message":["este","","un"]}`
+
+	tests := []struct {
+		name         string
+		ifMatch      string
+		want         int
+		wantCode     ProblemCode
+		wantAttempts int32
+	}{
+		// Sin If-Match se reintenta hasta agotar maxSaveAttempts
+		{"retries exhausted", "", http.StatusConflict, CodeVersionConflict, maxSaveAttempts},
+		// Con If-Match la versión pedida ya no existe y no tiene sentido reintentar
+		{"with if-match", `"1"`, http.StatusPreconditionFailed, CodePreconditionFailed, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &conflictingRepository{RepositoryService: repository.New()}
+			headers := map[string]string{}
+			if tt.ifMatch != "" {
+				headers["If-Match"] = tt.ifMatch
+			}
+			w := serve(newTestRouter(repo), http.MethodPost, "/topsecret_split/kenobi", reading, headers)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if problem := decodeProblem(t, w); problem.Code != tt.wantCode {
+				t.Fatalf("code = %q, want %q", problem.Code, tt.wantCode)
+			}
+			if attempts := repo.attempts.Load(); attempts != tt.wantAttempts {
+				t.Fatalf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	quasarv1 "fuegodequasar/api/quasar/v1"
+	"fuegodequasar/internal/platform/audit"
+	"fuegodequasar/internal/platform/auth"
+	"fuegodequasar/internal/platform/history"
+	"fuegodequasar/internal/platform/repository"
+	"net/http"
+	"slices"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// grpcService implementa quasarv1.QuasarServiceServer con el mismo
+// repositorio y los mismos cálculos que las rutas HTTP
+type grpcService struct {
+	quasarv1.UnimplementedQuasarServiceServer
+
+	// ctx termina los streams abiertos al apagar el servidor
+	ctx      context.Context
+	repo     repository.RepositoryService
+	readings *history.Store
+}
+
+// grpcMethodRoles es el rol que exige cada método, igual que los grupos de
+// SetupRoutes; un método que no está en la lista solo lo puede usar admin.
+// Las lecturas por gRPC no llevan firma del satélite, así que si se exigen
+// firmas los métodos que las guardan pasan a ser solo de admin, salvo
+// SubmitReading por una conexión con el certificado de su satélite.
+func grpcMethodRoles(signatures *auth.SignatureVerifier) map[string]auth.Role {
+	return map[string]auth.Role{
+		quasarv1.QuasarService_Locate_FullMethodName:        unsignedReadingsRole(signatures),
+		quasarv1.QuasarService_SubmitReading_FullMethodName: unsignedReadingsRole(signatures),
+		quasarv1.QuasarService_WatchResult_FullMethodName:   auth.RoleAnalyst,
+	}
+}
+
+// NewGRPCServer crea el servidor gRPC de la API. Los streams de WatchResult
+// terminan al cancelarse ctx, de modo que GracefulStop no tenga que esperarlos.
+// Las credenciales se leen de los metadatos con el mismo authenticator que la
+// API HTTP; con authenticator nil no se exigen.
+func NewGRPCServer(ctx context.Context, repo repository.RepositoryService, readings *history.Store, authenticator auth.Authenticator, signatures *auth.SignatureVerifier, opts ...grpc.ServerOption) *grpc.Server {
+	authorizer := grpcAuthorizer{authenticator: authenticator, roles: grpcMethodRoles(signatures)}
+	server := grpc.NewServer(append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(grpcAuditActor, authorizer.unary),
+		grpc.StreamInterceptor(authorizer.stream),
+	}, opts...)...)
+	quasarv1.RegisterQuasarServiceServer(server, &grpcService{ctx: ctx, repo: repo, readings: readings})
+	return server
+}
+
+// grpcAuditActor es el equivalente de AuditActor y RequestID para gRPC: toma la
+// identidad del llamador y el ID de petición de los metadatos x-caller-id y
+// x-request-id
+func grpcAuditActor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	actor := audit.Actor{ID: "anonymous", RequestID: newRequestID()}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("x-caller-id"); len(values) > 0 && values[0] != "" {
+			actor.ID = values[0]
+		}
+		if values := md.Get("x-request-id"); len(values) > 0 && values[0] != "" && len(values[0]) <= 128 {
+			actor.RequestID = values[0]
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		actor.ClientIP = p.Addr.String()
+	}
+	return handler(audit.WithActor(ctx, actor), req)
+}
+
+// grpcAuthorizer autentica cada llamada y comprueba el rol que exige su método
+type grpcAuthorizer struct {
+	authenticator auth.Authenticator
+	roles         map[string]auth.Role
+}
+
+func (a grpcAuthorizer) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.authorize(ctx, info.FullMethod, req)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a grpcAuthorizer) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authorize(ss.Context(), info.FullMethod, nil)
+	if err != nil {
+		return err
+	}
+	return handler(srv, contextStream{ServerStream: ss, ctx: ctx})
+}
+
+// authorize devuelve el contexto con el principal y, si se autenticó, con él
+// como actor de la auditoría. En SubmitReading aplica además las reglas de
+// ClientCertificate: el certificado de cliente verificado, si lo hay, debe
+// identificar al satélite de la lectura y, sin otras credenciales, autentica
+// la llamada como estación de ese satélite.
+func (a grpcAuthorizer) authorize(ctx context.Context, method string, req any) (context.Context, error) {
+	var certified []string
+	if reading, ok := req.(*quasarv1.SubmitReadingRequest); ok {
+		certified = grpcVerifiedSatellites(ctx)
+		if len(certified) > 0 && !slices.Contains(certified, reading.GetSatelliteName()) {
+			return nil, status.Error(codes.PermissionDenied, "Client certificate does not identify this satellite")
+		}
+	}
+	if a.authenticator == nil {
+		return auth.WithPrincipal(ctx, anonymousPrincipal), nil
+	}
+
+	// Los metadatos llegan en minúsculas; http.Header los normaliza
+	header := make(http.Header)
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for key, values := range md {
+			for _, value := range values {
+				header.Add(key, value)
+			}
+		}
+	}
+	principal, err := a.authenticator.Authenticate(ctx, header)
+	switch {
+	case errors.Is(err, auth.ErrNoCredentials) && len(certified) > 0:
+		principal = auth.CertificatePrincipal(certified)
+	case errors.Is(err, auth.ErrNoCredentials):
+		return nil, status.Error(codes.Unauthenticated, "Authentication required")
+	case err != nil:
+		return nil, status.Error(codes.Unauthenticated, "Invalid credentials")
+	}
+
+	role, known := a.roles[method]
+	if !known {
+		role = auth.RoleAdmin
+	}
+	if len(certified) > 0 {
+		// El certificado del satélite sustituye a su firma, como en
+		// RequireSatelliteSignature
+		role = auth.RoleStation
+	}
+	if !principal.HasRole(role) {
+		return nil, status.Error(codes.PermissionDenied, "Insufficient role for this operation")
+	}
+
+	ctx = auth.WithPrincipal(ctx, principal)
+	actor := audit.ActorFrom(ctx)
+	actor.ID = principal.Subject
+	return audit.WithActor(ctx, actor), nil
+}
+
+// grpcVerifiedSatellites devuelve los satélites del certificado de cliente
+// verificado de la conexión, si la hay por TLS
+func grpcVerifiedSatellites(ctx context.Context) []string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil
+	}
+	return auth.VerifiedSatellites(&info.State)
+}
+
+// contextStream sustituye el contexto de un stream por el del interceptor
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *grpcService) Locate(ctx context.Context, req *quasarv1.LocateRequest) (*quasarv1.LocateResponse, error) {
+	request := TopSecretRequest{Satellites: make([]SatelliteInfo, len(req.GetSatellites()))}
+	for i, sat := range req.GetSatellites() {
+		request.Satellites[i] = SatelliteInfo{Name: sat.GetName(), Distance: sat.GetDistance(), Message: sat.GetMessage()}
+	}
+	if err := grpcValidate(request); err != nil {
+		return nil, err
+	}
+
+	// Igual que POST /topsecret: se guardan las lecturas y se calcula con todos los satélites
+	for _, sat := range req.GetSatellites() {
+		if _, err := saveReading(ctx, s.repo, s.readings, sat.GetName(), true, "", sat.GetDistance(), sat.GetMessage()); err != nil {
+			return nil, status.Error(codes.Internal, "Failed to save satellite info")
+		}
+	}
+
+	satellites, err := s.repo.GetAllSatellites(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "Failed to retrieve satellites")
+	}
+	response, err := locate(satellites)
+	if err != nil {
+		// Los satélites excluidos viajan como detalle del error
+		st, detailErr := status.New(codes.NotFound, locateErrorMessage(err)).
+			WithDetails(&quasarv1.LocateResponse{Skipped: skippedToProto(response.Skipped)})
+		if detailErr != nil {
+			return nil, status.Error(codes.NotFound, locateErrorMessage(err))
+		}
+		return nil, st.Err()
+	}
+	return locationToProto(response), nil
+}
+
+func (s *grpcService) SubmitReading(ctx context.Context, req *quasarv1.SubmitReadingRequest) (*quasarv1.SubmitReadingResponse, error) {
+	if err := grpcValidate(TopSecretSplitRequest{Distance: req.GetDistance(), Message: req.GetMessage()}); err != nil {
+		return nil, err
+	}
+	version, err := saveReading(ctx, s.repo, s.readings, req.GetSatelliteName(), false, req.GetIfMatch(), req.GetDistance(), req.GetMessage())
+	if err != nil {
+		problem := saveReadingProblem(err)
+		return nil, status.Error(grpcCode(problem.Status), problem.Detail)
+	}
+	return &quasarv1.SubmitReadingResponse{Etag: satelliteETag(version), Version: version}, nil
+}
+
+func (s *grpcService) WatchResult(_ *quasarv1.WatchResultRequest, stream grpc.ServerStreamingServer[quasarv1.WatchResultResponse]) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	stop := context.AfterFunc(s.ctx, cancel)
+	defer stop()
+
+	// Suscribirse antes de leer el estado para no perder cambios intermedios
+	sub, err := s.repo.Subscribe(ctx, streamBuffer)
+	if err != nil {
+		return status.Error(codes.Internal, "Failed to subscribe to changes")
+	}
+	defer func() { sub.Close() }()
+
+	var seq uint64
+	if err := s.sendState(ctx, stream, seq); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return s.watchEnded(ctx)
+		case change, ok := <-sub.C:
+			if !ok {
+				if !errors.Is(sub.Err(), repository.ErrSlowConsumer) {
+					return s.watchEnded(ctx)
+				}
+				// El repositorio nos dio de baja por lentos: volver a suscribirse y reenviar el estado
+				if sub, err = s.repo.Subscribe(ctx, streamBuffer); err != nil {
+					return s.watchEnded(ctx)
+				}
+			} else {
+				seq = drainChanges(sub, change.Seq)
+			}
+			if err := s.sendState(ctx, stream, seq); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// watchEnded devuelve el motivo por el que termina un stream de WatchResult
+func (s *grpcService) watchEnded(ctx context.Context) error {
+	if s.ctx.Err() != nil {
+		return status.Error(codes.Unavailable, "Server is shutting down")
+	}
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	return status.Error(codes.Internal, "Subscription to changes ended")
+}
+
+// sendState envía el estado actual del flujo dividido
+func (s *grpcService) sendState(ctx context.Context, stream grpc.ServerStreamingServer[quasarv1.WatchResultResponse], seq uint64) error {
+	satellites, err := s.repo.GetAllSatellites(ctx)
+	if err != nil {
+		return status.Error(codes.Internal, "Failed to retrieve satellites")
+	}
+
+	result := &quasarv1.WatchResultResponse{Seq: seq}
+	if response, waiting := splitState(satellites); response != nil {
+		result.Result = &quasarv1.WatchResultResponse_Location{Location: locationToProto(*response)}
+	} else {
+		result.Result = &quasarv1.WatchResultResponse_Waiting{Waiting: &quasarv1.Waiting{
+			Error:      waiting.Error,
+			WaitingFor: waiting.WaitingFor,
+			Skipped:    skippedToProto(waiting.Skipped),
+		}}
+	}
+	return stream.Send(result)
+}
+
+// grpcValidate aplica las reglas de validación de la API REST y devuelve
+// InvalidArgument con un BadRequest que lista todos los campos inválidos
+func grpcValidate(request any) error {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(validate(request), &validationErrs) {
+		return nil
+	}
+	badRequest := &errdetails.BadRequest{}
+	for _, field := range fieldErrors(validationErrs) {
+		badRequest.FieldViolations = append(badRequest.FieldViolations,
+			&errdetails.BadRequest_FieldViolation{Field: field.Field, Description: field.Message})
+	}
+	st, err := status.New(codes.InvalidArgument, "Request failed validation").WithDetails(badRequest)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "Request failed validation")
+	}
+	return st.Err()
+}
+
+// grpcCode traduce los códigos HTTP del catálogo de problemas a códigos gRPC
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusConflict:
+		return codes.Aborted
+	}
+	return codes.Internal
+}
+
+func locationToProto(response TopSecretResponse) *quasarv1.LocateResponse {
+	return &quasarv1.LocateResponse{
+		Position: &quasarv1.Position{X: response.Position.X, Y: response.Position.Y},
+		Message:  response.Message,
+		Skipped:  skippedToProto(response.Skipped),
+	}
+}
+
+func skippedToProto(skipped []SkippedSatellite) []*quasarv1.SkippedSatellite {
+	result := make([]*quasarv1.SkippedSatellite, len(skipped))
+	for i, sat := range skipped {
+		result[i] = &quasarv1.SkippedSatellite{Name: sat.Name, Reason: sat.Reason, Detail: sat.Detail}
+	}
+	return result
+}
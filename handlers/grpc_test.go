@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	quasarv1 "fuegodequasar/api/quasar/v1"
+	"fuegodequasar/internal/platform/auth"
+	"net"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// withClientCertificate simula una conexión mTLS con un certificado de
+// cliente verificado para satellite
+func withClientCertificate(ctx context.Context, satellite string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: satellite}}
+	state := tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert},
+		VerifiedChains:   [][]*x509.Certificate{{cert}},
+	}
+	return peer.NewContext(ctx, &peer.Peer{
+		Addr:     &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)},
+		AuthInfo: credentials.TLSInfo{State: state},
+	})
+}
+
+func TestGRPCAuthorizerClientCertificate(t *testing.T) {
+	keys, err := auth.ParseAPIKeys([]byte(`[{"id":"analyst","sha256":"` + auth.HashAPIKey("analyst-key") + `","roles":["analyst"]},` +
+		`{"id":"admin","sha256":"` + auth.HashAPIKey("admin-key") + `","roles":["admin"]}]`))
+	if err != nil {
+		t.Fatalf("ParseAPIKeys: %v", err)
+	}
+	signatures, err := auth.ParseSatelliteCredentials([]byte(`[]`), time.Minute)
+	if err != nil {
+		t.Fatalf("ParseSatelliteCredentials: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		authenticator auth.Authenticator
+		signatures    *auth.SignatureVerifier
+		certificate   string
+		apiKey        string
+		satellite     string
+		want          codes.Code
+		wantSubject   string
+	}{
+		{"certificate of the satellite", keys, nil, "kenobi", "", "kenobi", codes.OK, "kenobi"},
+		{"certificate replaces the signature", keys, signatures, "kenobi", "", "kenobi", codes.OK, "kenobi"},
+		{"certificate of another satellite", keys, nil, "skywalker", "", "kenobi", codes.PermissionDenied, ""},
+		{"another satellite with admin key", keys, nil, "skywalker", "admin-key", "kenobi", codes.PermissionDenied, ""},
+		{"another satellite without authentication", nil, nil, "skywalker", "", "kenobi", codes.PermissionDenied, ""},
+		{"certificate without authentication", nil, nil, "kenobi", "", "kenobi", codes.OK, "anonymous"},
+		{"certificate with analyst key", keys, nil, "kenobi", "analyst-key", "kenobi", codes.PermissionDenied, ""},
+		{"no certificate nor credentials", keys, nil, "", "", "kenobi", codes.Unauthenticated, ""},
+		{"unsigned reading without certificate", keys, signatures, "", "analyst-key", "kenobi", codes.PermissionDenied, ""},
+		{"admin without certificate", keys, signatures, "", "admin-key", "kenobi", codes.OK, "admin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorizer := grpcAuthorizer{authenticator: tt.authenticator, roles: grpcMethodRoles(tt.signatures)}
+			ctx := context.Background()
+			if tt.certificate != "" {
+				ctx = withClientCertificate(ctx, tt.certificate)
+			}
+			if tt.apiKey != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-api-key", tt.apiKey))
+			}
+
+			var subject string
+			handler := func(ctx context.Context, _ any) (any, error) {
+				principal, _ := auth.PrincipalFrom(ctx)
+				subject = principal.Subject
+				return nil, nil
+			}
+			info := &grpc.UnaryServerInfo{FullMethod: quasarv1.QuasarService_SubmitReading_FullMethodName}
+			_, err := authorizer.unary(ctx, &quasarv1.SubmitReadingRequest{SatelliteName: tt.satellite}, info, handler)
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("code = %v, want %v (%v)", got, tt.want, err)
+			}
+			if subject != tt.wantSubject {
+				t.Fatalf("principal = %q, want %q", subject, tt.wantSubject)
+			}
+		})
+	}
+}
+
+func TestGRPCAuthorizerLocateIgnoresCertificate(t *testing.T) {
+	// Locate trae lecturas de varios satélites: el certificado de uno no basta
+	keys, err := auth.ParseAPIKeys([]byte(`[{"id":"admin","sha256":"` + auth.HashAPIKey("admin-key") + `","roles":["admin"]}]`))
+	if err != nil {
+		t.Fatalf("ParseAPIKeys: %v", err)
+	}
+	authorizer := grpcAuthorizer{authenticator: keys, roles: grpcMethodRoles(nil)}
+	ctx := withClientCertificate(context.Background(), "kenobi")
+	info := &grpc.UnaryServerInfo{FullMethod: quasarv1.QuasarService_Locate_FullMethodName}
+	handler := func(context.Context, any) (any, error) { return nil, nil }
+	if _, err := authorizer.unary(ctx, &quasarv1.LocateRequest{}, info, handler); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("code = %v, want %v", status.Code(err), codes.Unauthenticated)
+	}
+}
+
+func TestGRPCValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		request    any
+		want       codes.Code
+		violations []string
+	}{
+		{"valid reading", TopSecretSplitRequest{Distance: 100, Message: []string{"este", "", "un"}}, codes.OK, nil},
+		{"negative distance", TopSecretSplitRequest{Distance: -1, Message: []string{"este"}}, codes.InvalidArgument, []string{"distance"}},
+		{"no message", TopSecretSplitRequest{Distance: 100}, codes.InvalidArgument, []string{"message"}},
+		{"repeated satellite", TopSecretRequest{Satellites: []SatelliteInfo{
+			{Name: "kenobi", Distance: 100, Message: []string{"este"}},
+			{Name: "kenobi", Distance: 100, Message: []string{"este"}},
+		}}, codes.InvalidArgument, []string{"satellites[1].name"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := status.Convert(grpcValidate(tt.request))
+			if st.Code() != tt.want {
+				t.Fatalf("code = %v, want %v (%v)", st.Code(), tt.want, st.Message())
+			}
+			var fields []string
+			for _, detail := range st.Details() {
+				if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+					for _, violation := range badRequest.GetFieldViolations() {
+						fields = append(fields, violation.GetField())
+					}
+				}
+			}
+			if !slices.Equal(fields, tt.violations) {
+				t.Fatalf("field violations = %v, want %v", fields, tt.violations)
+			}
+		})
+	}
+}
+
+func TestGRPCCode(t *testing.T) {
+	tests := []struct {
+		httpStatus int
+		want       codes.Code
+	}{
+		{http.StatusBadRequest, codes.InvalidArgument},
+		{http.StatusNotFound, codes.NotFound},
+		{http.StatusPreconditionFailed, codes.FailedPrecondition},
+		{http.StatusConflict, codes.Aborted},
+		{http.StatusInternalServerError, codes.Internal},
+	}
+	for _, tt := range tests {
+		if got := grpcCode(tt.httpStatus); got != tt.want {
+			t.Errorf("grpcCode(%d) = %v, want %v", tt.httpStatus, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"errors"
+	"fuegodequasar/internal/platform/history"
+	"fuegodequasar/internal/platform/repository"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Límites de paginación del historial de lecturas
+const (
+	defaultReadingsLimit = 100
+	maxReadingsLimit     = 1000
+)
+
+// SatelliteReadingsResponse representa una página del historial de un satélite
+// @Description Lecturas de un satélite en orden cronológico con estadísticas por ventana
+type SatelliteReadingsResponse struct {
+	Satellite string                `json:"satellite" example:"kenobi"`
+	Total     int                   `json:"total" example:"250"`
+	Offset    int                   `json:"offset" example:"0"`
+	Limit     int                   `json:"limit" example:"100"`
+	Readings  []history.Reading     `json:"readings"`
+	Stats     []history.WindowStats `json:"stats"`
+}
+
+// @Summary Consulta el historial de lecturas de un satélite
+// @Description Devuelve las lecturas recibidas en un intervalo, paginadas, y la media, desviación típica, mínimo y máximo de la distancia por ventana de tiempo
+// @Tags satellites
+// @Produce json
+// @Param satellite_name path string true "Nombre del satélite"
+// @Param from query string false "Inicio del intervalo (RFC 3339, incluido)"
+// @Param to query string false "Fin del intervalo (RFC 3339, excluido)"
+// @Param offset query int false "Lecturas a saltar" default(0)
+// @Param limit query int false "Lecturas por página (máximo 1000)" default(100)
+// @Param window query string false "Duración de cada ventana de estadísticas, por ejemplo 1h; vacío usa todo el intervalo"
+// @Success 200 {object} SatelliteReadingsResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/satellites/{satellite_name}/readings [get]
+func handleGetSatelliteReadings(repo repository.RepositoryService, readings *history.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("satellite_name")
+		_, err := repo.GetSatellite(c.Request.Context(), name)
+		if errors.Is(err, repository.ErrSatelliteNotFound) {
+			abortProblem(c, CodeSatelliteNotFound, "Satellite not found")
+			return
+		}
+		if err != nil {
+			abortProblem(c, CodeInternal, "Failed to retrieve satellite")
+			return
+		}
+
+		query := history.Query{Limit: defaultReadingsLimit}
+		if query.From, err = parseTimeQuery(c, "from"); err != nil {
+			paramProblem(c, "query", "from", "Invalid 'from' time, expected RFC 3339", "expected RFC 3339")
+			return
+		}
+		if query.To, err = parseTimeQuery(c, "to"); err != nil {
+			paramProblem(c, "query", "to", "Invalid 'to' time, expected RFC 3339", "expected RFC 3339")
+			return
+		}
+		if value := c.Query("offset"); value != "" {
+			if query.Offset, err = strconv.Atoi(value); err != nil || query.Offset < 0 {
+				paramProblem(c, "query", "offset", "Invalid 'offset', expected a non-negative integer", "expected a non-negative integer")
+				return
+			}
+		}
+		if value := c.Query("limit"); value != "" {
+			if query.Limit, err = strconv.Atoi(value); err != nil || query.Limit < 1 || query.Limit > maxReadingsLimit {
+				paramProblem(c, "query", "limit", "Invalid 'limit', expected an integer between 1 and 1000", "expected an integer between 1 and 1000")
+				return
+			}
+		}
+		var window time.Duration
+		if value := c.Query("window"); value != "" {
+			if window, err = time.ParseDuration(value); err != nil || window <= 0 {
+				paramProblem(c, "query", "window", "Invalid 'window', expected a positive duration such as 1h", "expected a positive duration such as 1h")
+				return
+			}
+		}
+
+		page, total := readings.Query(name, query)
+		c.JSON(http.StatusOK, SatelliteReadingsResponse{
+			Satellite: name,
+			Total:     total,
+			Offset:    query.Offset,
+			Limit:     query.Limit,
+			Readings:  page,
+			Stats:     readings.Stats(name, query.From, query.To, window),
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"fuegodequasar/internal/platform/auth"
+	"fuegodequasar/internal/platform/ratelimit"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QuotasResponse representa las cuentas diarias de los límites de peticiones
+// @Description Peticiones aceptadas hoy (UTC) por cada cliente en cada regla
+type QuotasResponse struct {
+	Quotas []ratelimit.Usage `json:"quotas"`
+}
+
+// RateLimit aplica a cada petición la regla de su ruta. Las rutas se comparan
+// sin el prefijo de versión, así que /api/v1, sus alias en la raíz y /api/v2
+// comparten regla y cubos. Con limiter nil no se limita nada.
+func RateLimit(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limiter == nil || c.FullPath() == "" {
+			c.Next()
+			return
+		}
+		rule, ok := limiter.Rule(c.Request.Method, unversionedPath(c.FullPath()))
+		if !ok {
+			c.Next()
+			return
+		}
+
+		decision := limiter.Allow(rule, rateLimitKey(c, rule.Key))
+		c.Header("RateLimit-Limit", strconv.Itoa(decision.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		c.Header("RateLimit-Reset", ceilSeconds(decision.Reset))
+		if !decision.Allowed {
+			c.Header("Retry-After", ceilSeconds(decision.RetryAfter))
+			if decision.QuotaExceeded {
+				abortProblem(c, CodeQuotaExceeded, "Daily quota exceeded")
+				return
+			}
+			abortProblem(c, CodeRateLimited, "Too many requests")
+			return
+		}
+		c.Next()
+	}
+}
+
+// unversionedPath quita de una ruta registrada el prefijo /api/vN
+func unversionedPath(path string) string {
+	rest, ok := strings.CutPrefix(path, "/api/v")
+	if !ok {
+		return path
+	}
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		return rest[i:]
+	}
+	return "/"
+}
+
+// rateLimitKey identifica al cliente según el tipo de clave de la regla. Si la
+// petición no tiene ese dato (sin autenticar o sin satélite en la ruta) se usa
+// la IP.
+func rateLimitKey(c *gin.Context, kind ratelimit.KeyKind) string {
+	switch kind {
+	case ratelimit.KeyAPIKey:
+		if principal, ok := auth.PrincipalFrom(c.Request.Context()); ok && principal.Method != anonymousPrincipal.Method {
+			return principal.Subject
+		}
+	case ratelimit.KeySatellite:
+		if satellite := c.Param("satellite_name"); satellite != "" {
+			return satellite
+		}
+	}
+	return c.ClientIP()
+}
+
+// ceilSeconds da una duración en segundos enteros, redondeando hacia arriba
+func ceilSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
+
+// @Summary Consulta las cuotas diarias
+// @Description Devuelve cuántas peticiones se han aceptado hoy (UTC) a cada cliente en cada regla de límite de peticiones y cuánto les queda de su cuota diaria
+// @Tags admin
+// @Produce json
+// @Success 200 {object} QuotasResponse
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/quotas [get]
+func handleGetQuotas(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		quotas := []ratelimit.Usage{}
+		if limiter != nil {
+			quotas = limiter.Usage()
+		}
+		c.JSON(http.StatusOK, QuotasResponse{Quotas: quotas})
+	}
+}
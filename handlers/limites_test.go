@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fuegodequasar/internal/platform/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rules, err := ratelimit.ParseRules([]byte(`[` +
+		`{"route":"POST /topsecret_split/:satellite_name","key":"satellite","rate":0.001,"burst":1},` +
+		`{"route":"GET /topsecret_split","key":"ip","rate":0.001,"burst":2,"daily_quota":1}]`))
+	if err != nil {
+		t.Fatalf("ParseRules: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		requests      []string
+		want          int
+		wantCode      ProblemCode
+		wantRemaining string
+	}{
+		{"within burst", []string{"POST /api/v1/topsecret_split/kenobi"}, http.StatusOK, "", "0"},
+		{"over burst", []string{"POST /api/v1/topsecret_split/kenobi", "POST /api/v1/topsecret_split/kenobi"}, http.StatusTooManyRequests, CodeRateLimited, "0"},
+		// Las versiones de la API comparten cubo
+		{"shared across versions", []string{"POST /api/v1/topsecret_split/kenobi", "POST /api/v2/topsecret_split/kenobi"}, http.StatusTooManyRequests, CodeRateLimited, "0"},
+		// Cada satélite tiene su cubo
+		{"other satellite", []string{"POST /api/v1/topsecret_split/kenobi", "POST /api/v1/topsecret_split/sato"}, http.StatusOK, "", "0"},
+		{"over daily quota", []string{"GET /api/v1/topsecret_split", "GET /api/v1/topsecret_split"}, http.StatusTooManyRequests, CodeQuotaExceeded, "1"},
+		{"route without rule", []string{"GET /health"}, http.StatusOK, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(RateLimit(ratelimit.NewLimiter(rules)))
+			ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+			for _, version := range []string{"/api/v1", "/api/v2"} {
+				router.POST(version+"/topsecret_split/:satellite_name", ok)
+				router.GET(version+"/topsecret_split", ok)
+			}
+			router.GET("/health", ok)
+
+			var w *httptest.ResponseRecorder
+			for _, request := range tt.requests {
+				method, path, _ := strings.Cut(request, " ")
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+			}
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if got := w.Header().Get("RateLimit-Remaining"); got != tt.wantRemaining {
+				t.Fatalf("RateLimit-Remaining = %q, want %q", got, tt.wantRemaining)
+			}
+			if tt.want != http.StatusTooManyRequests {
+				return
+			}
+			var problem Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil || problem.Code != tt.wantCode {
+				t.Fatalf("problem = %s, want code %q", w.Body, tt.wantCode)
+			}
+			if w.Header().Get("Retry-After") == "" || w.Header().Get("RateLimit-Reset") == "" {
+				t.Fatalf("headers = %v, want Retry-After and RateLimit-Reset", w.Header())
+			}
+		})
+	}
+}
+
+func TestRateLimitWithoutLimiter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RateLimit(nil))
+	router.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for range 3 {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+		if w.Code != http.StatusOK || w.Header().Get("RateLimit-Limit") != "" {
+			t.Fatalf("status = %d, headers = %v", w.Code, w.Header())
+		}
+	}
+}
+
+func TestUnversionedPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/v1/topsecret", "/topsecret"},
+		{"/api/v2/topsecret_split/:satellite_name", "/topsecret_split/:satellite_name"},
+		{"/api/v1", "/"},
+		{"/topsecret", "/topsecret"},
+		{"/health", "/health"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := unversionedPath(tt.path); got != tt.want {
+				t.Fatalf("unversionedPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"fuegodequasar/internal/platform/calculos"
+	"fuegodequasar/internal/platform/repository"
+)
+
+// degradedWeight es el peso relativo de un satélite degradado en la trilateración
+const degradedWeight = 0.5
+
+// Motivos por los que un satélite no participa en el cálculo
+const (
+	skipReasonNoReading      = "no reading"
+	skipReasonMaintenance    = "maintenance"
+	skipReasonDecommissioned = "decommissioned"
+)
+
+var (
+	errNotEnoughSatellites = errors.New("not enough satellite data")
+	errLocationUnavailable = errors.New("could not determine location")
+	errUndecodableMessage  = errors.New("could not decode message")
+)
+
+// SkippedSatellite indica un satélite que no participó en el cálculo y el motivo
+// @Description Satélite excluido del cálculo
+type SkippedSatellite struct {
+	Name   string `json:"name" example:"sato"`
+	Reason string `json:"reason" example:"maintenance"`
+	// Detail es el motivo registrado junto al estado operativo, si lo hay
+	Detail string `json:"detail,omitempty" example:"antenna recalibration"`
+}
+
+// LocateDiagnostics detalla cómo se calculó una localización
+// @Description Diagnóstico del cálculo, solo con ?debug=true y permiso de depuración
+type LocateDiagnostics struct {
+	// Solver es el método usado: trilateration (tres satélites activos) o
+	// weighted_least_squares
+	Solver string `json:"solver,omitempty" example:"trilateration"`
+	// Estimate es la posición calculada en doble precisión
+	Estimate PrecisePosition `json:"estimate"`
+	// Satellites son los satélites usados, en el orden en que entran al cálculo
+	Satellites []DiagnosticSatellite `json:"satellites"`
+	// Tolerance es el residuo máximo admitido por el método, si lo limita
+	Tolerance float64 `json:"tolerance,omitempty" example:"10"`
+	// Determinant es el determinante del sistema lineal; cerca de 0 indica
+	// satélites casi colineales
+	Determinant float64 `json:"determinant" example:"-480000"`
+	// Iterations son las iteraciones de refinamiento del método ponderado
+	Iterations int `json:"iterations,omitempty" example:"4"`
+	// SolverError es el fallo del método. La trilateración exacta devuelve
+	// (0, 0) cuando falla, así que puede aparecer junto a una posición.
+	SolverError string `json:"solver_error,omitempty"`
+	// Merge explica de qué satélite sale cada palabra del mensaje
+	Merge []MergedWord `json:"merge,omitempty"`
+}
+
+// DiagnosticSatellite es un satélite tal como entró al cálculo
+// @Description Satélite usado en el cálculo
+type DiagnosticSatellite struct {
+	Name     string   `json:"name" example:"kenobi"`
+	Position Position `json:"position"`
+	Distance float32  `json:"distance" example:"485.7"`
+	Weight   float64  `json:"weight" example:"1"`
+	// Residual es |posición - satélite| - distancia para la posición estimada
+	Residual *float64 `json:"residual,omitempty" example:"0.0003"`
+	// Padding son las palabras vacías añadidas al inicio de su mensaje
+	Padding int `json:"padding" example:"0"`
+}
+
+// MergedWord es una palabra del mensaje reconstruido y su origen
+// @Description Palabra del mensaje reconstruido
+type MergedWord struct {
+	Position int    `json:"position" example:"0"`
+	Word     string `json:"word" example:"este"`
+	// Source es el satélite del que se tomó; vacío si ninguno la tenía
+	Source string `json:"source,omitempty" example:"kenobi"`
+	// Discarded son palabras distintas que traían satélites posteriores
+	Discarded []string `json:"discarded,omitempty"`
+}
+
+// locate calcula la posición y el mensaje con los satélites que tienen lectura
+// y están operativos. Los satélites en mantenimiento o retirados se excluyen y
+// los degradados participan con menos peso y después de los activos al
+// reconstruir el mensaje. Skipped se rellena también cuando el cálculo falla.
+func locate(satellites []repository.Satellite) (TopSecretResponse, error) {
+	result, _, err := locateDiagnosed(satellites)
+	return result, err
+}
+
+// locateDiagnosed es locate con el diagnóstico del cálculo, que también se
+// devuelve cuando el cálculo falla
+func locateDiagnosed(satellites []repository.Satellite) (TopSecretResponse, *LocateDiagnostics, error) {
+	var active, degraded []repository.Satellite
+	var result TopSecretResponse
+
+	for _, sat := range satellites {
+		if sat.Distance <= 0 || len(sat.Message) == 0 {
+			result.Skipped = append(result.Skipped, SkippedSatellite{Name: sat.Name, Reason: skipReasonNoReading})
+			continue
+		}
+		switch sat.Status.Effective() {
+		case repository.StatusActive:
+			active = append(active, sat)
+		case repository.StatusDegraded:
+			degraded = append(degraded, sat)
+		case repository.StatusMaintenance:
+			result.Skipped = append(result.Skipped, SkippedSatellite{Name: sat.Name, Reason: skipReasonMaintenance, Detail: sat.Status.Reason})
+		default:
+			result.Skipped = append(result.Skipped, SkippedSatellite{Name: sat.Name, Reason: skipReasonDecommissioned, Detail: sat.Status.Reason})
+		}
+	}
+
+	used := append(active, degraded...)
+	diag := &LocateDiagnostics{Satellites: make([]DiagnosticSatellite, len(used))}
+	for i, sat := range used {
+		diag.Satellites[i] = DiagnosticSatellite{
+			Name:     sat.Name,
+			Position: Position{X: sat.Position.X, Y: sat.Position.Y},
+			Distance: sat.Distance,
+			Weight:   1,
+		}
+		if i >= len(active) {
+			diag.Satellites[i].Weight = degradedWeight
+		}
+	}
+	if len(used) < 3 {
+		return result, diag, errNotEnoughSatellites
+	}
+
+	// Preparar datos para la trilateración
+	positions := make([]calculos.Point32, len(used))
+	distances := make([]float32, len(used))
+	weights := make([]float64, len(used))
+	messages := make([][]string, len(used))
+	for i, sat := range used {
+		positions[i] = calculos.Point32{X: sat.Position.X, Y: sat.Position.Y}
+		distances[i] = sat.Distance
+		weights[i] = diag.Satellites[i].Weight
+		messages[i] = sat.Message
+	}
+
+	// Calcular posición: con tres satélites activos se mantiene el cálculo exacto
+	var location calculos.Point32
+	var solver calculos.Diagnostics
+	if len(used) == 3 && len(degraded) == 0 {
+		location, solver = calculos.GetLocationDiagnostics(positions[0], positions[1], positions[2],
+			distances[0], distances[1], distances[2])
+	} else {
+		location, solver = calculos.GetLocationWeightedDiagnostics(positions, distances, weights)
+	}
+	diag.addSolver(solver)
+	if solver.Err != nil && solver.Solver == calculos.SolverWeighted {
+		// Se conserva el error del cálculo para informar de los residuos
+		return result, diag, fmt.Errorf("%w: %w", errLocationUnavailable, solver.Err)
+	}
+
+	// Recuperar mensaje
+	message, trace, err := calculos.MergeMessagesTrace(messages...)
+	diag.addMerge(trace)
+	if err != nil {
+		return result, diag, errUndecodableMessage
+	}
+
+	result.Position = Position{X: location.X, Y: location.Y}
+	result.Message = message
+	return result, diag, nil
+}
+
+// addSolver incorpora el detalle del método de cálculo
+func (d *LocateDiagnostics) addSolver(solver calculos.Diagnostics) {
+	d.Solver = solver.Solver
+	d.Estimate = PrecisePosition{X: solver.Position.X, Y: solver.Position.Y}
+	d.Tolerance = solver.Tolerance
+	d.Determinant = solver.Determinant
+	d.Iterations = solver.Iterations
+	if solver.Err != nil {
+		d.SolverError = solver.Err.Error()
+	}
+	for i := range solver.Residuals {
+		d.Satellites[i].Residual = &solver.Residuals[i]
+	}
+}
+
+// addMerge incorpora la traza de la reconstrucción del mensaje, con los
+// índices de las copias traducidos a nombres de satélite
+func (d *LocateDiagnostics) addMerge(trace calculos.MergeTrace) {
+	for i, padding := range trace.Padding {
+		d.Satellites[i].Padding = padding
+	}
+	d.Merge = make([]MergedWord, len(trace.Words))
+	for i, word := range trace.Words {
+		d.Merge[i] = MergedWord{Position: i, Word: word.Word, Discarded: word.Discarded}
+		if word.Source >= 0 {
+			d.Merge[i].Source = d.Satellites[word.Source].Name
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fuegodequasar/internal/platform/repository"
+	"log"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// maxBatchItems limita el número de transmisiones de un lote
+const maxBatchItems = 10000
+
+// maxBatchBodyBytes limita el cuerpo de un lote o de un trabajo; deja margen
+// para maxBatchItems transmisiones de tres satélites
+const maxBatchBodyBytes = 32 << 20
+
+// BatchItem es una transmisión del lote con el identificador que le da el cliente
+// @Description Transmisión a localizar dentro de un lote
+type BatchItem struct {
+	ID         string          `json:"id" example:"session-1/0001"`
+	Satellites []SatelliteInfo `json:"satellites" binding:"satellites,dive"`
+}
+
+// BatchRequest es el cuerpo de POST /topsecret/batch
+// @Description Lote de transmisiones a localizar
+type BatchRequest struct {
+	Items []BatchItem `json:"items"`
+}
+
+// BatchItemResult es el resultado de una transmisión del lote: Result si se
+// pudo calcular o Problem en caso contrario
+// @Description Resultado de una transmisión del lote
+type BatchItemResult struct {
+	ID     string             `json:"id" example:"session-1/0001"`
+	Index  int                `json:"index" example:"0"`
+	Result *TopSecretResponse `json:"result,omitempty"`
+	// Problem es el error de la transmisión, con el mismo formato que en la API
+	Problem *Problem `json:"problem,omitempty"`
+}
+
+// BatchSummary resume el resultado de un lote
+// @Description Resumen de un lote
+type BatchSummary struct {
+	Total      int   `json:"total" example:"1000"`
+	Succeeded  int   `json:"succeeded" example:"990"`
+	Failed     int   `json:"failed" example:"10"`
+	DurationMS int64 `json:"duration_ms" example:"120"`
+}
+
+// BatchResponse es la respuesta de POST /topsecret/batch, con los resultados
+// en el mismo orden que las transmisiones recibidas
+// @Description Resultados de un lote
+type BatchResponse struct {
+	Results []BatchItemResult `json:"results"`
+	Summary BatchSummary      `json:"summary"`
+}
+
+// @Summary Localiza un lote de transmisiones
+// @Description Calcula la posición y el mensaje de cada transmisión del lote de forma concurrente. Cada transmisión se resuelve de forma independiente con las posiciones y estados de los satélites registrados, sin guardar sus lecturas. Un fallo en una transmisión no hace fallar el lote.
+// @Tags topsecret
+// @Accept json
+// @Produce json
+// @Param request body BatchRequest true "Transmisiones a localizar (máximo 10000)"
+// @Success 200 {object} BatchResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 413 {object} Problem
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/topsecret/batch [post]
+func handleTopSecretBatch(repo repository.RepositoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request BatchRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			respondProblem(c, bindingProblem(err))
+			return
+		}
+		if len(request.Items) > maxBatchItems {
+			abortProblem(c, CodeBatchTooLarge, "Too many items in batch, the maximum is 10000")
+			return
+		}
+
+		satellites, err := repo.GetAllSatellites(c.Request.Context())
+		if err != nil {
+			abortProblem(c, CodeInternal, "Failed to retrieve satellites")
+			return
+		}
+
+		c.JSON(http.StatusOK, locateBatch(c.Request.Context(), satellites, request.Items, runtime.GOMAXPROCS(0)))
+	}
+}
+
+// locateBatch resuelve las transmisiones con un máximo de workers a la vez.
+// Si ctx se cancela, las transmisiones pendientes terminan con error.
+func locateBatch(ctx context.Context, constellation []repository.Satellite, items []BatchItem, workers int) BatchResponse {
+	start := time.Now()
+	results := make([]BatchItemResult, len(items))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(items)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = locateBatchItem(ctx, constellation, items[i], i)
+			}
+		}()
+	}
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	summary := BatchSummary{Total: len(items), DurationMS: time.Since(start).Milliseconds()}
+	for _, result := range results {
+		if result.Problem == nil {
+			summary.Succeeded++
+		} else {
+			summary.Failed++
+		}
+	}
+	return BatchResponse{Results: results, Summary: summary}
+}
+
+// locateBatchItem resuelve una transmisión sobre una copia de la constelación
+// en la que solo tienen lectura los satélites de la transmisión
+func locateBatchItem(ctx context.Context, constellation []repository.Satellite, item BatchItem, index int) (result BatchItemResult) {
+	result = BatchItemResult{ID: item.ID, Index: index}
+	if ctx.Err() != nil {
+		problem := newProblem(CodeCanceled, "Batch was canceled")
+		result.Problem = &problem
+		return result
+	}
+	// Un fallo inesperado en una transmisión no debe tumbar el lote
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("batch: item %d panicked: %v", index, r)
+			problem := newProblem(CodeInternal, "Internal error")
+			result = BatchItemResult{ID: item.ID, Index: index, Problem: &problem}
+		}
+	}()
+
+	// Cada transmisión se valida por separado para que una inválida no haga fallar el lote
+	var validationErrs validator.ValidationErrors
+	if err := validate(item); errors.As(err, &validationErrs) {
+		problem := validationProblem(validationErrs)
+		result.Problem = &problem
+		return result
+	}
+
+	positions := make(map[string]int, len(constellation))
+	satellites := make([]repository.Satellite, len(constellation))
+	for i, sat := range constellation {
+		sat.Distance, sat.Message = 0, nil
+		satellites[i] = sat
+		positions[sat.Name] = i
+	}
+	for _, info := range item.Satellites {
+		i, known := positions[info.Name]
+		if !known {
+			// Igual que en /topsecret, un satélite desconocido tiene la posición por defecto
+			i = len(satellites)
+			positions[info.Name] = i
+			satellites = append(satellites, repository.Satellite{Name: info.Name})
+		}
+		satellites[i].Distance = info.Distance
+		satellites[i].Message = info.Message
+	}
+
+	response, err := locate(satellites)
+	if err != nil {
+		problem := locateProblem(err, response.Skipped)
+		result.Problem = &problem
+		return result
+	}
+	result.Result = &response
+	return result
+}
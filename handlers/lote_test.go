@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"fuegodequasar/internal/platform/repository"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// transmission es una transmisión con las distancias exactas de los satélites
+// de la constelación por defecto a (x, y) y el mensaje "este es un mensaje"
+func transmission(id string, x, y float64) BatchItem {
+	messages := [][]string{{"este", "", "un", ""}, {"", "es", "", ""}, {"este", "", "", "mensaje"}}
+	item := BatchItem{ID: id}
+	for i, sat := range repository.DefaultConstellation() {
+		distance := math.Hypot(x-float64(sat.Position.X), y-float64(sat.Position.Y))
+		item.Satellites = append(item.Satellites, SatelliteInfo{Name: sat.Name, Distance: float32(distance), Message: messages[i]})
+	}
+	return item
+}
+
+func TestTopSecretBatch(t *testing.T) {
+	repo := repository.New()
+	router := newTestRouter(repo)
+
+	invalid := transmission("invalid", 0, 0)
+	invalid.Satellites[1].Distance = -1
+	alone := transmission("alone", 0, 0)
+	alone.Satellites = alone.Satellites[:1]
+	items := []BatchItem{
+		transmission("first", -100, 75.5),
+		invalid,
+		transmission("third", 200, 300),
+		alone,
+		transmission("fifth", 426.4, -252.8),
+	}
+	body, err := json.Marshal(BatchRequest{Items: items})
+	if err != nil {
+		t.Fatalf("marshal batch: %v", err)
+	}
+
+	w := serve(router, http.MethodPost, "/topsecret/batch", string(body), nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	var response BatchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode batch: %v", err)
+	}
+
+	// Los resultados siguen el orden de las transmisiones aunque se resuelvan
+	// en paralelo, y una inválida no hace fallar a las demás
+	tests := []struct {
+		id       string
+		x, y     float32
+		wantCode ProblemCode
+	}{
+		{"first", -100, 75.5, ""},
+		{"invalid", 0, 0, CodeValidationFailed},
+		{"third", 200, 300, ""},
+		{"alone", 0, 0, CodeNotEnoughSatellites},
+		{"fifth", 426.4, -252.8, ""},
+	}
+	if len(response.Results) != len(tests) {
+		t.Fatalf("results = %+v, want %d", response.Results, len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			result := response.Results[i]
+			if result.ID != tt.id || result.Index != i {
+				t.Fatalf("result %d = %q at index %d, want %q", i, result.ID, result.Index, tt.id)
+			}
+			if tt.wantCode != "" {
+				if result.Problem == nil || result.Problem.Code != tt.wantCode || result.Result != nil {
+					t.Fatalf("result = %+v, want problem %q", result, tt.wantCode)
+				}
+				return
+			}
+			if result.Problem != nil || result.Result == nil {
+				t.Fatalf("result = %+v, want a location", result)
+			}
+			position := result.Result.Position
+			if math.Abs(float64(position.X-tt.x)) > 0.01 || math.Abs(float64(position.Y-tt.y)) > 0.01 || result.Result.Message != "este es un mensaje" {
+				t.Fatalf("result = %+v, want (%v, %v)", result.Result, tt.x, tt.y)
+			}
+		})
+	}
+
+	if summary := response.Summary; summary.Total != 5 || summary.Succeeded != 3 || summary.Failed != 2 {
+		t.Fatalf("summary = %+v, want 5 total, 3 succeeded and 2 failed", summary)
+	}
+	invalidProblem := response.Results[1].Problem
+	if len(invalidProblem.Errors) != 1 || invalidProblem.Errors[0].Field != "satellites[1].distance" {
+		t.Fatalf("invalid item errors = %+v, want satellites[1].distance", invalidProblem.Errors)
+	}
+
+	// El lote no guarda las lecturas de sus transmisiones
+	satellites, err := repo.GetAllSatellites(context.Background())
+	if err != nil {
+		t.Fatalf("GetAllSatellites: %v", err)
+	}
+	for _, sat := range satellites {
+		if sat.Distance != 0 || len(sat.Message) != 0 || sat.Version != 1 {
+			t.Fatalf("%s = %+v after a batch, want it untouched", sat.Name, sat)
+		}
+	}
+}
+
+func TestTopSecretBatchLimits(t *testing.T) {
+	router := newTestRouter(repository.New())
+
+	tests := []struct {
+		name     string
+		body     string
+		want     int
+		wantCode ProblemCode
+	}{
+		{"empty batch", `{"items":[]}`, http.StatusOK, ""},
+		{"at the item limit", `{"items":[` + strings.Repeat(`{},`, maxBatchItems-1) + `{}]}`, http.StatusOK, ""},
+		{"too many items", `{"items":[` + strings.Repeat(`{},`, maxBatchItems) + `{}]}`, http.StatusBadRequest, CodeBatchTooLarge},
+		{"body too large", `{"items":[` + strings.Repeat(" ", maxBatchBodyBytes) + `]}`, http.StatusRequestEntityTooLarge, CodePayloadTooLarge},
+		{"not json", `{"items":`, http.StatusBadRequest, CodeInvalidRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodPost, "/topsecret/batch", tt.body, nil)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %.200s", w.Code, tt.want, w.Body)
+			}
+			if tt.wantCode != "" {
+				if problem := decodeProblem(t, w); problem.Code != tt.wantCode {
+					t.Fatalf("code = %q, want %q", problem.Code, tt.wantCode)
+				}
+			}
+		})
+	}
+}
+
+func TestLocateBatchCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	items := make([]BatchItem, 4)
+	for i := range items {
+		items[i] = transmission(fmt.Sprint(i), 0, 0)
+	}
+
+	response := locateBatch(ctx, repository.DefaultConstellation(), items, 2)
+	if response.Summary.Failed != len(items) {
+		t.Fatalf("summary = %+v, want every item failed", response.Summary)
+	}
+	for _, result := range response.Results {
+		if result.Problem == nil || result.Problem.Code != CodeCanceled {
+			t.Fatalf("result = %+v, want %q", result, CodeCanceled)
+		}
+	}
+}
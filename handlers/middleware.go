@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fuegodequasar/internal/platform/audit"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestIDKey es la clave del contexto de gin donde se guarda el ID de la petición
+const requestIDKey = "request_id"
+
+// permissionsKey es la clave del contexto de gin con los permisos concedidos
+const permissionsKey = "permissions"
+
+// Permission es un permiso que se concede a una petición
+type Permission string
+
+// PermissionDebug permite pedir el diagnóstico de los cálculos con ?debug=true
+const PermissionDebug Permission = "debug"
+
+// RequestID asigna a cada petición un identificador, respetando el que envíe
+// el cliente en X-Request-ID, y lo devuelve en la respuesta
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader("X-Request-ID")
+		if id == "" || len(id) > 128 {
+			id = newRequestID()
+		}
+		c.Set(requestIDKey, id)
+		c.Header("X-Request-ID", id)
+		c.Next()
+	}
+}
+
+// AuditActor añade al contexto de la petición el actor que el registro de
+// auditoría asocia a los cambios. La identidad del llamador se toma de la
+// cabecera X-Caller-ID.
+func AuditActor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor := audit.Actor{
+			ID:        c.GetHeader("X-Caller-ID"),
+			ClientIP:  c.ClientIP(),
+			RequestID: c.GetString(requestIDKey),
+		}
+		if actor.ID == "" {
+			actor.ID = "anonymous"
+		}
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), actor))
+		c.Next()
+	}
+}
+
+// Deprecated marca las respuestas de unas rutas obsoletas con la cabecera
+// Deprecation (RFC 9745), con la fecha desde la que lo están, con Sunset
+// (RFC 8594), con la fecha en que dejarán de servirse, y con un Link a la
+// ruta equivalente bajo successor
+func Deprecated(successor string, since, sunset time.Time) gin.HandlerFunc {
+	deprecation := "@" + strconv.FormatInt(since.Unix(), 10)
+	sunsetDate := sunset.UTC().Format(http.TimeFormat)
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecation)
+		c.Header("Sunset", sunsetDate)
+		c.Header("Link", "<"+successor+c.Request.URL.Path+`>; rel="successor-version"`)
+		c.Next()
+	}
+}
+
+// limitBody limita el cuerpo de la petición a maxBytes. Al pasarse, la lectura
+// del cuerpo falla con *http.MaxBytesError, que bindingProblem responde con 413.
+func limitBody(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
+
+// DebugAccess concede PermissionDebug a las peticiones que presentan token en
+// la cabecera X-Debug-Token. Sin token configurado no se concede a nadie. Es
+// la única vía para obtenerlo: ningún rol lo incluye, ni siquiera admin.
+func DebugAccess(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		presented := c.GetHeader("X-Debug-Token")
+		if token != "" && subtle.ConstantTimeCompare([]byte(presented), []byte(token)) == 1 {
+			grantPermission(c, PermissionDebug)
+		}
+		c.Next()
+	}
+}
+
+// grantPermission concede un permiso a la petición
+func grantPermission(c *gin.Context, permission Permission) {
+	permissions, _ := c.Get(permissionsKey)
+	granted, _ := permissions.(map[Permission]bool)
+	if granted == nil {
+		granted = make(map[Permission]bool)
+		c.Set(permissionsKey, granted)
+	}
+	granted[permission] = true
+}
+
+// hasPermission indica si la petición tiene el permiso
+func hasPermission(c *gin.Context, permission Permission) bool {
+	permissions, _ := c.Get(permissionsKey)
+	granted, _ := permissions.(map[Permission]bool)
+	return granted[permission]
+}
+
+func newRequestID() string {
+	var b [16]byte
+	// crypto/rand.Read no falla en las plataformas soportadas
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fuegodequasar/internal/platform/history"
+	"fuegodequasar/internal/platform/jobs"
+	"fuegodequasar/internal/platform/repository"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLimitBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := jobs.NewManager(jobs.Config{})
+	defer manager.Shutdown(context.Background())
+
+	router := gin.New()
+	router.Use(Authenticate(nil))
+	SetupRoutes(context.Background(), router, repository.New(), history.NewStore(history.Retention{}), nil)
+	SetupJobRoutes(router, repository.New(), manager)
+	SetupV2Routes(router.Group("/v2"), repository.New(), history.NewStore(history.Retention{}), nil)
+
+	// padded rellena con espacios, que siguen siendo JSON válido, hasta size bytes
+	padded := func(body string, size int) string {
+		return body[:len(body)-1] + strings.Repeat(" ", size-len(body)) + "}"
+	}
+	const split = `{"distance":100,"message":["este","","un","",""]}`
+
+	tests := []struct {
+		name string
+		path string
+		body string
+		want int
+	}{
+		{"topsecret within limit", "/topsecret", padded(`{"satellites":[]}`, maxBodyBytes), http.StatusBadRequest},
+		{"topsecret too large", "/topsecret", padded(`{"satellites":[]}`, maxBodyBytes+1), http.StatusRequestEntityTooLarge},
+		{"v2 topsecret too large", "/v2/topsecret", padded(`{"satellites":[]}`, maxBodyBytes+1), http.StatusRequestEntityTooLarge},
+		{"split within limit", "/topsecret_split/kenobi", padded(split, maxBodyBytes), http.StatusOK},
+		{"split too large", "/topsecret_split/kenobi", padded(split, maxBodyBytes+1), http.StatusRequestEntityTooLarge},
+		{"batch within limit", "/topsecret/batch", padded(`{"items":[]}`, maxBodyBytes+1), http.StatusOK},
+		{"batch too large", "/topsecret/batch", padded(`{"items":[]}`, maxBatchBodyBytes+1), http.StatusRequestEntityTooLarge},
+		{"job too large", "/jobs", padded(`{"items":[]}`, maxBatchBodyBytes+1), http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if tt.want != http.StatusRequestEntityTooLarge {
+				return
+			}
+			var problem Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil || problem.Code != CodePayloadTooLarge {
+				t.Fatalf("problem = %+v, %v, want code %q", problem, err, CodePayloadTooLarge)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"fuegodequasar/internal/platform/calculos"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// problemContentType es el tipo de contenido de los errores (RFC 7807)
+const problemContentType = "application/problem+json"
+
+// problemTypeBase es la ruta bajo la que se documenta cada código; el campo
+// type de un problema apunta a /api/v1/problems/{code}
+const problemTypeBase = "/api/v1/problems/"
+
+// ProblemCode es el identificador estable de un tipo de error. Los clientes
+// deben decidir por el código y no por el texto de title o detail.
+type ProblemCode string
+
+// Catálogo de códigos de error
+const (
+	CodeInvalidRequest      ProblemCode = "invalid_request"
+	CodeValidationFailed    ProblemCode = "validation_failed"
+	CodePayloadTooLarge     ProblemCode = "payload_too_large"
+	CodeUnauthorized        ProblemCode = "unauthorized"
+	CodeForbidden           ProblemCode = "forbidden"
+	CodeInvalidSignature    ProblemCode = "invalid_signature"
+	CodeInvalidParameter    ProblemCode = "invalid_parameter"
+	CodeBatchTooLarge       ProblemCode = "batch_too_large"
+	CodeInvalidArchive      ProblemCode = "invalid_archive"
+	CodeSatelliteNotFound   ProblemCode = "satellite_not_found"
+	CodeNotEnoughSatellites ProblemCode = "not_enough_satellites"
+	CodeLocationUnavailable ProblemCode = "location_unavailable"
+	CodeMessageUndecodable  ProblemCode = "message_undecodable"
+	CodePreconditionFailed  ProblemCode = "precondition_failed"
+	CodeVersionConflict     ProblemCode = "version_conflict"
+	CodeJobNotFound         ProblemCode = "job_not_found"
+	CodeJobFinished         ProblemCode = "job_finished"
+	CodeProblemNotFound     ProblemCode = "problem_not_found"
+	CodeRateLimited         ProblemCode = "rate_limited"
+	CodeQuotaExceeded       ProblemCode = "quota_exceeded"
+	CodeQueueFull           ProblemCode = "queue_full"
+	CodeShuttingDown        ProblemCode = "shutting_down"
+	CodeCanceled            ProblemCode = "canceled"
+	CodeInternal            ProblemCode = "internal_error"
+)
+
+// ProblemType documenta un código del catálogo
+// @Description Entrada del catálogo de errores
+type ProblemType struct {
+	Code        ProblemCode `json:"code" example:"not_enough_satellites"`
+	Status      int         `json:"status" example:"404"`
+	Title       string      `json:"title" example:"Not enough satellite data"`
+	Description string      `json:"description"`
+}
+
+var problemCatalog = map[ProblemCode]ProblemType{
+	CodeInvalidRequest: {Status: http.StatusBadRequest, Title: "Invalid request format",
+		Description: "El cuerpo no es JSON válido o no tiene la forma esperada; errors indica los campos afectados."},
+	CodeValidationFailed: {Status: http.StatusBadRequest, Title: "Validation failed",
+		Description: "El cuerpo tiene la forma esperada pero algún valor incumple las reglas (distancia finita y positiva, nombre obligatorio y sin repetir, límites de palabras y satélites); errors lista todos los campos que las incumplen."},
+	CodePayloadTooLarge: {Status: http.StatusRequestEntityTooLarge, Title: "Payload too large",
+		Description: "El cuerpo o el frame supera el tamaño máximo admitido."},
+	CodeUnauthorized: {Status: http.StatusUnauthorized, Title: "Unauthorized",
+		Description: "Faltan las credenciales o no son válidas."},
+	CodeForbidden: {Status: http.StatusForbidden, Title: "Forbidden",
+		Description: "Las credenciales no tienen el permiso que requiere la operación."},
+	CodeInvalidSignature: {Status: http.StatusUnauthorized, Title: "Invalid signature",
+		Description: "La lectura no trae una firma válida de su satélite sobre el cuerpo, el nombre y un instante dentro de la ventana admitida, o la firma ya se usó."},
+	CodeInvalidParameter: {Status: http.StatusBadRequest, Title: "Invalid parameter",
+		Description: "Un parámetro de la ruta o de la consulta no tiene un valor válido; errors indica cuál y qué se esperaba."},
+	CodeBatchTooLarge: {Status: http.StatusBadRequest, Title: "Batch too large",
+		Description: "El lote supera el número máximo de transmisiones."},
+	CodeInvalidArchive: {Status: http.StatusBadRequest, Title: "Invalid backup archive",
+		Description: "El archivo de copia de seguridad no supera la validación; no se aplicó ningún cambio."},
+	CodeSatelliteNotFound: {Status: http.StatusNotFound, Title: "Satellite not found",
+		Description: "El satélite no está registrado."},
+	CodeNotEnoughSatellites: {Status: http.StatusNotFound, Title: "Not enough satellite data",
+		Description: "Hacen falta al menos tres satélites operativos con lectura; missing lista los que no tienen lectura y skipped los excluidos por su estado."},
+	CodeLocationUnavailable: {Status: http.StatusNotFound, Title: "Could not determine location",
+		Description: "Las distancias no permiten calcular una posición; residuals tiene los residuos de la última estimación, si la hubo."},
+	CodeMessageUndecodable: {Status: http.StatusNotFound, Title: "Could not decode message",
+		Description: "Los fragmentos de mensaje recibidos no permiten reconstruir el mensaje."},
+	CodePreconditionFailed: {Status: http.StatusPreconditionFailed, Title: "Precondition failed",
+		Description: "La versión indicada en If-Match ya no es la actual."},
+	CodeVersionConflict: {Status: http.StatusConflict, Title: "Version conflict",
+		Description: "Otras peticiones modificaron el satélite a la vez; se puede reintentar."},
+	CodeJobNotFound: {Status: http.StatusNotFound, Title: "Job not found",
+		Description: "El trabajo no existe o ya expiró su retención."},
+	CodeJobFinished: {Status: http.StatusConflict, Title: "Job already finished",
+		Description: "El trabajo ya terminó y no se puede cancelar."},
+	CodeProblemNotFound: {Status: http.StatusNotFound, Title: "Problem type not found",
+		Description: "El código no está en el catálogo de errores que lista /problems."},
+	CodeRateLimited: {Status: http.StatusTooManyRequests, Title: "Too many requests",
+		Description: "El cliente supera el ritmo de peticiones de la ruta; RateLimit-Remaining y RateLimit-Reset describen su cubo y se puede reintentar pasado Retry-After."},
+	CodeQuotaExceeded: {Status: http.StatusTooManyRequests, Title: "Daily quota exceeded",
+		Description: "El cliente agotó la cuota diaria de la ruta; se renueva a medianoche UTC, indicada en Retry-After."},
+	CodeQueueFull: {Status: http.StatusServiceUnavailable, Title: "Job queue is full",
+		Description: "La cola de trabajos está llena; se puede reintentar pasado Retry-After."},
+	CodeShuttingDown: {Status: http.StatusServiceUnavailable, Title: "Server is shutting down",
+		Description: "El servidor se está apagando y no acepta trabajo nuevo."},
+	CodeCanceled: {Status: http.StatusServiceUnavailable, Title: "Canceled",
+		Description: "La petición o el trabajo se canceló antes de terminar."},
+	CodeInternal: {Status: http.StatusInternalServerError, Title: "Internal error",
+		Description: "Error inesperado del servidor."},
+}
+
+// FieldError señala un campo o parámetro inválido
+// @Description Campo o parámetro inválido
+type FieldError struct {
+	// Field es la ruta del campo en el cuerpo (p. ej. satellites.distance) o
+	// el nombre del parámetro
+	Field string `json:"field" example:"satellites.distance"`
+	// In indica dónde está el campo: body, query, path o header
+	In      string `json:"in" example:"body"`
+	Message string `json:"message" example:"expected number"`
+}
+
+// Problem es un error con formato application/problem+json (RFC 7807). Además
+// de los miembros estándar lleva el código estable y los detalles que
+// correspondan al tipo de error.
+// @Description Error con formato RFC 7807
+type Problem struct {
+	Type     string      `json:"type" example:"/api/v1/problems/not_enough_satellites"`
+	Title    string      `json:"title" example:"Not enough satellite data"`
+	Status   int         `json:"status" example:"404"`
+	Detail   string      `json:"detail,omitempty" example:"Not enough satellite data"`
+	Instance string      `json:"instance,omitempty" example:"/api/v1/topsecret_split"`
+	Code     ProblemCode `json:"code" example:"not_enough_satellites"`
+	// RequestID es el identificador de la petición, el mismo que X-Request-ID
+	RequestID string `json:"request_id,omitempty" example:"6f1c2a9e0b7d4e3f8a5b6c7d8e9f0a1b"`
+	// Missing son los satélites de los que falta lectura
+	Missing []string `json:"missing,omitempty" example:"sato"`
+	// Skipped son los satélites excluidos del cálculo por su estado
+	Skipped []SkippedSatellite `json:"skipped,omitempty"`
+	// Residuals son los residuos de la última estimación de la posición
+	Residuals []float64    `json:"residuals,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	// Debug es el diagnóstico del cálculo, solo con ?debug=true
+	Debug *LocateDiagnostics `json:"debug,omitempty"`
+}
+
+// newProblem crea un problema del catálogo con el detalle indicado
+func newProblem(code ProblemCode, detail string) Problem {
+	entry, known := problemCatalog[code]
+	if !known {
+		code, entry = CodeInternal, problemCatalog[CodeInternal]
+	}
+	return Problem{
+		Type:   problemTypeBase + string(code),
+		Title:  entry.Title,
+		Status: entry.Status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// respondProblem escribe el problema y aborta la petición
+func respondProblem(c *gin.Context, problem Problem) {
+	problem.Instance = c.Request.URL.Path
+	problem.RequestID = c.GetString(requestIDKey)
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// abortProblem responde con un problema del catálogo sin más detalles
+func abortProblem(c *gin.Context, code ProblemCode, detail string) {
+	respondProblem(c, newProblem(code, detail))
+}
+
+// paramProblem responde que el parámetro name no es válido
+func paramProblem(c *gin.Context, in, name, detail, expected string) {
+	problem := newProblem(CodeInvalidParameter, detail)
+	problem.Errors = []FieldError{{Field: name, In: in, Message: expected}}
+	respondProblem(c, problem)
+}
+
+// bindingProblem describe por qué no se pudo leer el cuerpo de la petición
+func bindingProblem(err error) Problem {
+	problem := newProblem(CodeInvalidRequest, "Invalid request format")
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var validationErrs validator.ValidationErrors
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &validationErrs):
+		return validationProblem(validationErrs)
+	case errors.As(err, &maxBytesErr):
+		return newProblem(CodePayloadTooLarge, fmt.Sprintf("Request body is too large, the maximum is %d bytes", maxBytesErr.Limit))
+	case errors.Is(err, io.EOF):
+		problem.Detail = "Request body is empty"
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		problem.Detail = "Request body is not valid JSON"
+	case errors.As(err, &syntaxErr):
+		problem.Detail = "Request body is not valid JSON"
+		problem.Errors = []FieldError{{In: "body", Message: syntaxErr.Error()}}
+	case errors.As(err, &typeErr):
+		problem.Errors = []FieldError{{Field: typeErr.Field, In: "body", Message: "expected " + jsonTypeName(typeErr.Type)}}
+	}
+	return problem
+}
+
+// validationProblem describe todos los campos que incumplen las reglas de validación
+func validationProblem(errs validator.ValidationErrors) Problem {
+	problem := newProblem(CodeValidationFailed, "Request body failed validation")
+	problem.Errors = fieldErrors(errs)
+	return problem
+}
+
+// jsonTypeName devuelve el nombre JSON del tipo que se esperaba en un campo
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	}
+	return t.String()
+}
+
+// locateProblem traduce un error de locate a su problema, con los satélites
+// que faltan o se excluyeron y los residuos del cálculo si los hay
+func locateProblem(err error, skipped []SkippedSatellite) Problem {
+	code := CodeMessageUndecodable
+	switch {
+	case errors.Is(err, errNotEnoughSatellites):
+		code = CodeNotEnoughSatellites
+	case errors.Is(err, errLocationUnavailable):
+		code = CodeLocationUnavailable
+	}
+	problem := newProblem(code, locateErrorMessage(err))
+	for _, sat := range skipped {
+		if sat.Reason == skipReasonNoReading {
+			problem.Missing = append(problem.Missing, sat.Name)
+		} else {
+			problem.Skipped = append(problem.Skipped, sat)
+		}
+	}
+	var solverErr *calculos.SolverError
+	if errors.As(err, &solverErr) {
+		problem.Residuals = solverErr.Residuals
+	}
+	return problem
+}
+
+// @Summary Catálogo de errores
+// @Description Lista los códigos estables que pueden aparecer en el campo code de un error application/problem+json
+// @Tags problems
+// @Produce json
+// @Success 200 {array} ProblemType
+// @Router /v1/problems [get]
+func handleListProblems(c *gin.Context) {
+	types := make([]ProblemType, 0, len(problemCatalog))
+	for code, entry := range problemCatalog {
+		entry.Code = code
+		types = append(types, entry)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Code < types[j].Code })
+	c.JSON(http.StatusOK, types)
+}
+
+// @Summary Describe un código de error
+// @Description Documentación del tipo de error al que apunta el campo type de un problema
+// @Tags problems
+// @Produce json
+// @Param code path string true "Código del error"
+// @Success 200 {object} ProblemType
+// @Failure 404 {object} Problem
+// @Router /v1/problems/{code} [get]
+func handleGetProblem(c *gin.Context) {
+	code := ProblemCode(c.Param("code"))
+	entry, known := problemCatalog[code]
+	if !known {
+		abortProblem(c, CodeProblemNotFound, "Unknown problem code")
+		return
+	}
+	entry.Code = code
+	c.JSON(http.StatusOK, entry)
+}
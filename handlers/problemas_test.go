@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetProblem(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/problems/:code", handleGetProblem)
+
+	tests := []struct {
+		name     string
+		code     string
+		want     int
+		wantCode ProblemCode
+	}{
+		{"known code", string(CodeNotEnoughSatellites), http.StatusOK, CodeNotEnoughSatellites},
+		{"own entry", string(CodeProblemNotFound), http.StatusOK, CodeProblemNotFound},
+		{"unknown code", "no_such_problem", http.StatusNotFound, CodeProblemNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/problems/"+tt.code, nil))
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			var body struct {
+				Code ProblemCode `json:"code"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Code != tt.wantCode {
+				t.Fatalf("code = %q, %v, want %q", body.Code, err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestProblemCatalogStatus(t *testing.T) {
+	// Cada código del catálogo es un error con un título
+	for code, entry := range problemCatalog {
+		if entry.Status < 400 || entry.Title == "" || entry.Description == "" {
+			t.Errorf("catalog entry %q = %+v", code, entry)
+		}
+	}
+}
@@ -1,9 +1,14 @@
 package handlers
 
 import (
-	"fuegodequasar/internal/platform/calculos"
+	"context"
+	"errors"
+	"fuegodequasar/internal/platform/auth"
+	"fuegodequasar/internal/platform/history"
 	"fuegodequasar/internal/platform/repository"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,15 +16,15 @@ import (
 // TopSecretRequest representa el payload para /topsecret
 // @Description Datos de los satélites para decodificar mensaje y posición
 type TopSecretRequest struct {
-	Satellites []SatelliteInfo `json:"satellites"`
+	Satellites []SatelliteInfo `json:"satellites" binding:"satellites,dive"`
 }
 
 // SatelliteInfo representa la información de un satélite
 // @Description Información individual de un satélite
 type SatelliteInfo struct {
-	Name     string   `json:"name" example:"kenobi"`
-	Distance float32  `json:"distance" example:"927.75"`
-	Message  []string `json:"message" example:"[\"este\", \"\", \"\", \"mensaje\", \"\"]"`
+	Name     string   `json:"name" binding:"required,max=64" example:"kenobi"`
+	Distance float32  `json:"distance" binding:"distance" example:"927.75"`
+	Message  []string `json:"message" binding:"words" example:"[\"este\", \"\", \"\", \"mensaje\", \"\"]"`
 }
 
 // TopSecretResponse representa la respuesta de /topsecret
@@ -27,6 +32,10 @@ type SatelliteInfo struct {
 type TopSecretResponse struct {
 	Position Position `json:"position"`
 	Message  string   `json:"message" example:"este es un mensaje secreto"`
+	// Skipped lista los satélites que no participaron en el cálculo
+	Skipped []SkippedSatellite `json:"skipped,omitempty"`
+	// Debug es el diagnóstico del cálculo, solo con ?debug=true
+	Debug *LocateDiagnostics `json:"debug,omitempty"`
 }
 
 // Position representa coordenadas X e Y
@@ -37,18 +46,82 @@ type Position struct {
 }
 
 type TopSecretSplitRequest struct {
-	Distance float32  `json:"distance"`
-	Message  []string `json:"message"`
+	Distance float32  `json:"distance" binding:"distance"`
+	Message  []string `json:"message" binding:"words"`
 }
 
-// SetupRoutes configura las rutas HTTP de la API
-func SetupRoutes(router *gin.Engine, repo repository.RepositoryService) {
+// SatelliteReadingResponse representa la lectura guardada de un satélite
+// @Description Lectura actual de un satélite junto con sus metadatos
+type SatelliteReadingResponse struct {
+	Name      string            `json:"name" example:"kenobi"`
+	Position  Position          `json:"position"`
+	Distance  float32           `json:"distance" example:"927.75"`
+	Message   []string          `json:"message" example:"[\"este\", \"\", \"\", \"mensaje\", \"\"]"`
+	UpdatedAt time.Time         `json:"updated_at" example:"2025-01-01T00:00:00Z"`
+	Status    repository.Status `json:"status"`
+}
+
+// maxSaveAttempts limita los reintentos de una escritura que compite con otras peticiones
+const maxSaveAttempts = 3
+
+// maxBodyBytes limita el cuerpo de las peticiones con las lecturas de una
+// transmisión
+const maxBodyBytes = 1 << 20
+
+// errPreconditionFailed indica que la versión del satélite no coincide con la de If-Match
+var errPreconditionFailed = errors.New("precondition failed")
+
+// SetupRoutes configura las rutas HTTP de la API. Cada grupo exige su rol:
+// las estaciones envían lecturas, los analistas consultan y calculan y los
+// administradores pueden además borrar todas las lecturas. El catálogo de
+// errores es público.
+//
+// Las rutas de un satélite admiten además como credencial un certificado de
+// cliente que lo identifique (mTLS). Con signatures, cada lectura de
+// POST /topsecret_split/{satellite_name} debe venir firmada por su satélite o
+// llegar por una conexión con su certificado. POST /topsecret, que trae
+// lecturas de varios satélites sin firma de cada uno, queda entonces solo
+// para admin, igual que DELETE /topsecret_split/{satellite_name} salvo con el
+// certificado de su satélite.
+//
+// Los streams de GET /stream/topsecret_split terminan al cancelarse ctx, de
+// modo que el apagado del servidor no tenga que esperarlos.
+func SetupRoutes(ctx context.Context, router gin.IRouter, repo repository.RepositoryService, readings *history.Store, signatures *auth.SignatureVerifier) {
+	satellites := router.Group("", ClientCertificate(), RequireRole(auth.RoleStation))
+	analysts := router.Group("", RequireRole(auth.RoleAnalyst))
+	admins := router.Group("", RequireRole(auth.RoleAdmin))
+
 	// POST /topsecret
-	router.POST("/topsecret", handleTopSecret(repo))
+	router.POST("/topsecret", RequireRole(unsignedReadingsRole(signatures)), limitBody(maxBodyBytes), handleTopSecret(repo, readings))
+	// POST /topsecret/batch
+	analysts.POST("/topsecret/batch", limitBody(maxBatchBodyBytes), handleTopSecretBatch(repo))
 	// POST /topsecret_split/{satellite_name}
-	router.POST("/topsecret_split/:satellite_name", handleTopSecretSplit(repo))
+	satellites.POST("/topsecret_split/:satellite_name", limitBody(maxBodyBytes), RequireSatelliteSignature(signatures), handleTopSecretSplit(repo, readings))
+	// GET /topsecret_split/{satellite_name}
+	analysts.GET("/topsecret_split/:satellite_name", handleGetSatelliteReading(repo))
+	// DELETE /topsecret_split/{satellite_name}
+	satellites.DELETE("/topsecret_split/:satellite_name", RequireSatelliteCredential(signatures), handleDeleteSatelliteReading(repo))
 	// GET /topsecret_split
-	router.GET("/topsecret_split", handleGetTopSecretSplit(repo))
+	analysts.GET("/topsecret_split", handleGetTopSecretSplit(repo))
+	// DELETE /topsecret_split
+	admins.DELETE("/topsecret_split", handleDeleteTopSecretSplit(repo))
+	// GET /stream/topsecret_split
+	analysts.GET("/stream/topsecret_split", handleTopSecretSplitStream(ctx, repo, streamHeartbeatInterval))
+	// GET /problems
+	router.GET("/problems", handleListProblems)
+	// GET /problems/{code}
+	router.GET("/problems/:code", handleGetProblem)
+	// GET /satellites/{satellite_name}/readings
+	analysts.GET("/satellites/:satellite_name/readings", handleGetSatelliteReadings(repo, readings))
+}
+
+// unsignedReadingsRole es el rol que pueden enviar lecturas sin la firma de
+// su satélite: las estaciones si no se exigen firmas y si no solo admin
+func unsignedReadingsRole(signatures *auth.SignatureVerifier) auth.Role {
+	if signatures != nil {
+		return auth.RoleAdmin
+	}
+	return auth.RoleStation
 }
 
 // @Summary Decodifica mensaje y posición
@@ -57,131 +130,79 @@ func SetupRoutes(router *gin.Engine, repo repository.RepositoryService) {
 // @Accept json
 // @Produce json
 // @Param request body TopSecretRequest true "Datos de los satélites" example({"satellites":[{"name":"kenobi","distance":927.75,"message":["este","","","mensaje",""]},{"name":"skywalker","distance":360,"message":["","es","","","secreto"]},{"name":"sato","distance":360,"message":["este","","un","",""]}]})
+// @Param debug query bool false "Incluye el diagnóstico del cálculo; requiere permiso de depuración"
+// @Param X-Debug-Token header string false "Token que concede el permiso de depuración"
 // @Success 200 {object} TopSecretResponse "Ejemplo de respuesta" example({"position":{"x":426.4001,"y":-252.80016},"message":"este es un mensaje secreto"})
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
-// @Router /topsecret [post]
-func handleTopSecret(repo repository.RepositoryService) gin.HandlerFunc {
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 413 {object} Problem
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/topsecret [post]
+func handleTopSecret(repo repository.RepositoryService, readings *history.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var request TopSecretRequest
-
-		if err := c.ShouldBindJSON(&request); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		// Comprobar el permiso antes de guardar nada
+		debug, ok := debugRequested(c)
+		if !ok {
 			return
 		}
 
-		// Actualizar información de los satélites usando posición fija del repositorio
-		for _, sat := range request.Satellites {
-			// Obtener la posición fija desde el repositorio
-			existing, err := repo.GetSatellite(sat.Name)
-			var pos repository.Point
-			if err == nil {
-				pos = existing.Position
-			} else {
-				// Si no existe, usar posición por defecto (el repo ya lo hace en New())
-				pos = repository.Point{}
-			}
-			satellite := repository.Satellite{
-				Name:     sat.Name,
-				Position: pos, // posición fija
-				Distance: sat.Distance,
-				Message:  sat.Message,
-			}
-			if err := repo.SaveSatellite(satellite); err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save satellite info"})
-				return
-			}
-		}
-
-		// Obtener todos los satélites para el cálculo
-		satellites, err := repo.GetAllSatellites()
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve satellites"})
+		satellites, ok := saveTopSecret(c, repo, readings)
+		if !ok {
 			return
 		}
 
-		// Preparar datos para la trilateración
-		var positions []calculos.Point32
-		var distances []float32
-		var messages [][]string
-
-		for _, sat := range satellites {
-			positions = append(positions, calculos.Point32{
-				X: sat.Position.X,
-				Y: sat.Position.Y,
-			})
-			distances = append(distances, sat.Distance)
-			messages = append(messages, sat.Message)
-		}
-
-		// Calcular posición
-		if len(positions) < 3 {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Not enough satellite data"})
-			return
-		}
-
-		location := calculos.GetLocation(positions[0], positions[1], positions[2],
-			distances[0], distances[1], distances[2])
-
-		// Recuperar mensaje
-		message, err := calculos.GetMessage(messages[0], messages[1], messages[2])
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Could not decode message"})
-			return
-		}
-
-		response := TopSecretResponse{
-			Position: Position{
-				X: location.X,
-				Y: location.Y,
-			},
-			Message: message,
-		}
-
-		c.JSON(http.StatusOK, response)
+		respondLocation(c, satellites, debug)
 	}
 }
 
 // @Summary Guarda información parcial de un satélite
 // @Description Permite guardar la distancia y mensaje de un satélite individualmente
+// @Description Si el servidor exige firmas, la lectura debe llevar en X-Signature la firma en base64 (HMAC-SHA256 o Ed25519) de "satellite_name\ntimestamp\nsha256_hex(cuerpo)" y en X-Signature-Timestamp ese instante en segundos Unix. Cada firma solo se acepta una vez.
+// @Description Con mTLS, un certificado de cliente cuyo CN o SAN (DNS o urn:satellite:<nombre>) sea el satélite sirve como credencial y sustituye a la firma; un certificado de otro satélite se rechaza con 403.
 // @Tags topsecret_split
 // @Accept json
 // @Produce json
 // @Param satellite_name path string true "Nombre del satélite"
 // @Param request body TopSecretSplitRequest true "Distancia y mensaje del satélite"
+// @Param If-Match header string false "ETag de la versión sobre la que se escribe"
+// @Param X-Signature header string false "Firma del satélite, si el servidor exige firmas"
+// @Param X-Signature-Timestamp header string false "Instante de la firma en segundos Unix"
 // @Success 200 "Actualización exitosa"
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
-// @Router /topsecret_split/{satellite_name} [post]
-func handleTopSecretSplit(repo repository.RepositoryService) gin.HandlerFunc {
+// @Header 200 {string} ETag "Versión guardada del satélite"
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 412 {object} Problem
+// @Failure 413 {object} Problem
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/topsecret_split/{satellite_name} [post]
+func handleTopSecretSplit(repo repository.RepositoryService, readings *history.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		satelliteName := c.Param("satellite_name")
 		var request TopSecretSplitRequest
 
 		if err := c.ShouldBindJSON(&request); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+			respondProblem(c, bindingProblem(err))
 			return
 		}
 
-		// Obtener el satélite existente para mantener su posición
-		satellite, err := repo.GetSatellite(satelliteName)
+		// Actualizar la distancia y mensaje manteniendo la posición del satélite existente
+		version, err := saveReading(c.Request.Context(), repo, readings, satelliteName, false, c.GetHeader("If-Match"), request.Distance, request.Message)
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Satellite not found"})
-			return
-		}
-
-		// Actualizar la distancia y mensaje del satélite
-		satellite.Distance = request.Distance
-		satellite.Message = request.Message
-
-		// Guardar la información actualizada
-		if err := repo.SaveSatellite(satellite); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save satellite info"})
+			respondProblem(c, saveReadingProblem(err))
 			return
 		}
 
+		c.Header("ETag", satelliteETag(version))
 		c.Status(http.StatusOK)
 	}
 }
@@ -191,67 +212,269 @@ func handleTopSecretSplit(repo repository.RepositoryService) gin.HandlerFunc {
 // @Tags topsecret_split
 // @Accept json
 // @Produce json
+// @Param debug query bool false "Incluye el diagnóstico del cálculo; requiere permiso de depuración"
+// @Param X-Debug-Token header string false "Token que concede el permiso de depuración"
 // @Success 200 {object} TopSecretResponse
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
-// @Router /topsecret_split [get]
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/topsecret_split [get]
 func handleGetTopSecretSplit(repo repository.RepositoryService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		debug, ok := debugRequested(c)
+		if !ok {
+			return
+		}
+
 		// Obtener todos los satélites
-		satellites, err := repo.GetAllSatellites()
+		satellites, err := repo.GetAllSatellites(c.Request.Context())
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve satellites"})
+			abortProblem(c, CodeInternal, "Failed to retrieve satellites")
 			return
 		}
 
-		// Verificar que tengamos suficiente información
-		validSatellites := 0
-		for _, sat := range satellites {
-			if sat.Distance > 0 && len(sat.Message) > 0 {
-				validSatellites++
-			}
-		}
+		respondLocation(c, satellites, debug)
+	}
+}
 
-		if validSatellites < 3 {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Not enough satellite data"})
+// @Summary Consulta la lectura parcial de un satélite
+// @Description Devuelve la distancia y mensaje guardados de un satélite junto con sus metadatos
+// @Tags topsecret_split
+// @Produce json
+// @Param satellite_name path string true "Nombre del satélite"
+// @Param If-None-Match header string false "ETag de la versión que ya tiene el cliente"
+// @Success 200 {object} SatelliteReadingResponse
+// @Header 200 {string} ETag "Versión actual del satélite"
+// @Success 304 "La versión del cliente sigue vigente"
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/topsecret_split/{satellite_name} [get]
+func handleGetSatelliteReading(repo repository.RepositoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		satellite, err := repo.GetSatellite(c.Request.Context(), c.Param("satellite_name"))
+		if errors.Is(err, repository.ErrSatelliteNotFound) {
+			abortProblem(c, CodeSatelliteNotFound, "Satellite not found")
+			return
+		}
+		if err != nil {
+			abortProblem(c, CodeInternal, "Failed to retrieve satellite")
 			return
 		}
 
-		// Preparar datos para la trilateración
-		var positions []calculos.Point32
-		var distances []float32
-		var messages [][]string
-
-		for _, sat := range satellites {
-			if sat.Distance > 0 && len(sat.Message) > 0 {
-				positions = append(positions, calculos.Point32{
-					X: sat.Position.X,
-					Y: sat.Position.Y,
-				})
-				distances = append(distances, sat.Distance)
-				messages = append(messages, sat.Message)
-			}
+		c.Header("ETag", satelliteETag(satellite.Version))
+		if match := c.GetHeader("If-None-Match"); match != "" && etagMatches(match, satellite.Version) {
+			c.Status(http.StatusNotModified)
+			return
 		}
 
-		// Calcular posición
-		location := calculos.GetLocation(positions[0], positions[1], positions[2],
-			distances[0], distances[1], distances[2])
+		c.JSON(http.StatusOK, SatelliteReadingResponse{
+			Name: satellite.Name,
+			Position: Position{
+				X: satellite.Position.X,
+				Y: satellite.Position.Y,
+			},
+			Distance:  satellite.Distance,
+			Message:   satellite.Message,
+			UpdatedAt: satellite.UpdatedAt,
+			Status:    satellite.Status,
+		})
+	}
+}
 
-		// Recuperar mensaje
-		message, err := calculos.GetMessage(messages[0], messages[1], messages[2])
+// @Summary Borra la lectura parcial de un satélite
+// @Description Elimina la distancia y mensaje guardados de un satélite, conservando su posición
+// @Description Si el servidor exige firmas, solo puede borrarla una conexión con el certificado de cliente del satélite o un administrador.
+// @Tags topsecret_split
+// @Produce json
+// @Param satellite_name path string true "Nombre del satélite"
+// @Success 204 "Lectura borrada"
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/topsecret_split/{satellite_name} [delete]
+func handleDeleteSatelliteReading(repo repository.RepositoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := repo.ClearSatellite(c.Request.Context(), c.Param("satellite_name"))
+		if errors.Is(err, repository.ErrSatelliteNotFound) {
+			abortProblem(c, CodeSatelliteNotFound, "Satellite not found")
+			return
+		}
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Could not decode message"})
+			abortProblem(c, CodeInternal, "Failed to clear satellite info")
 			return
 		}
 
-		response := TopSecretResponse{
-			Position: Position{
-				X: location.X,
-				Y: location.Y,
-			},
-			Message: message,
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// @Summary Borra todas las lecturas parciales
+// @Description Elimina la distancia y mensaje guardados de todos los satélites, conservando sus posiciones
+// @Tags topsecret_split
+// @Produce json
+// @Success 204 "Lecturas borradas"
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/topsecret_split [delete]
+func handleDeleteTopSecretSplit(repo repository.RepositoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := repo.ClearAllSatellites(c.Request.Context()); err != nil {
+			abortProblem(c, CodeInternal, "Failed to clear satellites info")
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// saveReading actualiza la distancia y el mensaje de un satélite conservando
+// su posición y estado, y añade la lectura a su historial. Si create es true y
+// el satélite no existe se crea con posición por defecto. Devuelve la versión
+// guardada.
+func saveReading(ctx context.Context, repo repository.RepositoryService, readings *history.Store, name string, create bool, ifMatch string, distance float32, message []string) (uint64, error) {
+	version, err := updateSatellite(ctx, repo, name, create, ifMatch, func(satellite *repository.Satellite) {
+		satellite.Distance = distance
+		satellite.Message = message
+	})
+	if err != nil {
+		return 0, err
+	}
+	readings.Record(history.Reading{Satellite: name, Distance: distance, Message: message})
+	return version, nil
+}
+
+// saveReadingProblem traduce un error de saveReading al problema que devuelve la API
+func saveReadingProblem(err error) Problem {
+	switch {
+	case errors.Is(err, repository.ErrSatelliteNotFound):
+		return newProblem(CodeSatelliteNotFound, "Satellite not found")
+	case errors.Is(err, errPreconditionFailed):
+		return newProblem(CodePreconditionFailed, "Satellite was modified by another request")
+	case errors.Is(err, repository.ErrVersionConflict):
+		return newProblem(CodeVersionConflict, "Satellite is being modified concurrently")
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return newProblem(CodeCanceled, "Request was canceled")
+	}
+	return newProblem(CodeInternal, "Failed to save satellite info")
+}
+
+// updateSatellite aplica update sobre un satélite mediante compare-and-swap,
+// de modo que una petición concurrente no pise la escritura. Si ifMatch no
+// está vacío la escritura solo procede sobre esa versión; si no, se reintenta
+// ante conflictos hasta maxSaveAttempts veces. Devuelve la versión guardada.
+func updateSatellite(ctx context.Context, repo repository.RepositoryService, name string, create bool, ifMatch string, update func(*repository.Satellite)) (uint64, error) {
+	for attempt := 0; attempt < maxSaveAttempts; attempt++ {
+		satellite, err := repo.GetSatellite(ctx, name)
+		if errors.Is(err, repository.ErrSatelliteNotFound) && create {
+			satellite, err = repository.Satellite{Name: name}, nil
+		}
+		if err != nil {
+			return 0, err
 		}
+		if ifMatch != "" && !etagMatches(ifMatch, satellite.Version) {
+			return 0, errPreconditionFailed
+		}
+
+		update(&satellite)
+		err = repo.SaveSatelliteIfVersion(ctx, satellite, satellite.Version)
+		if err == nil {
+			return satellite.Version + 1, nil
+		}
+		if !errors.Is(err, repository.ErrVersionConflict) {
+			return 0, err
+		}
+		if ifMatch != "" {
+			return 0, errPreconditionFailed
+		}
+	}
+	return 0, repository.ErrVersionConflict
+}
+
+// saveTopSecret guarda las lecturas del cuerpo de /topsecret y devuelve todos
+// los satélites para el cálculo. Si devuelve ok=false ya respondió con el error.
+func saveTopSecret(c *gin.Context, repo repository.RepositoryService, readings *history.Store) (satellites []repository.Satellite, ok bool) {
+	var request TopSecretRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondProblem(c, bindingProblem(err))
+		return nil, false
+	}
+
+	// Actualizar información de los satélites usando posición fija del repositorio
+	for _, sat := range request.Satellites {
+		// Si no existe se crea con posición por defecto (el repo ya carga las conocidas en New())
+		if _, err := saveReading(c.Request.Context(), repo, readings, sat.Name, true, "", sat.Distance, sat.Message); err != nil {
+			respondProblem(c, saveReadingProblem(err))
+			return nil, false
+		}
+	}
+
+	// Obtener todos los satélites para el cálculo
+	satellites, err := repo.GetAllSatellites(c.Request.Context())
+	if err != nil {
+		abortProblem(c, CodeInternal, "Failed to retrieve satellites")
+		return nil, false
+	}
+	return satellites, true
+}
+
+// respondLocation calcula posición y mensaje y escribe la respuesta. Si no se
+// puede calcular, el error incluye los satélites excluidos para saber por qué.
+func respondLocation(c *gin.Context, satellites []repository.Satellite, debug bool) {
+	response, diag, err := locateDiagnosed(satellites)
+	if !debug {
+		diag = nil
+	}
+	if err != nil {
+		problem := locateProblem(err, response.Skipped)
+		problem.Debug = diag
+		respondProblem(c, problem)
+		return
+	}
+	response.Debug = diag
+	c.JSON(http.StatusOK, response)
+}
+
+// debugRequested lee ?debug y comprueba que la petición tenga PermissionDebug.
+// Si devuelve ok=false ya respondió con el error.
+func debugRequested(c *gin.Context) (debug bool, ok bool) {
+	value := c.Query("debug")
+	if value == "" {
+		return false, true
+	}
+	debug, err := strconv.ParseBool(value)
+	if err != nil {
+		paramProblem(c, "query", "debug", "Invalid 'debug', expected true or false", "expected true or false")
+		return false, false
+	}
+	if debug && !hasPermission(c, PermissionDebug) {
+		abortProblem(c, CodeForbidden, "Debug diagnostics require the debug permission")
+		return false, false
+	}
+	return debug, true
+}
 
-		c.JSON(http.StatusOK, response)
+// locateErrorMessage traduce un error de locate al texto que devuelve la API
+func locateErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, errNotEnoughSatellites):
+		return "Not enough satellite data"
+	case errors.Is(err, errLocationUnavailable):
+		return "Could not determine location"
 	}
+	return "Could not decode message"
 }
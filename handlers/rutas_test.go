@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fuegodequasar/internal/platform/auth"
+	"fuegodequasar/internal/platform/history"
+	"fuegodequasar/internal/platform/repository"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouter crea un router con las rutas de SetupRoutes sobre repo, sin
+// autenticación ni firmas
+func newTestRouter(repo repository.RepositoryService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(AuditActor(), Authenticate(nil))
+	SetupRoutes(context.Background(), router, repo, history.NewStore(history.Retention{}), nil)
+	return router
+}
+
+// serve envía una petición al router con body como JSON y las cabeceras headers
+func serve(router http.Handler, method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// decodeProblem decodifica una respuesta application/problem+json
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) Problem {
+	t.Helper()
+	if contentType := w.Header().Get("Content-Type"); !strings.HasPrefix(contentType, problemContentType) {
+		t.Fatalf("Content-Type = %q, want %q: %s", contentType, problemContentType, w.Body)
+	}
+	var problem Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("decode problem: %v: %s", err, w.Body)
+	}
+	return problem
+}
+
+func TestRepositoryFaultProblem(t *testing.T) {
+	repo := repository.NewFaultInjector(repository.New(), repository.FaultConfig{
+		Rules: []repository.FaultRule{{ErrorRate: 1}},
+	})
+	router := newTestRouter(repo)
+	reading := `{"distance":100,"message":["este","","un"]}`
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"locate", http.MethodPost, "/topsecret", `{"satellites":[{"name":"kenobi","distance":100,"message":["este"]}]}`},
+		{"save reading", http.MethodPost, "/topsecret_split/kenobi", reading},
+		{"get reading", http.MethodGet, "/topsecret_split/kenobi", ""},
+		{"clear reading", http.MethodDelete, "/topsecret_split/kenobi", ""},
+		{"locate split", http.MethodGet, "/topsecret_split", ""},
+		{"clear readings", http.MethodDelete, "/topsecret_split", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, tt.method, tt.path, tt.body, nil)
+			if w.Code != http.StatusInternalServerError {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusInternalServerError, w.Body)
+			}
+			// El problema no filtra el error interno del repositorio
+			problem := decodeProblem(t, w)
+			if problem.Code != CodeInternal || strings.Contains(problem.Detail, "injected") {
+				t.Fatalf("problem = %+v, want %q without the repository error", problem, CodeInternal)
+			}
+		})
+	}
+}
+
+func TestSatelliteReadingRoutes(t *testing.T) {
+	repo := repository.New()
+	router := newTestRouter(repo)
+	for _, satellite := range []string{"kenobi", "sato"} {
+		if w := serve(router, http.MethodPost, "/topsecret_split/"+satellite, `{"distance":100,"message":["este","","un"]}`, nil); w.Code != http.StatusOK {
+			t.Fatalf("save %s: status = %d: %s", satellite, w.Code, w.Body)
+		}
+	}
+
+	// readingOf devuelve la lectura de un satélite tal como la sirve la API
+	readingOf := func(t *testing.T, satellite string) SatelliteReadingResponse {
+		t.Helper()
+		w := serve(router, http.MethodGet, "/topsecret_split/"+satellite, "", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("get %s: status = %d, want %d: %s", satellite, w.Code, http.StatusOK, w.Body)
+		}
+		var reading SatelliteReadingResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &reading); err != nil {
+			t.Fatalf("decode reading: %v", err)
+		}
+		return reading
+	}
+
+	t.Run("get", func(t *testing.T) {
+		reading := readingOf(t, "kenobi")
+		if reading.Name != "kenobi" || reading.Distance != 100 || !slices.Equal(reading.Message, []string{"este", "", "un"}) {
+			t.Fatalf("reading = %+v", reading)
+		}
+		if reading.Position != (Position{X: -500, Y: -200}) || reading.UpdatedAt.IsZero() {
+			t.Fatalf("reading = %+v, want kenobi's position and update time", reading)
+		}
+	})
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		want     int
+		wantCode ProblemCode
+	}{
+		{"get unknown satellite", http.MethodGet, "/topsecret_split/yoda", http.StatusNotFound, CodeSatelliteNotFound},
+		{"clear unknown satellite", http.MethodDelete, "/topsecret_split/yoda", http.StatusNotFound, CodeSatelliteNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, tt.method, tt.path, "", nil)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if problem := decodeProblem(t, w); problem.Code != tt.wantCode {
+				t.Fatalf("code = %q, want %q", problem.Code, tt.wantCode)
+			}
+		})
+	}
+
+	t.Run("clear", func(t *testing.T) {
+		if w := serve(router, http.MethodDelete, "/topsecret_split/kenobi", "", nil); w.Code != http.StatusNoContent || w.Body.Len() != 0 {
+			t.Fatalf("status = %d, want %d with no body: %s", w.Code, http.StatusNoContent, w.Body)
+		}
+		// Se borra la lectura pero el satélite conserva su posición fija
+		reading := readingOf(t, "kenobi")
+		if reading.Distance != 0 || len(reading.Message) != 0 || reading.Position != (Position{X: -500, Y: -200}) {
+			t.Fatalf("cleared reading = %+v", reading)
+		}
+		// Los demás satélites no se tocan
+		if reading := readingOf(t, "sato"); reading.Distance != 100 {
+			t.Fatalf("sato = %+v, want its reading kept", reading)
+		}
+	})
+
+	t.Run("clear all", func(t *testing.T) {
+		if w := serve(router, http.MethodDelete, "/topsecret_split", "", nil); w.Code != http.StatusNoContent {
+			t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusNoContent, w.Body)
+		}
+		for _, satellite := range repository.DefaultConstellation() {
+			reading := readingOf(t, satellite.Name)
+			want := Position{X: satellite.Position.X, Y: satellite.Position.Y}
+			if reading.Distance != 0 || len(reading.Message) != 0 || reading.Position != want {
+				t.Fatalf("%s after clearing all = %+v, want position %+v and no reading", satellite.Name, reading, want)
+			}
+		}
+	})
+}
+
+func TestDebugDiagnostics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keys, err := auth.ParseAPIKeys([]byte(`[` +
+		`{"id":"analyst","sha256":"` + auth.HashAPIKey("analyst-key") + `","roles":["analyst","station"]},` +
+		`{"id":"admin","sha256":"` + auth.HashAPIKey("admin-key") + `","roles":["admin"]}]`))
+	if err != nil {
+		t.Fatalf("ParseAPIKeys: %v", err)
+	}
+	item := transmission("", -100, 75.5)
+	body, _ := json.Marshal(TopSecretRequest{Satellites: item.Satellites})
+
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		key       string
+		token     string
+		want      int
+		wantCode  ProblemCode
+		wantDebug bool
+	}{
+		{"v1 split with token", http.MethodGet, "/api/v1/topsecret_split?debug=true", "analyst-key", "s3cret", http.StatusOK, "", true},
+		{"v1 post with token", http.MethodPost, "/api/v1/topsecret?debug=true", "analyst-key", "s3cret", http.StatusOK, "", true},
+		{"v2 split with token", http.MethodGet, "/api/v2/topsecret_split?debug=true", "analyst-key", "s3cret", http.StatusOK, "", true},
+		{"v2 post with token", http.MethodPost, "/api/v2/topsecret?debug=true", "analyst-key", "s3cret", http.StatusOK, "", true},
+		// Ningún rol concede el permiso de depuración por sí solo
+		{"v1 analyst without token", http.MethodGet, "/api/v1/topsecret_split?debug=true", "analyst-key", "", http.StatusForbidden, CodeForbidden, false},
+		{"v1 admin without token", http.MethodGet, "/api/v1/topsecret_split?debug=true", "admin-key", "", http.StatusForbidden, CodeForbidden, false},
+		{"v1 post without token", http.MethodPost, "/api/v1/topsecret?debug=true", "analyst-key", "", http.StatusForbidden, CodeForbidden, false},
+		{"v2 analyst without token", http.MethodGet, "/api/v2/topsecret_split?debug=true", "analyst-key", "", http.StatusForbidden, CodeForbidden, false},
+		{"v2 post without token", http.MethodPost, "/api/v2/topsecret?debug=true", "analyst-key", "", http.StatusForbidden, CodeForbidden, false},
+		{"wrong token", http.MethodGet, "/api/v1/topsecret_split?debug=true", "analyst-key", "guess", http.StatusForbidden, CodeForbidden, false},
+		{"debug false", http.MethodGet, "/api/v1/topsecret_split?debug=false", "analyst-key", "", http.StatusOK, "", false},
+		{"no debug", http.MethodGet, "/api/v2/topsecret_split", "analyst-key", "s3cret", http.StatusOK, "", false},
+		{"invalid debug", http.MethodGet, "/api/v1/topsecret_split?debug=maybe", "analyst-key", "s3cret", http.StatusBadRequest, CodeInvalidParameter, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.New()
+			for _, sat := range item.Satellites {
+				stored, _ := repo.GetSatellite(context.Background(), sat.Name)
+				stored.Distance, stored.Message = sat.Distance, sat.Message
+				if err := repo.SaveSatellite(context.Background(), stored); err != nil {
+					t.Fatalf("SaveSatellite: %v", err)
+				}
+			}
+			readings := history.NewStore(history.Retention{})
+			router := gin.New()
+			router.Use(AuditActor(), Authenticate(keys), DebugAccess("s3cret"))
+			SetupRoutes(context.Background(), router.Group("/api/v1"), repo, readings, nil)
+			SetupV2Routes(router.Group("/api/v2"), repo, readings, nil)
+
+			requestBody := ""
+			if tt.method == http.MethodPost {
+				requestBody = string(body)
+			}
+			headers := map[string]string{auth.APIKeyHeader: tt.key}
+			if tt.token != "" {
+				headers["X-Debug-Token"] = tt.token
+			}
+			w := serve(router, tt.method, tt.path, requestBody, headers)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if tt.wantCode != "" {
+				if problem := decodeProblem(t, w); problem.Code != tt.wantCode || problem.Debug != nil {
+					t.Fatalf("problem = %+v, want %q without diagnostics", problem, tt.wantCode)
+				}
+				// Sin permiso no se guarda ninguna lectura
+				if kenobi, _ := repo.GetSatellite(context.Background(), "kenobi"); kenobi.Version != 2 {
+					t.Fatalf("kenobi version = %d, want the request to save nothing", kenobi.Version)
+				}
+				return
+			}
+
+			var response struct {
+				Debug *LocateDiagnostics `json:"debug"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if (response.Debug != nil) != tt.wantDebug {
+				t.Fatalf("debug = %+v, want diagnostics %v", response.Debug, tt.wantDebug)
+			}
+			if !tt.wantDebug {
+				return
+			}
+			diag := response.Debug
+			if diag.Solver != "trilateration" || len(diag.Satellites) != 3 || len(diag.Merge) == 0 {
+				t.Fatalf("debug = %+v, want the trilateration of three satellites and the message merge", diag)
+			}
+			if math.Abs(diag.Estimate.X+100) > 0.01 || math.Abs(diag.Estimate.Y-75.5) > 0.01 {
+				t.Fatalf("debug estimate = %+v, want (-100, 75.5)", diag.Estimate)
+			}
+		})
+	}
+}
+
+func TestTopSecretSaveProblem(t *testing.T) {
+	body, _ := json.Marshal(TopSecretRequest{Satellites: transmission("", -100, 75.5).Satellites})
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		repo     repository.RepositoryService
+		ctx      context.Context
+		wantCode ProblemCode
+	}{
+		// El problema de guardar una lectura llega tal cual, como en el flujo dividido
+		{"version conflict", &conflictingRepository{RepositoryService: repository.New()}, context.Background(), CodeVersionConflict},
+		{"canceled", repository.New(), canceled, CodeCanceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newVersionedRouter(tt.repo)
+			for _, path := range []string{"/api/v1/topsecret", "/api/v2/topsecret"} {
+				req := httptest.NewRequestWithContext(tt.ctx, http.MethodPost, path, strings.NewReader(string(body)))
+				req.Header.Set("Content-Type", "application/json")
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				if want := problemCatalog[tt.wantCode].Status; w.Code != want {
+					t.Fatalf("%s status = %d, want %d: %s", path, w.Code, want, w.Body)
+				}
+				if problem := decodeProblem(t, w); problem.Code != tt.wantCode {
+					t.Fatalf("%s code = %q, want %q", path, problem.Code, tt.wantCode)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"errors"
+	"fuegodequasar/internal/platform/auth"
+	"fuegodequasar/internal/platform/history"
+	"fuegodequasar/internal/platform/repository"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PrecisePosition son coordenadas en doble precisión
+// @Description Coordenadas de la fuente en doble precisión
+type PrecisePosition struct {
+	X float64 `json:"x" example:"-487.2859125"`
+	Y float64 `json:"y" example:"1557.014225"`
+}
+
+// LocationV2 es la respuesta de localización de la API v2: posición en doble
+// precisión y la contribución de cada satélite usado
+// @Description Posición, mensaje y satélites que participaron en el cálculo
+type LocationV2 struct {
+	Position PrecisePosition `json:"position"`
+	Message  string          `json:"message" example:"este es un mensaje secreto"`
+	// Satellites son los satélites usados, en el orden en que entran al cálculo
+	Satellites []SatelliteContribution `json:"satellites"`
+	// Skipped lista los satélites que no participaron en el cálculo
+	Skipped []SkippedSatellite `json:"skipped,omitempty"`
+	// Debug es el diagnóstico del cálculo, solo con ?debug=true
+	Debug *LocateDiagnostics `json:"debug,omitempty"`
+}
+
+// SatelliteContribution es la participación de un satélite en el cálculo
+// @Description Satélite usado en el cálculo
+type SatelliteContribution struct {
+	Name   string  `json:"name" example:"kenobi"`
+	Weight float64 `json:"weight" example:"1"`
+	// Residual es |posición - satélite| - distancia
+	Residual float64 `json:"residual" example:"0.0003"`
+}
+
+// SetupV2Routes configura las rutas de la API v2. Solo incluye los recursos
+// cuya representación cambia respecto de v1; el resto se sirve en /api/v1.
+func SetupV2Routes(router gin.IRouter, repo repository.RepositoryService, readings *history.Store, signatures *auth.SignatureVerifier) {
+	// POST /topsecret
+	router.POST("/topsecret", RequireRole(unsignedReadingsRole(signatures)), limitBody(maxBodyBytes), handleTopSecretV2(repo, readings))
+	// GET /topsecret_split
+	router.GET("/topsecret_split", RequireRole(auth.RoleAnalyst), handleGetTopSecretSplitV2(repo))
+}
+
+// @Summary Decodifica mensaje y posición (v2)
+// @Description Igual que POST /v1/topsecret, pero con la posición en doble precisión y los satélites usados con su peso y residuo. A diferencia de v1, si la trilateración falla responde location_unavailable en lugar de la posición (0, 0).
+// @Tags v2
+// @Accept json
+// @Produce json
+// @Param request body TopSecretRequest true "Datos de los satélites"
+// @Param debug query bool false "Incluye el diagnóstico del cálculo; requiere permiso de depuración"
+// @Param X-Debug-Token header string false "Token que concede el permiso de depuración"
+// @Success 200 {object} LocationV2
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 413 {object} Problem
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v2/topsecret [post]
+func handleTopSecretV2(repo repository.RepositoryService, readings *history.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Comprobar el permiso antes de guardar nada
+		debug, ok := debugRequested(c)
+		if !ok {
+			return
+		}
+
+		satellites, ok := saveTopSecret(c, repo, readings)
+		if !ok {
+			return
+		}
+
+		respondLocationV2(c, satellites, debug)
+	}
+}
+
+// @Summary Decodifica mensaje y posición usando información parcial (v2)
+// @Description Igual que GET /v1/topsecret_split, con la representación de v2
+// @Tags v2
+// @Produce json
+// @Param debug query bool false "Incluye el diagnóstico del cálculo; requiere permiso de depuración"
+// @Param X-Debug-Token header string false "Token que concede el permiso de depuración"
+// @Success 200 {object} LocationV2
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v2/topsecret_split [get]
+func handleGetTopSecretSplitV2(repo repository.RepositoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		debug, ok := debugRequested(c)
+		if !ok {
+			return
+		}
+
+		satellites, err := repo.GetAllSatellites(c.Request.Context())
+		if err != nil {
+			abortProblem(c, CodeInternal, "Failed to retrieve satellites")
+			return
+		}
+
+		respondLocationV2(c, satellites, debug)
+	}
+}
+
+// respondLocationV2 calcula posición y mensaje y escribe la respuesta de v2
+func respondLocationV2(c *gin.Context, satellites []repository.Satellite, debug bool) {
+	response, diag, err := locateDiagnosed(satellites)
+	if err == nil && diag.SolverError != "" {
+		// v1 conserva la posición (0, 0) de la trilateración fallida; v2 la
+		// trata como lo que es, una posición que no se pudo calcular
+		err = errors.Join(errLocationUnavailable, errors.New(diag.SolverError))
+	}
+	if err != nil {
+		problem := locateProblem(err, response.Skipped)
+		if errors.Is(err, errLocationUnavailable) && problem.Residuals == nil && diag != nil {
+			for _, sat := range diag.Satellites {
+				if sat.Residual != nil {
+					problem.Residuals = append(problem.Residuals, *sat.Residual)
+				}
+			}
+		}
+		if debug {
+			problem.Debug = diag
+		}
+		respondProblem(c, problem)
+		return
+	}
+
+	location := LocationV2{
+		Position:   diag.Estimate,
+		Message:    response.Message,
+		Satellites: make([]SatelliteContribution, len(diag.Satellites)),
+		Skipped:    response.Skipped,
+	}
+	for i, sat := range diag.Satellites {
+		location.Satellites[i] = SatelliteContribution{Name: sat.Name, Weight: sat.Weight}
+		if sat.Residual != nil {
+			location.Satellites[i].Residual = *sat.Residual
+		}
+	}
+	if debug {
+		location.Debug = diag
+	}
+	c.JSON(http.StatusOK, location)
+}
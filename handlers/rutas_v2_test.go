@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fuegodequasar/internal/platform/history"
+	"fuegodequasar/internal/platform/repository"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	testDeprecatedAt = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	testSunsetAt     = time.Date(2027, time.April, 18, 0, 0, 0, 0, time.UTC)
+)
+
+// newVersionedRouter monta las rutas como el servidor: v1 bajo /api/v1 y como
+// alias obsoletos en la raíz, y v2 bajo /api/v2
+func newVersionedRouter(repo repository.RepositoryService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(AuditActor(), Authenticate(nil))
+	readings := history.NewStore(history.Retention{})
+	v1 := router.Group("/api/v1")
+	legacy := router.Group("/", Deprecated("/api/v1", testDeprecatedAt, testSunsetAt))
+	for _, group := range []gin.IRouter{v1, legacy} {
+		SetupRoutes(context.Background(), group, repo, readings, nil)
+	}
+	SetupV2Routes(router.Group("/api/v2"), repo, readings, nil)
+	return router
+}
+
+// saveTransmission guarda en repo las lecturas de una transmisión
+func saveTransmission(t *testing.T, repo repository.RepositoryService, item BatchItem) {
+	t.Helper()
+	for _, sat := range item.Satellites {
+		stored, _ := repo.GetSatellite(context.Background(), sat.Name)
+		stored.Distance, stored.Message = sat.Distance, sat.Message
+		if err := repo.SaveSatellite(context.Background(), stored); err != nil {
+			t.Fatalf("SaveSatellite: %v", err)
+		}
+	}
+}
+
+func TestDeprecatedRootAliases(t *testing.T) {
+	router := newVersionedRouter(repository.New())
+
+	tests := []struct {
+		name           string
+		path           string
+		wantDeprecated bool
+		wantLink       string
+	}{
+		{"root alias", "/topsecret_split/kenobi", true, `</api/v1/topsecret_split/kenobi>; rel="successor-version"`},
+		// También las respuestas de error de un alias lo marcan como obsoleto
+		{"root alias error", "/topsecret_split/yoda", true, `</api/v1/topsecret_split/yoda>; rel="successor-version"`},
+		{"root alias catalog", "/problems", true, `</api/v1/problems>; rel="successor-version"`},
+		{"v1", "/api/v1/topsecret_split/kenobi", false, ""},
+		{"v2", "/api/v2/topsecret_split", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodGet, tt.path, "", nil)
+			headers := map[string]string{
+				"Deprecation": "",
+				"Sunset":      "",
+				"Link":        tt.wantLink,
+			}
+			if tt.wantDeprecated {
+				headers["Deprecation"] = "@" + strconv.FormatInt(testDeprecatedAt.Unix(), 10)
+				headers["Sunset"] = "Sun, 18 Apr 2027 00:00:00 GMT"
+			}
+			for name, want := range headers {
+				if got := w.Header().Get(name); got != want {
+					t.Fatalf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRootAliasParity(t *testing.T) {
+	repo := repository.New()
+	saveTransmission(t, repo, transmission("", -100, 75.5))
+	router := newVersionedRouter(repo)
+
+	// normalize decodifica una respuesta JSON sin los campos que dependen de la
+	// ruta o de la petición
+	normalize := func(t *testing.T, body []byte) any {
+		t.Helper()
+		var value any
+		if err := json.Unmarshal(body, &value); err != nil {
+			t.Fatalf("decode %s: %v", body, err)
+		}
+		if object, ok := value.(map[string]any); ok {
+			delete(object, "instance")
+			delete(object, "request_id")
+		}
+		return value
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"locate split", http.MethodGet, "/topsecret_split", ""},
+		{"reading", http.MethodGet, "/topsecret_split/kenobi", ""},
+		{"unknown satellite", http.MethodGet, "/topsecret_split/yoda", ""},
+		{"invalid reading", http.MethodPost, "/topsecret_split/kenobi", `{"distance":-1,"message":["este"]}`},
+		{"catalog", http.MethodGet, "/problems", ""},
+		{"catalog entry", http.MethodGet, "/problems/not_enough_satellites", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v1 := serve(router, tt.method, "/api/v1"+tt.path, tt.body, nil)
+			alias := serve(router, tt.method, tt.path, tt.body, nil)
+			if v1.Code != alias.Code {
+				t.Fatalf("status = %d on v1 and %d on the alias", v1.Code, alias.Code)
+			}
+			if v1.Header().Get("Content-Type") != alias.Header().Get("Content-Type") || v1.Header().Get("ETag") != alias.Header().Get("ETag") {
+				t.Fatalf("headers = %v on v1 and %v on the alias", v1.Header(), alias.Header())
+			}
+			if got, want := normalize(t, alias.Body.Bytes()), normalize(t, v1.Body.Bytes()); !reflect.DeepEqual(got, want) {
+				t.Fatalf("alias body = %s, want %s", alias.Body, v1.Body)
+			}
+		})
+	}
+}
+
+func TestV2Representation(t *testing.T) {
+	item := transmission("", -100, 75.5)
+	body, _ := json.Marshal(TopSecretRequest{Satellites: item.Satellites})
+	router := newVersionedRouter(repository.New())
+
+	w := serve(router, http.MethodPost, "/api/v2/topsecret", string(body), nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	var location LocationV2
+	if err := json.Unmarshal(w.Body.Bytes(), &location); err != nil {
+		t.Fatalf("decode location: %v", err)
+	}
+	if math.Abs(location.Position.X+100) > 1e-3 || math.Abs(location.Position.Y-75.5) > 1e-3 || location.Message != "este es un mensaje" {
+		t.Fatalf("location = %+v, want (-100, 75.5) and the message", location)
+	}
+	if len(location.Satellites) != 3 {
+		t.Fatalf("satellites = %+v, want the three satellites used", location.Satellites)
+	}
+	for _, sat := range location.Satellites {
+		if sat.Weight != 1 || math.Abs(sat.Residual) > 0.01 {
+			t.Fatalf("satellite = %+v, want weight 1 and a residual near 0", sat)
+		}
+	}
+
+	// v2 solo sirve los recursos que cambian respecto de v1
+	if w := serve(router, http.MethodGet, "/api/v2/topsecret_split/kenobi", "", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("GET /api/v2/topsecret_split/kenobi = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestV2LocationUnavailable(t *testing.T) {
+	// Circunferencias que no se cortan: la trilateración exacta falla
+	satellites := []SatelliteInfo{
+		{Name: "kenobi", Distance: 1, Message: []string{"este", ""}},
+		{Name: "skywalker", Distance: 1, Message: []string{"", "es"}},
+		{Name: "sato", Distance: 1, Message: []string{"este", ""}},
+	}
+	body, _ := json.Marshal(TopSecretRequest{Satellites: satellites})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"post", http.MethodPost, "/topsecret", string(body)},
+		{"split", http.MethodGet, "/topsecret_split", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.New()
+			saveTransmission(t, repo, BatchItem{Satellites: satellites})
+			router := newVersionedRouter(repo)
+
+			// v1 conserva la posición (0, 0) de la trilateración fallida
+			v1 := serve(router, tt.method, "/api/v1"+tt.path, tt.body, nil)
+			if v1.Code != http.StatusOK {
+				t.Fatalf("v1 status = %d, want %d: %s", v1.Code, http.StatusOK, v1.Body)
+			}
+			var response TopSecretResponse
+			if err := json.Unmarshal(v1.Body.Bytes(), &response); err != nil || response.Position != (Position{}) {
+				t.Fatalf("v1 response = %s, want position (0, 0)", v1.Body)
+			}
+
+			// v2 responde que no se pudo calcular
+			v2 := serve(router, tt.method, "/api/v2"+tt.path, tt.body, nil)
+			if v2.Code != problemCatalog[CodeLocationUnavailable].Status {
+				t.Fatalf("v2 status = %d, want %d: %s", v2.Code, problemCatalog[CodeLocationUnavailable].Status, v2.Body)
+			}
+			if problem := decodeProblem(t, v2); problem.Code != CodeLocationUnavailable {
+				t.Fatalf("v2 code = %q, want %q", problem.Code, CodeLocationUnavailable)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fuegodequasar/internal/platform/repository"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// Parámetros del stream de eventos del flujo dividido
+const (
+	// streamHeartbeatInterval es cada cuánto se envía un heartbeat si no hay
+	// cambios en el stream que registra SetupRoutes
+	streamHeartbeatInterval = 15 * time.Second
+	// streamWriteTimeout es el plazo de cada escritura; sustituye al WriteTimeout
+	// del servidor, que cortaría cualquier stream a los pocos segundos
+	streamWriteTimeout = 10 * time.Second
+	// streamRetry es la espera en milisegundos que se indica al cliente antes de reconectar
+	streamRetry = 3000
+	// streamBuffer es el tamaño del buffer de la suscripción al repositorio
+	streamBuffer = 64
+)
+
+// Tipos de evento del stream
+const (
+	streamEventLocation  = "location"
+	streamEventWaiting   = "waiting"
+	streamEventHeartbeat = "heartbeat"
+)
+
+// StreamWaitingResponse es el evento que se envía mientras no se puede calcular
+// la posición y el mensaje
+// @Description Estado de espera del flujo dividido
+type StreamWaitingResponse struct {
+	Error string      `json:"error" example:"Not enough satellite data"`
+	Code  ProblemCode `json:"code" example:"not_enough_satellites"`
+	// WaitingFor son los satélites de los que todavía no hay lectura
+	WaitingFor []string           `json:"waiting_for" example:"sato"`
+	Skipped    []SkippedSatellite `json:"skipped,omitempty"`
+}
+
+// StreamHeartbeat es el evento que mantiene viva la conexión
+// @Description Heartbeat del stream
+type StreamHeartbeat struct {
+	Time time.Time `json:"time" example:"2024-01-01T12:00:00Z"`
+}
+
+// @Summary Stream de posición y mensaje del flujo dividido
+// @Description Mantiene abierta una conexión Server-Sent Events que envía el estado actual al conectar y lo recalcula cada vez que llega o se borra una lectura.
+// @Description Eventos: "location" con la posición y el mensaje (TopSecretResponse), "waiting" con los satélites de los que falta lectura (StreamWaitingResponse) y "heartbeat" cada 15 segundos (StreamHeartbeat).
+// @Description El id de cada evento es la secuencia del último cambio incluido; al reconectar con Last-Event-ID se reenvía el estado actual.
+// @Tags topsecret_split
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "Último id recibido, al reconectar"
+// @Success 200 {object} TopSecretResponse
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/stream/topsecret_split [get]
+func handleTopSecretSplitStream(streams context.Context, repo repository.RepositoryService, heartbeatInterval time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// El stream termina cuando se va el cliente o cuando se cancela streams
+		// al apagar; las demás peticiones no dependen de streams
+		ctx, cancel := context.WithCancel(c.Request.Context())
+		defer cancel()
+		stop := context.AfterFunc(streams, cancel)
+		defer stop()
+
+		// Suscribirse antes de leer el estado para no perder cambios intermedios
+		sub, err := repo.Subscribe(ctx, streamBuffer)
+		if err != nil {
+			abortProblem(c, CodeInternal, "Failed to subscribe to changes")
+			return
+		}
+		defer func() { sub.Close() }()
+
+		// Al reconectar se conserva el id que ya tenía el cliente hasta el siguiente cambio
+		var lastID string
+		if _, err := strconv.ParseUint(c.GetHeader("Last-Event-ID"), 10, 64); err == nil {
+			lastID = c.GetHeader("Last-Event-ID")
+		}
+
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+
+		stream := &splitStream{c: c, rc: http.NewResponseController(c.Writer), retry: streamRetry}
+		if !stream.sendState(repo, lastID) {
+			return
+		}
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-heartbeat.C:
+				if !stream.send(sse.Event{Event: streamEventHeartbeat, Data: StreamHeartbeat{Time: time.Now().UTC()}}) {
+					return
+				}
+			case change, ok := <-sub.C:
+				if !ok {
+					if !errors.Is(sub.Err(), repository.ErrSlowConsumer) {
+						return
+					}
+					// El repositorio nos dio de baja por lentos: volver a suscribirse y reenviar el estado
+					if sub, err = repo.Subscribe(ctx, streamBuffer); err != nil {
+						return
+					}
+					if !stream.sendState(repo, lastID) {
+						return
+					}
+					continue
+				}
+				// Agrupar los cambios pendientes en un único recálculo
+				seq := drainChanges(sub, change.Seq)
+				lastID = strconv.FormatUint(seq, 10)
+				if !stream.sendState(repo, lastID) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// drainChanges consume sin bloquear los cambios pendientes de la suscripción y
+// devuelve la secuencia del último
+func drainChanges(sub *repository.Subscription, seq uint64) uint64 {
+	for {
+		select {
+		case change, ok := <-sub.C:
+			if !ok {
+				return seq
+			}
+			seq = change.Seq
+		default:
+			return seq
+		}
+	}
+}
+
+// splitStream escribe los eventos de una conexión SSE
+type splitStream struct {
+	c     *gin.Context
+	rc    *http.ResponseController
+	retry uint
+}
+
+// sendState calcula el estado actual del flujo dividido y lo envía con el id indicado
+func (s *splitStream) sendState(repo repository.RepositoryService, id string) bool {
+	satellites, err := repo.GetAllSatellites(s.c.Request.Context())
+	if err != nil {
+		log.Printf("stream: failed to retrieve satellites: %v", err)
+		return false
+	}
+
+	response, waiting := splitState(satellites)
+	if response != nil {
+		return s.send(sse.Event{Event: streamEventLocation, Id: id, Data: response})
+	}
+	return s.send(sse.Event{Event: streamEventWaiting, Id: id, Data: waiting})
+}
+
+// splitState calcula el estado del flujo dividido: la posición y el mensaje si
+// se pueden calcular o, si no, de qué satélites falta lectura
+func splitState(satellites []repository.Satellite) (*TopSecretResponse, *StreamWaitingResponse) {
+	response, err := locate(satellites)
+	if err == nil {
+		return &response, nil
+	}
+
+	problem := locateProblem(err, response.Skipped)
+	return nil, &StreamWaitingResponse{
+		Error:      problem.Detail,
+		Code:       problem.Code,
+		WaitingFor: append([]string{}, problem.Missing...),
+		Skipped:    problem.Skipped,
+	}
+}
+
+// send escribe un evento con su propio plazo de escritura y lo vacía hacia el
+// cliente. Devuelve false si la conexión ya no sirve.
+func (s *splitStream) send(event sse.Event) bool {
+	err := s.rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return false
+	}
+	// La espera de reconexión solo se indica en el primer evento
+	event.Retry, s.retry = s.retry, 0
+	s.c.Render(-1, event)
+	if s.c.IsAborted() {
+		return false
+	}
+	return s.rc.Flush() == nil
+}
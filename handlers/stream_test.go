@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fuegodequasar/internal/platform/repository"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTopSecretSplitStreamEndsWithStreamsContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	streams, cancelStreams := context.WithCancel(context.Background())
+	defer cancelStreams()
+
+	router := gin.New()
+	router.GET("/stream", handleTopSecretSplitStream(streams, repository.New(), streamHeartbeatInterval))
+	router.GET("/problems", handleListProblems)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/stream")
+	if err != nil {
+		t.Fatalf("GET /stream: %v", err)
+	}
+	defer resp.Body.Close()
+	// El primer evento llega al conectar
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "id:") && !strings.HasPrefix(line, "event:") && !strings.HasPrefix(line, "retry:") {
+		t.Fatalf("first stream line = %q, %v", line, err)
+	}
+
+	cancelStreams()
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(io.Discard, resp.Body)
+		done <- err
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream still open after canceling the streams context")
+	}
+
+	// Cancelar los streams no afecta al resto de peticiones
+	problems, err := http.Get(server.URL + "/problems")
+	if err != nil {
+		t.Fatalf("GET /problems: %v", err)
+	}
+	problems.Body.Close()
+	if problems.StatusCode != http.StatusOK {
+		t.Fatalf("GET /problems status = %d, want %d", problems.StatusCode, http.StatusOK)
+	}
+}
+
+// streamEvent es un evento SSE tal como llega al cliente
+type streamEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+// openStream conecta con el stream de repo, enviando lastEventID si no está
+// vacío, y devuelve sus eventos según llegan
+func openStream(t *testing.T, repo repository.RepositoryService, heartbeat time.Duration, lastEventID string) <-chan streamEvent {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/stream", handleTopSecretSplitStream(context.Background(), repo, heartbeat))
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/stream", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /stream: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	events := make(chan streamEvent, 16)
+	go func() {
+		defer close(events)
+		var event streamEvent
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			field, value, _ := strings.Cut(scanner.Text(), ":")
+			switch field {
+			case "id":
+				event.id = value
+			case "event":
+				event.event = value
+			case "data":
+				event.data = value
+			case "":
+				events <- event
+				event = streamEvent{}
+			}
+		}
+	}()
+	return events
+}
+
+// nextEvent espera al siguiente evento del tipo kind, descartando los demás
+func nextEvent(t *testing.T, events <-chan streamEvent, kind string) streamEvent {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatalf("stream closed waiting for a %q event", kind)
+			}
+			if event.event == kind {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("no %q event", kind)
+		}
+	}
+}
+
+func TestTopSecretSplitStreamChanges(t *testing.T) {
+	repo := repository.New()
+	events := openStream(t, repo, time.Hour, "")
+
+	// Al conectar llega el estado actual, sin id porque aún no hubo cambios
+	first := <-events
+	var waiting StreamWaitingResponse
+	if err := json.Unmarshal([]byte(first.data), &waiting); err != nil {
+		t.Fatalf("decode %q: %v", first.data, err)
+	}
+	if first.event != streamEventWaiting || first.id != "" || waiting.Code != CodeNotEnoughSatellites ||
+		!slices.Equal(waiting.WaitingFor, []string{"kenobi", "sato", "skywalker"}) {
+		t.Fatalf("first event = %+v, want waiting for every satellite", first)
+	}
+
+	// Cuando llegan las tres lecturas se envía la posición con la secuencia del cambio
+	saveTransmission(t, repo, transmission("", -100, 75.5))
+	location := nextEvent(t, events, streamEventLocation)
+	var response TopSecretResponse
+	if err := json.Unmarshal([]byte(location.data), &response); err != nil {
+		t.Fatalf("decode %q: %v", location.data, err)
+	}
+	if math.Abs(float64(response.Position.X+100)) > 0.01 || math.Abs(float64(response.Position.Y-75.5)) > 0.01 || response.Message != "este es un mensaje" {
+		t.Fatalf("location = %+v, want (-100, 75.5)", response)
+	}
+	seq, err := strconv.ParseUint(location.id, 10, 64)
+	if err != nil || seq == 0 {
+		t.Fatalf("location id = %q, want the sequence of the change", location.id)
+	}
+
+	// Borrar una lectura vuelve a la espera de ese satélite
+	if err := repo.ClearSatellite(context.Background(), "sato"); err != nil {
+		t.Fatalf("ClearSatellite: %v", err)
+	}
+	cleared := nextEvent(t, events, streamEventWaiting)
+	if err := json.Unmarshal([]byte(cleared.data), &waiting); err != nil || !slices.Equal(waiting.WaitingFor, []string{"sato"}) {
+		t.Fatalf("event after clearing = %+v, want waiting for sato", cleared)
+	}
+	if next, err := strconv.ParseUint(cleared.id, 10, 64); err != nil || next <= seq {
+		t.Fatalf("id after clearing = %q, want it after %q", cleared.id, location.id)
+	}
+}
+
+func TestTopSecretSplitStreamResume(t *testing.T) {
+	tests := []struct {
+		name        string
+		lastEventID string
+		wantID      string
+	}{
+		{"no header", "", ""},
+		// Al reconectar se reenvía el estado actual con el id que ya tenía el cliente
+		{"last event id", "7", "7"},
+		{"invalid last event id", "seven", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := <-openStream(t, repository.New(), time.Hour, tt.lastEventID)
+			if first.event != streamEventWaiting || first.id != tt.wantID {
+				t.Fatalf("first event = %+v, want a waiting event with id %q", first, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestTopSecretSplitStreamHeartbeat(t *testing.T) {
+	start := time.Now()
+	heartbeat := nextEvent(t, openStream(t, repository.New(), 10*time.Millisecond, ""), streamEventHeartbeat)
+
+	var data StreamHeartbeat
+	if err := json.Unmarshal([]byte(heartbeat.data), &data); err != nil {
+		t.Fatalf("decode %q: %v", heartbeat.data, err)
+	}
+	if data.Time.Before(start.Add(-time.Second)) || heartbeat.id != "" {
+		t.Fatalf("heartbeat = %+v, want the current time and no id", heartbeat)
+	}
+}
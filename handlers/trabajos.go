@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fuegodequasar/internal/platform/auth"
+	"fuegodequasar/internal/platform/jobs"
+	"fuegodequasar/internal/platform/repository"
+	"net/http"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tipos de trabajo asíncrono
+const (
+	jobKindLocate = "locate"
+	jobKindBatch  = "batch"
+)
+
+// JobRequest es el cuerpo de POST /jobs: o bien Satellites con una sola
+// transmisión, o bien Items con un lote
+// @Description Trabajo de localización a encolar
+type JobRequest struct {
+	Satellites []SatelliteInfo `json:"satellites,omitempty" binding:"omitempty,satellites,dive"`
+	Items      []BatchItem     `json:"items,omitempty"`
+}
+
+// SetupJobRoutes registra los endpoints de trabajos asíncronos
+func SetupJobRoutes(router gin.IRouter, repo repository.RepositoryService, manager *jobs.Manager) {
+	router = router.Group("", RequireRole(auth.RoleAnalyst))
+	// POST /jobs
+	router.POST("/jobs", limitBody(maxBatchBodyBytes), handleSubmitJob(repo, manager))
+	// GET /jobs/{job_id}
+	router.GET("/jobs/:job_id", handleGetJob(manager))
+	// DELETE /jobs/{job_id}
+	router.DELETE("/jobs/:job_id", handleCancelJob(manager))
+}
+
+// @Summary Encola un trabajo de localización
+// @Description Encola el cálculo de una transmisión (satellites) o de un lote (items) para resolverlo en segundo plano, con las mismas reglas que /topsecret/batch: no se guardan lecturas. El resultado se consulta en GET /jobs/{job_id}: un TopSecretResponse para una transmisión o un BatchResponse para un lote.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param request body JobRequest true "Transmisión o lote a localizar"
+// @Success 202 {object} jobs.Job
+// @Header 202 {string} Location "URL del trabajo"
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 413 {object} Problem
+// @Failure 503 {object} Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/jobs [post]
+func handleSubmitJob(repo repository.RepositoryService, manager *jobs.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request JobRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			respondProblem(c, bindingProblem(err))
+			return
+		}
+		if (len(request.Satellites) == 0) == (len(request.Items) == 0) {
+			abortProblem(c, CodeInvalidRequest, "Exactly one of 'satellites' or 'items' is required")
+			return
+		}
+		if len(request.Items) > maxBatchItems {
+			abortProblem(c, CodeBatchTooLarge, "Too many items in batch, the maximum is 10000")
+			return
+		}
+
+		kind, fn := jobKindBatch, batchJob(repo, request.Items)
+		if len(request.Satellites) > 0 {
+			kind, fn = jobKindLocate, locateJob(repo, request.Satellites)
+		}
+		job, err := manager.Submit(kind, fn)
+		switch {
+		case errors.Is(err, jobs.ErrQueueFull):
+			c.Header("Retry-After", "1")
+			abortProblem(c, CodeQueueFull, "Job queue is full")
+			return
+		case errors.Is(err, jobs.ErrShuttingDown):
+			abortProblem(c, CodeShuttingDown, "Server is shutting down")
+			return
+		case err != nil:
+			abortProblem(c, CodeInternal, "Failed to submit job")
+			return
+		}
+
+		c.Header("Location", c.Request.URL.Path+"/"+job.ID)
+		c.JSON(http.StatusAccepted, job)
+	}
+}
+
+// @Summary Consulta un trabajo
+// @Description Devuelve el estado del trabajo y, cuando termina, su resultado o su error. Un trabajo fallido incluye en problem el mismo Problem que respondería el endpoint síncrono. Los trabajos terminados se conservan durante el tiempo de retención configurado.
+// @Tags jobs
+// @Produce json
+// @Param job_id path string true "ID del trabajo"
+// @Success 200 {object} jobs.Job
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/jobs/{job_id} [get]
+func handleGetJob(manager *jobs.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := manager.Get(c.Param("job_id"))
+		if err != nil {
+			abortProblem(c, CodeJobNotFound, "Job not found")
+			return
+		}
+		c.JSON(http.StatusOK, job)
+	}
+}
+
+// @Summary Cancela un trabajo
+// @Description Cancela un trabajo en cola o en curso. Un trabajo en curso pasa a cancelado en cuanto el cálculo atiende la cancelación.
+// @Tags jobs
+// @Produce json
+// @Param job_id path string true "ID del trabajo"
+// @Success 202 {object} jobs.Job
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/jobs/{job_id} [delete]
+func handleCancelJob(manager *jobs.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := manager.Cancel(c.Param("job_id"))
+		switch {
+		case errors.Is(err, jobs.ErrJobNotFound):
+			abortProblem(c, CodeJobNotFound, "Job not found")
+			return
+		case errors.Is(err, jobs.ErrJobFinished):
+			abortProblem(c, CodeJobFinished, "Job already finished")
+			return
+		}
+		c.JSON(http.StatusAccepted, job)
+	}
+}
+
+// locateJob resuelve una transmisión igual que un elemento de un lote
+func locateJob(repo repository.RepositoryService, satellites []SatelliteInfo) jobs.Func {
+	return func(ctx context.Context) (any, error) {
+		constellation, err := repo.GetAllSatellites(ctx)
+		if err != nil {
+			return nil, jobFailure(newProblem(CodeInternal, "Failed to retrieve satellites"))
+		}
+		result := locateBatchItem(ctx, constellation, BatchItem{Satellites: satellites}, 0)
+		if result.Problem != nil {
+			return nil, jobFailure(*result.Problem)
+		}
+		return result.Result, nil
+	}
+}
+
+// batchJob resuelve un lote completo
+func batchJob(repo repository.RepositoryService, items []BatchItem) jobs.Func {
+	return func(ctx context.Context) (any, error) {
+		constellation, err := repo.GetAllSatellites(ctx)
+		if err != nil {
+			return nil, jobFailure(newProblem(CodeInternal, "Failed to retrieve satellites"))
+		}
+		response := locateBatch(ctx, constellation, items, runtime.GOMAXPROCS(0))
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return response, nil
+	}
+}
+
+// jobFailure convierte un problema en el error de un trabajo, para que quien
+// consulta el trabajo reciba el mismo problema que daría el endpoint síncrono
+func jobFailure(problem Problem) error {
+	return &jobs.Failure{Message: problem.Detail, Problem: problem}
+}
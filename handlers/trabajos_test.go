@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fuegodequasar/internal/platform/jobs"
+	"fuegodequasar/internal/platform/repository"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// polledJob es un trabajo tal como lo recibe quien lo consulta
+type polledJob struct {
+	jobs.Job
+	Problem *Problem `json:"problem"`
+}
+
+// waitJob consulta el trabajo hasta que llega a un estado final
+func waitJob(t *testing.T, router http.Handler, location string) polledJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		w := serve(router, http.MethodGet, location, "", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d: %s", location, w.Code, w.Body)
+		}
+		var job polledJob
+		if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+			t.Fatalf("decode job: %v: %s", err, w.Body)
+		}
+		if job.Status.Finished() {
+			return job
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", location)
+	return polledJob{}
+}
+
+func TestJobProblem(t *testing.T) {
+	manager := jobs.NewManager(jobs.Config{})
+	t.Cleanup(func() { manager.Shutdown(context.Background()) })
+	failing := repository.NewFaultInjector(repository.New(), repository.FaultConfig{
+		Rules: []repository.FaultRule{{ErrorRate: 1}},
+	})
+	transmission := `{"satellites":[{"name":"kenobi","distance":100,"message":["este"]}]}`
+
+	tests := []struct {
+		name     string
+		repo     repository.RepositoryService
+		body     string
+		wantCode ProblemCode
+	}{
+		{"not enough satellites", repository.New(), transmission, CodeNotEnoughSatellites},
+		{"repository failure", failing, transmission, CodeInternal},
+		{"batch repository failure", failing, `{"items":[{}]}`, CodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(AuditActor(), Authenticate(nil))
+			SetupJobRoutes(router, tt.repo, manager)
+
+			w := serve(router, http.MethodPost, "/jobs", tt.body, nil)
+			if w.Code != http.StatusAccepted {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusAccepted, w.Body)
+			}
+
+			job := waitJob(t, router, w.Header().Get("Location"))
+			if job.Status != jobs.StatusFailed || job.Problem == nil {
+				t.Fatalf("job = %+v, want failed with a problem", job)
+			}
+			// El trabajo conserva el problema del catálogo, no solo su detalle
+			want := newProblem(tt.wantCode, job.Problem.Detail)
+			if job.Problem.Code != tt.wantCode || job.Problem.Status != want.Status || job.Problem.Type != want.Type || job.Problem.Title != want.Title {
+				t.Fatalf("problem = %+v, want %+v", job.Problem, want)
+			}
+			if job.Error != job.Problem.Detail {
+				t.Fatalf("error = %q, want the problem detail %q", job.Error, job.Problem.Detail)
+			}
+			if tt.wantCode == CodeNotEnoughSatellites && !slices.Equal(job.Problem.Missing, []string{"sato", "skywalker"}) {
+				t.Fatalf("missing = %v, want the satellites without a reading", job.Problem.Missing)
+			}
+		})
+	}
+}
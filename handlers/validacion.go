@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// ValidationLimits son los límites configurables de las lecturas recibidas
+type ValidationLimits struct {
+	// MaxWords es el número máximo de palabras de un mensaje
+	MaxWords int
+	// MaxSatellites es el número máximo de satélites de una transmisión
+	MaxSatellites int
+	// MaxDistance es la distancia máxima admitida
+	MaxDistance float64
+}
+
+// DefaultValidationLimits son los límites que se aplican si no se configuran otros
+var DefaultValidationLimits = ValidationLimits{MaxWords: 100, MaxSatellites: 16, MaxDistance: 1e6}
+
+// validationLimits son los límites en vigor
+var validationLimits = DefaultValidationLimits
+
+// SetValidationLimits cambia los límites de validación. Debe llamarse antes de
+// empezar a servir peticiones.
+func SetValidationLimits(limits ValidationLimits) {
+	validationLimits = limits
+}
+
+// Etiquetas de validación propias, que dependen de los límites configurados
+const (
+	tagDistance   = "distance"
+	tagWords      = "words"
+	tagSatellites = "satellites"
+	tagUnique     = "unique"
+)
+
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		panic("handlers: unexpected validator engine")
+	}
+	// Las rutas de los errores usan los nombres JSON de los campos
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	mustRegisterValidation(v, tagDistance, func(fl validator.FieldLevel) bool {
+		distance := fl.Field().Float()
+		return !math.IsNaN(distance) && !math.IsInf(distance, 0) && distance > 0 && distance <= validationLimits.MaxDistance
+	})
+	mustRegisterValidation(v, tagWords, func(fl validator.FieldLevel) bool {
+		words := fl.Field().Len()
+		return words > 0 && words <= validationLimits.MaxWords
+	})
+	mustRegisterValidation(v, tagSatellites, func(fl validator.FieldLevel) bool {
+		satellites := fl.Field().Len()
+		return satellites > 0 && satellites <= validationLimits.MaxSatellites
+	})
+	// Los nombres repetidos se comprueban a nivel de struct para señalar cada
+	// satélite repetido sin dejar de validar el resto de campos
+	v.RegisterStructValidation(func(sl validator.StructLevel) {
+		uniqueSatelliteNames(sl, sl.Current().Interface().(TopSecretRequest).Satellites)
+	}, TopSecretRequest{})
+	v.RegisterStructValidation(func(sl validator.StructLevel) {
+		uniqueSatelliteNames(sl, sl.Current().Interface().(BatchItem).Satellites)
+	}, BatchItem{})
+	v.RegisterStructValidation(func(sl validator.StructLevel) {
+		uniqueSatelliteNames(sl, sl.Current().Interface().(JobRequest).Satellites)
+	}, JobRequest{})
+}
+
+// mustRegisterValidation registra una etiqueta de validación propia. Si no se
+// pudiera registrar, los campos con la etiqueta dejarían de validarse sin
+// avisar, así que se aborta el arranque.
+func mustRegisterValidation(v *validator.Validate, tag string, fn validator.Func) {
+	if err := v.RegisterValidation(tag, fn); err != nil {
+		panic(fmt.Sprintf("handlers: failed to register validation %q: %v", tag, err))
+	}
+}
+
+// uniqueSatelliteNames señala los satélites cuyo nombre ya apareció antes en
+// la misma transmisión
+func uniqueSatelliteNames(sl validator.StructLevel, satellites []SatelliteInfo) {
+	seen := make(map[string]bool, len(satellites))
+	for i, sat := range satellites {
+		if sat.Name == "" {
+			continue
+		}
+		if seen[sat.Name] {
+			field := fmt.Sprintf("satellites[%d].name", i)
+			sl.ReportError(sat.Name, field, field, tagUnique, "")
+		}
+		seen[sat.Name] = true
+	}
+}
+
+// validate aplica las reglas declaradas en las etiquetas binding de obj, igual
+// que ShouldBindJSON. Lo usan los canales que no pasan por gin.
+func validate(obj any) error {
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// fieldErrors traduce los errores del validador a errores por campo
+func fieldErrors(errs validator.ValidationErrors) []FieldError {
+	result := make([]FieldError, len(errs))
+	for i, err := range errs {
+		result[i] = FieldError{Field: fieldPath(err.Namespace()), In: "body", Message: validationMessage(err)}
+	}
+	return result
+}
+
+// fieldPath quita de la ruta del validador el nombre del tipo raíz, de modo que
+// TopSecretRequest.satellites[0].distance queda en satellites[0].distance
+func fieldPath(namespace string) string {
+	_, path, found := strings.Cut(namespace, ".")
+	if !found {
+		return namespace
+	}
+	return path
+}
+
+// validationMessage describe la regla que incumple el campo
+func validationMessage(err validator.FieldError) string {
+	switch err.Tag() {
+	case "required":
+		return "is required"
+	case "max":
+		return fmt.Sprintf("must be at most %s characters", err.Param())
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(err.Param(), " ", ", ")
+	case tagUnique:
+		return "is repeated in the same request"
+	case tagDistance:
+		return fmt.Sprintf("must be a finite number greater than 0 and at most %g", validationLimits.MaxDistance)
+	case tagWords:
+		return fmt.Sprintf("must have between 1 and %d words", validationLimits.MaxWords)
+	case tagSatellites:
+		return fmt.Sprintf("must have between 1 and %d satellites", validationLimits.MaxSatellites)
+	}
+	return "does not satisfy " + err.Tag()
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"fuegodequasar/internal/platform/repository"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestMustRegisterValidation(t *testing.T) {
+	accept := func(validator.FieldLevel) bool { return true }
+	tests := []struct {
+		name      string
+		tag       string
+		wantPanic bool
+	}{
+		{"valid tag", "custom", false},
+		{"empty tag", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if panicked := recover() != nil; panicked != tt.wantPanic {
+					t.Fatalf("panicked = %v, want %v", panicked, tt.wantPanic)
+				}
+			}()
+			mustRegisterValidation(validator.New(), tt.tag, accept)
+		})
+	}
+}
+
+func TestTopSecretValidation(t *testing.T) {
+	router := newTestRouter(repository.New())
+	distanceMessage := "must be a finite number greater than 0 and at most 1e+06"
+
+	tests := []struct {
+		name       string
+		body       string
+		want       int
+		wantCode   ProblemCode
+		wantErrors []FieldError
+	}{
+		{"every field of a satellite", `{"satellites":[{"name":"","distance":-1,"message":[]}]}`, http.StatusBadRequest, CodeValidationFailed, []FieldError{
+			{Field: "satellites[0].name", In: "body", Message: "is required"},
+			{Field: "satellites[0].distance", In: "body", Message: distanceMessage},
+			{Field: "satellites[0].message", In: "body", Message: "must have between 1 and 100 words"},
+		}},
+		{"fields of several satellites", `{"satellites":[{"name":"kenobi","distance":0,"message":["este"]},{"name":"sato","distance":100}]}`, http.StatusBadRequest, CodeValidationFailed, []FieldError{
+			{Field: "satellites[0].distance", In: "body", Message: distanceMessage},
+			{Field: "satellites[1].message", In: "body", Message: "must have between 1 and 100 words"},
+		}},
+		// Se señala cada repetición, sin ocultar los demás errores
+		{"repeated names", `{"satellites":[{"name":"kenobi","distance":100,"message":["este"]},{"name":"kenobi","distance":100,"message":["este"]},{"name":"kenobi","distance":-1,"message":["este"]}]}`, http.StatusBadRequest, CodeValidationFailed, []FieldError{
+			{Field: "satellites[2].distance", In: "body", Message: distanceMessage},
+			{Field: "satellites[1].name", In: "body", Message: "is repeated in the same request"},
+			{Field: "satellites[2].name", In: "body", Message: "is repeated in the same request"},
+		}},
+		{"no satellites", `{"satellites":[]}`, http.StatusBadRequest, CodeValidationFailed, []FieldError{
+			{Field: "satellites", In: "body", Message: "must have between 1 and 16 satellites"},
+		}},
+		{"long name", `{"satellites":[{"name":"` + strings.Repeat("k", 65) + `","distance":100,"message":["este"]}]}`, http.StatusBadRequest, CodeValidationFailed, []FieldError{
+			{Field: "satellites[0].name", In: "body", Message: "must be at most 64 characters"},
+		}},
+		// JSON no admite NaN ni infinitos; un número fuera de rango no llega a validarse
+		{"distance out of range", `{"satellites":[{"name":"kenobi","distance":1e39,"message":["este"]}]}`, http.StatusBadRequest, CodeInvalidRequest, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodPost, "/topsecret", tt.body, nil)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			problem := decodeProblem(t, w)
+			if problem.Code != tt.wantCode {
+				t.Fatalf("code = %q, want %q", problem.Code, tt.wantCode)
+			}
+			if tt.wantErrors != nil && !slices.Equal(problem.Errors, tt.wantErrors) {
+				t.Fatalf("errors = %+v, want %+v", problem.Errors, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestValidationLimits(t *testing.T) {
+	SetValidationLimits(ValidationLimits{MaxWords: 2, MaxSatellites: 2, MaxDistance: 50})
+	t.Cleanup(func() { SetValidationLimits(DefaultValidationLimits) })
+	router := newTestRouter(repository.New())
+
+	tests := []struct {
+		name      string
+		path      string
+		body      string
+		want      int
+		wantError FieldError
+	}{
+		{"within limits", "/topsecret_split/kenobi", `{"distance":50,"message":["este","es"]}`, http.StatusOK, FieldError{}},
+		{"distance", "/topsecret_split/kenobi", `{"distance":50.5,"message":["este"]}`, http.StatusBadRequest,
+			FieldError{Field: "distance", In: "body", Message: "must be a finite number greater than 0 and at most 50"}},
+		{"words", "/topsecret_split/kenobi", `{"distance":10,"message":["este","es","un"]}`, http.StatusBadRequest,
+			FieldError{Field: "message", In: "body", Message: "must have between 1 and 2 words"}},
+		{"satellites", "/topsecret", `{"satellites":[{"name":"a","distance":1,"message":["x"]},{"name":"b","distance":1,"message":["x"]},{"name":"c","distance":1,"message":["x"]}]}`, http.StatusBadRequest,
+			FieldError{Field: "satellites", In: "body", Message: "must have between 1 and 2 satellites"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodPost, tt.path, tt.body, nil)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if tt.want == http.StatusOK {
+				return
+			}
+			if problem := decodeProblem(t, w); len(problem.Errors) != 1 || problem.Errors[0] != tt.wantError {
+				t.Fatalf("errors = %+v, want [%+v]", problem.Errors, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestValidateDistanceNotFinite(t *testing.T) {
+	// Por gRPC sí pueden llegar NaN e infinitos
+	tests := []struct {
+		name     string
+		distance float32
+	}{
+		{"nan", float32(math.NaN())},
+		{"positive infinity", float32(math.Inf(1))},
+		{"negative infinity", float32(math.Inf(-1))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate(TopSecretSplitRequest{Distance: tt.distance, Message: []string{"este"}})
+			var errs validator.ValidationErrors
+			if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Tag() != tagDistance {
+				t.Fatalf("validate() = %v, want a %q error", err, tagDistance)
+			}
+		})
+	}
+}
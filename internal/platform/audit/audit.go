@@ -0,0 +1,154 @@
+// Package audit guarda un registro de solo anexado con cada cambio sobre los
+// satélites del repositorio: quién lo hizo, desde dónde, en qué petición y
+// cuáles eran los valores anterior y nuevo.
+package audit
+
+import (
+	"context"
+	"fuegodequasar/internal/platform/repository"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Acciones registradas
+const (
+	ActionSave    = "save"
+	ActionClear   = "clear"
+	ActionRestore = "restore"
+)
+
+// Actor identifica al origen de un cambio
+type Actor struct {
+	ID        string `json:"id"`
+	ClientIP  string `json:"client_ip"`
+	RequestID string `json:"request_id"`
+}
+
+type actorKey struct{}
+
+// WithActor devuelve un contexto que lleva el actor de la petición
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom recupera el actor del contexto; si no hay ninguno devuelve el actor vacío
+func ActorFrom(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorKey{}).(Actor)
+	return actor
+}
+
+// Entry es un cambio registrado. Old es nil cuando el satélite no existía y
+// New es nil cuando dejó de existir.
+type Entry struct {
+	ID        uint64                `json:"id"`
+	Time      time.Time             `json:"time"`
+	Actor     Actor                 `json:"actor"`
+	Action    string                `json:"action"`
+	Satellite string                `json:"satellite"`
+	Old       *repository.Satellite `json:"old"`
+	New       *repository.Satellite `json:"new"`
+}
+
+// Filter restringe una consulta al registro. Los campos vacíos no filtran.
+type Filter struct {
+	// From y To acotan el intervalo [From, To) de Entry.Time
+	From      time.Time
+	To        time.Time
+	Satellite string
+	// Limit limita el número de entradas devueltas, las más recientes; 0 no limita
+	Limit int
+}
+
+// Retention limita cuántas entradas se conservan. Los valores cero no limitan.
+type Retention struct {
+	// MaxAge descarta las entradas más antiguas que este intervalo
+	MaxAge time.Duration
+	// MaxEntries conserva solo las entradas más recientes
+	MaxEntries int
+}
+
+// Log es un registro en memoria de solo anexado, con retención
+type Log struct {
+	retention Retention
+	now       func() time.Time
+
+	mutex   sync.RWMutex
+	entries []Entry
+	nextID  uint64
+}
+
+// NewLog crea un registro vacío con la retención indicada
+func NewLog(retention Retention) *Log {
+	return &Log{retention: retention, now: time.Now, nextID: 1}
+}
+
+// Append añade una entrada asignándole identificador y, si no lo trae, momento
+func (l *Log) Append(entry Entry) Entry {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	entry.ID = l.nextID
+	l.nextID++
+	if entry.Time.IsZero() {
+		entry.Time = l.now().UTC()
+	}
+	l.entries = append(l.entries, entry)
+	l.prune()
+	return entry
+}
+
+// prune aplica la retención; requiere el mutex tomado. Las entradas se anexan
+// en orden cronológico, así que las que sobran están siempre al principio.
+func (l *Log) prune() {
+	start := 0
+	if l.retention.MaxAge > 0 {
+		cutoff := l.now().Add(-l.retention.MaxAge)
+		for start < len(l.entries) && l.entries[start].Time.Before(cutoff) {
+			start++
+		}
+	}
+	if limit := l.retention.MaxEntries; limit > 0 && len(l.entries)-start > limit {
+		start = len(l.entries) - limit
+	}
+	if start == 0 {
+		return
+	}
+	l.entries = l.entries[start:]
+	// Copiar si se ha descartado mucho para no retener el array original
+	if cap(l.entries) > 2*len(l.entries)+16 {
+		l.entries = slices.Clone(l.entries)
+	}
+}
+
+// Query devuelve en orden cronológico las entradas que cumplen el filtro
+func (l *Log) Query(filter Filter) []Entry {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	var cutoff time.Time
+	if l.retention.MaxAge > 0 {
+		cutoff = l.now().Add(-l.retention.MaxAge)
+	}
+	result := make([]Entry, 0)
+	for _, entry := range l.entries {
+		// Las entradas caducadas no se devuelven aunque aún no se hayan purgado
+		if entry.Time.Before(cutoff) {
+			continue
+		}
+		if !filter.From.IsZero() && entry.Time.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !entry.Time.Before(filter.To) {
+			continue
+		}
+		if filter.Satellite != "" && entry.Satellite != filter.Satellite {
+			continue
+		}
+		result = append(result, entry)
+	}
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[len(result)-filter.Limit:]
+	}
+	return result
+}
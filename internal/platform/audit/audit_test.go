@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLogRetention(t *testing.T) {
+	start := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		retention Retention
+		// appended es el número de entradas, una por minuto desde start
+		appended int
+		wantIDs  []uint64
+	}{
+		{"unlimited", Retention{}, 4, []uint64{1, 2, 3, 4}},
+		{"max entries", Retention{MaxEntries: 2}, 4, []uint64{3, 4}},
+		{"max age", Retention{MaxAge: 90 * time.Second}, 4, []uint64{3, 4}},
+		{"both limits", Retention{MaxAge: 150 * time.Second, MaxEntries: 1}, 4, []uint64{4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := NewLog(tt.retention)
+			var now time.Time
+			log.now = func() time.Time { return now }
+			for i := range tt.appended {
+				now = start.Add(time.Duration(i) * time.Minute)
+				log.Append(Entry{Action: ActionSave, Satellite: "kenobi"})
+			}
+
+			entries := log.Query(Filter{})
+			if len(entries) != len(tt.wantIDs) {
+				t.Fatalf("Query() returned %d entries, want %d", len(entries), len(tt.wantIDs))
+			}
+			for i, entry := range entries {
+				if entry.ID != tt.wantIDs[i] {
+					t.Fatalf("entry %d has ID %d, want %d", i, entry.ID, tt.wantIDs[i])
+				}
+			}
+			if len(log.entries) != len(tt.wantIDs) {
+				t.Fatalf("log keeps %d entries, want %d", len(log.entries), len(tt.wantIDs))
+			}
+		})
+	}
+}
+
+func TestLogQuery(t *testing.T) {
+	start := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	log := NewLog(Retention{})
+	for i, satellite := range []string{"kenobi", "skywalker", "kenobi", "sato"} {
+		log.Append(Entry{Time: start.Add(time.Duration(i) * time.Minute), Action: ActionSave, Satellite: satellite})
+	}
+
+	tests := []struct {
+		name    string
+		filter  Filter
+		wantIDs []uint64
+	}{
+		{"all", Filter{}, []uint64{1, 2, 3, 4}},
+		{"satellite", Filter{Satellite: "kenobi"}, []uint64{1, 3}},
+		{"from", Filter{From: start.Add(2 * time.Minute)}, []uint64{3, 4}},
+		{"to excluded", Filter{To: start.Add(2 * time.Minute)}, []uint64{1, 2}},
+		{"limit keeps the latest", Filter{Limit: 2}, []uint64{3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := log.Query(tt.filter)
+			if len(entries) != len(tt.wantIDs) {
+				t.Fatalf("Query() returned %d entries, want %d", len(entries), len(tt.wantIDs))
+			}
+			for i, entry := range entries {
+				if entry.ID != tt.wantIDs[i] {
+					t.Fatalf("entry %d has ID %d, want %d", i, entry.ID, tt.wantIDs[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fuegodequasar/internal/platform/repository"
+	"sync"
+)
+
+// Repository envuelve un RepositoryService y anota en el registro cada
+// mutación que termina sin error, con el actor que viaja en el contexto.
+type Repository struct {
+	inner repository.RepositoryService
+	log   *Log
+
+	// writes serializa las mutaciones para que los valores anterior y nuevo
+	// de cada entrada no incluyan escrituras de otras peticiones
+	writes sync.Mutex
+}
+
+// NewRepository crea el decorador de auditoría sobre inner
+func NewRepository(inner repository.RepositoryService, log *Log) *Repository {
+	return &Repository{inner: inner, log: log}
+}
+
+func (r *Repository) GetSatellite(ctx context.Context, name string) (repository.Satellite, error) {
+	return r.inner.GetSatellite(ctx, name)
+}
+
+func (r *Repository) GetAllSatellites(ctx context.Context) ([]repository.Satellite, error) {
+	return r.inner.GetAllSatellites(ctx)
+}
+
+func (r *Repository) Subscribe(ctx context.Context, buffer int) (*repository.Subscription, error) {
+	return r.inner.Subscribe(ctx, buffer)
+}
+
+func (r *Repository) SaveSatellite(ctx context.Context, satellite repository.Satellite) error {
+	return r.record(ctx, ActionSave, satellite.Name, func() error {
+		return r.inner.SaveSatellite(ctx, satellite)
+	})
+}
+
+func (r *Repository) SaveSatelliteIfVersion(ctx context.Context, satellite repository.Satellite, version uint64) error {
+	return r.record(ctx, ActionSave, satellite.Name, func() error {
+		return r.inner.SaveSatelliteIfVersion(ctx, satellite, version)
+	})
+}
+
+func (r *Repository) ClearSatellite(ctx context.Context, name string) error {
+	return r.record(ctx, ActionClear, name, func() error {
+		return r.inner.ClearSatellite(ctx, name)
+	})
+}
+
+func (r *Repository) ClearAllSatellites(ctx context.Context) error {
+	return r.recordAll(ctx, ActionClear, func() error {
+		return r.inner.ClearAllSatellites(ctx)
+	})
+}
+
+func (r *Repository) ReplaceAllSatellites(ctx context.Context, satellites []repository.Satellite) error {
+	return r.recordAll(ctx, ActionRestore, func() error {
+		return r.inner.ReplaceAllSatellites(ctx, satellites)
+	})
+}
+
+// recordAll ejecuta una mutación sobre toda la colección y anota una entrada
+// por cada satélite que existía antes o existe después
+func (r *Repository) recordAll(ctx context.Context, action string, mutate func() error) error {
+	r.writes.Lock()
+	defer r.writes.Unlock()
+
+	before, err := r.inner.GetAllSatellites(ctx)
+	if err != nil {
+		return err
+	}
+	if err := mutate(); err != nil {
+		return err
+	}
+	after, err := r.inner.GetAllSatellites(ctx)
+	if err != nil {
+		return err
+	}
+
+	previous := make(map[string]repository.Satellite, len(before))
+	for _, satellite := range before {
+		previous[satellite.Name] = satellite
+	}
+	actor := ActorFrom(ctx)
+	for _, satellite := range after {
+		entry := Entry{Actor: actor, Action: action, Satellite: satellite.Name, New: &satellite}
+		if old, ok := previous[satellite.Name]; ok {
+			entry.Old = &old
+			delete(previous, satellite.Name)
+		}
+		r.log.Append(entry)
+	}
+	for _, old := range before {
+		if _, removed := previous[old.Name]; removed {
+			r.log.Append(Entry{Actor: actor, Action: action, Satellite: old.Name, Old: &old})
+		}
+	}
+	return nil
+}
+
+// record ejecuta la mutación y, si tiene éxito, anota los valores anterior y nuevo
+func (r *Repository) record(ctx context.Context, action, name string, mutate func() error) error {
+	r.writes.Lock()
+	defer r.writes.Unlock()
+
+	var old *repository.Satellite
+	satellite, err := r.inner.GetSatellite(ctx, name)
+	switch {
+	case err == nil:
+		old = &satellite
+	case !errors.Is(err, repository.ErrSatelliteNotFound):
+		return err
+	}
+
+	if err := mutate(); err != nil {
+		return err
+	}
+
+	entry := Entry{Actor: ActorFrom(ctx), Action: action, Satellite: name, Old: old}
+	// Si la lectura posterior falla el cambio ya está hecho: se registra sin valor nuevo
+	if current, err := r.inner.GetSatellite(ctx, name); err == nil {
+		entry.New = &current
+	}
+	r.log.Append(entry)
+	return nil
+}
@@ -0,0 +1,67 @@
+package audit_test
+
+import (
+	"context"
+	"fuegodequasar/internal/platform/audit"
+	"fuegodequasar/internal/platform/repository"
+	"fuegodequasar/internal/platform/repository/repositorytest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRepositoryConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.RepositoryService {
+		return audit.NewRepository(repository.New(), audit.NewLog(audit.Retention{}))
+	})
+}
+
+// slowWrites alarga cada escritura para que las concurrentes se solapen
+type slowWrites struct {
+	repository.RepositoryService
+}
+
+func (s slowWrites) SaveSatellite(ctx context.Context, satellite repository.Satellite) error {
+	time.Sleep(time.Millisecond)
+	return s.RepositoryService.SaveSatellite(ctx, satellite)
+}
+
+func TestRepositoryConcurrentWritersKeepOldAndNewConsistent(t *testing.T) {
+	log := audit.NewLog(audit.Retention{})
+	repo := audit.NewRepository(slowWrites{repository.New()}, log)
+	ctx := context.Background()
+	base, err := repo.GetSatellite(ctx, "kenobi")
+	if err != nil {
+		t.Fatalf("GetSatellite: %v", err)
+	}
+
+	const writers = 16
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			satellite := base
+			satellite.Distance = float32(100 + i)
+			if err := repo.SaveSatellite(ctx, satellite); err != nil {
+				t.Errorf("SaveSatellite: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Cada entrada debe partir exactamente del valor que dejó la anterior
+	entries := log.Query(audit.Filter{Satellite: "kenobi"})
+	if len(entries) != writers {
+		t.Fatalf("got %d entries, want %d", len(entries), writers)
+	}
+	for i := 1; i < len(entries); i++ {
+		previous, current := entries[i-1], entries[i]
+		if previous.New == nil || current.Old == nil || previous.New.Distance != current.Old.Distance {
+			t.Fatalf("entry %d old value does not match entry %d new value", current.ID, previous.ID)
+		}
+		if current.New.Distance == current.Old.Distance {
+			t.Fatalf("entry %d records no change", current.ID)
+		}
+	}
+}
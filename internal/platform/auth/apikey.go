@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIKeyHeader es la cabecera en la que se presenta una clave estática
+const APIKeyHeader = "X-API-Key"
+
+// APIKey es una clave estática registrada. Solo se guarda el SHA-256 de la
+// clave, en hexadecimal.
+type APIKey struct {
+	ID     string `json:"id"`
+	SHA256 string `json:"sha256"`
+	Roles  []Role `json:"roles"`
+}
+
+// APIKeys autentica con las claves estáticas registradas
+type APIKeys struct {
+	byHash map[string]APIKey
+}
+
+// ParseAPIKeys decodifica y valida un fichero de claves en JSON, una lista de
+// objetos {"id", "sha256", "roles"}
+func ParseAPIKeys(data []byte) (*APIKeys, error) {
+	var keys []APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("invalid api keys: %w", err)
+	}
+	result := &APIKeys{byHash: make(map[string]APIKey, len(keys))}
+	ids := make(map[string]bool, len(keys))
+	for i, key := range keys {
+		key.SHA256 = strings.ToLower(key.SHA256)
+		if key.ID == "" {
+			return nil, fmt.Errorf("invalid api keys: key %d has no id", i)
+		}
+		if ids[key.ID] {
+			return nil, fmt.Errorf("invalid api keys: id %q is repeated", key.ID)
+		}
+		if hash, err := hex.DecodeString(key.SHA256); err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("invalid api keys: key %q: sha256 must be 64 hex digits", key.ID)
+		}
+		if _, exists := result.byHash[key.SHA256]; exists {
+			return nil, fmt.Errorf("invalid api keys: key %q repeats the hash of another key", key.ID)
+		}
+		if len(key.Roles) == 0 {
+			return nil, fmt.Errorf("invalid api keys: key %q has no roles", key.ID)
+		}
+		for _, role := range key.Roles {
+			if !role.Valid() {
+				return nil, fmt.Errorf("invalid api keys: key %q: unknown role %q", key.ID, role)
+			}
+		}
+		ids[key.ID] = true
+		result.byHash[key.SHA256] = key
+	}
+	return result, nil
+}
+
+// HashAPIKey devuelve el hash con el que se registra una clave
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Authenticate implementa Authenticator con la cabecera X-API-Key
+func (k *APIKeys) Authenticate(_ context.Context, header http.Header) (Principal, error) {
+	presented := header.Get(APIKeyHeader)
+	if presented == "" {
+		return Principal{}, ErrNoCredentials
+	}
+	// Se busca por el hash, así que la comparación no depende de la clave guardada
+	key, exists := k.byHash[HashAPIKey(presented)]
+	if !exists {
+		return Principal{}, fmt.Errorf("%w: unknown api key", ErrInvalidCredentials)
+	}
+	return Principal{Subject: key.ID, Roles: key.Roles, Method: "api_key"}, nil
+}
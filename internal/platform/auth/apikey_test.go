@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIKeysAuthenticate(t *testing.T) {
+	keys, err := ParseAPIKeys([]byte(fmt.Sprintf(`[{"id":"station-1","sha256":%q,"roles":["station"]}]`,
+		strings.ToUpper(HashAPIKey("s3cret")))))
+	if err != nil {
+		t.Fatalf("ParseAPIKeys() = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		want    error
+		wantSub string
+	}{
+		{"registered key", "s3cret", nil, "station-1"},
+		{"no key", "", ErrNoCredentials, ""},
+		{"unknown key", "guess", ErrInvalidCredentials, ""},
+		// La clave no es el hash
+		{"hash as key", HashAPIKey("s3cret"), ErrInvalidCredentials, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.key != "" {
+				header.Set(APIKeyHeader, tt.key)
+			}
+			principal, err := keys.Authenticate(context.Background(), header)
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Fatalf("Authenticate() = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && (principal.Subject != tt.wantSub || principal.Method != "api_key" || !slices.Equal(principal.Roles, []Role{RoleStation})) {
+				t.Fatalf("principal = %+v", principal)
+			}
+		})
+	}
+}
+
+func TestParseAPIKeys(t *testing.T) {
+	hash := HashAPIKey("a")
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"valid", fmt.Sprintf(`[{"id":"a","sha256":%q,"roles":["admin"]}]`, hash), false},
+		{"empty", `[]`, false},
+		{"not json", `{`, true},
+		{"no id", fmt.Sprintf(`[{"sha256":%q,"roles":["admin"]}]`, hash), true},
+		{"repeated id", fmt.Sprintf(`[{"id":"a","sha256":%q,"roles":["admin"]},{"id":"a","sha256":%q,"roles":["admin"]}]`, hash, HashAPIKey("b")), true},
+		{"repeated hash", fmt.Sprintf(`[{"id":"a","sha256":%q,"roles":["admin"]},{"id":"b","sha256":%q,"roles":["admin"]}]`, hash, hash), true},
+		{"short hash", `[{"id":"a","sha256":"abcd","roles":["admin"]}]`, true},
+		{"hash not hex", fmt.Sprintf(`[{"id":"a","sha256":%q,"roles":["admin"]}]`, strings.Repeat("z", 64)), true},
+		{"no roles", fmt.Sprintf(`[{"id":"a","sha256":%q}]`, hash), true},
+		{"unknown role", fmt.Sprintf(`[{"id":"a","sha256":%q,"roles":["root"]}]`, hash), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseAPIKeys([]byte(tt.data)); (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAPIKeys() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPrincipalHasRole(t *testing.T) {
+	tests := []struct {
+		name  string
+		roles []Role
+		need  []Role
+		want  bool
+	}{
+		{"same role", []Role{RoleStation}, []Role{RoleStation}, true},
+		{"other role", []Role{RoleStation}, []Role{RoleAnalyst}, false},
+		{"any of", []Role{RoleAnalyst}, []Role{RoleStation, RoleAnalyst}, true},
+		{"admin meets any", []Role{RoleAdmin}, []Role{RoleStation}, true},
+		{"no roles", nil, []Role{RoleStation}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Principal{Roles: tt.roles}).HasRole(tt.need...); got != tt.want {
+				t.Fatalf("HasRole(%v) = %v, want %v", tt.need, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChainAuthenticate(t *testing.T) {
+	keys, err := ParseAPIKeys([]byte(fmt.Sprintf(`[{"id":"key","sha256":%q,"roles":["analyst"]}]`, HashAPIKey("k"))))
+	if err != nil {
+		t.Fatalf("ParseAPIKeys() = %v", err)
+	}
+	jwt, err := NewJWTVerifier(JWTConfig{HS256Secret: testHMACSecret})
+	if err != nil {
+		t.Fatalf("NewJWTVerifier() = %v", err)
+	}
+	jwt.now = func() time.Time { return testNow }
+	chain := Chain{keys, jwt}
+	token := signJWT(t, AlgHS256, map[string]any{"sub": "token", "exp": testNow.Unix() + 60})
+
+	tests := []struct {
+		name    string
+		header  http.Header
+		want    error
+		wantSub string
+	}{
+		{"api key", http.Header{http.CanonicalHeaderKey(APIKeyHeader): {"k"}}, nil, "key"},
+		{"jwt", bearer(token), nil, "token"},
+		// La primera credencial que aparece decide; una clave inválida no cae al JWT
+		{"invalid key with valid jwt", http.Header{http.CanonicalHeaderKey(APIKeyHeader): {"bad"}, "Authorization": {"Bearer " + token}}, ErrInvalidCredentials, ""},
+		{"nothing", http.Header{}, ErrNoCredentials, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := chain.Authenticate(context.Background(), tt.header)
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Fatalf("Authenticate() = %v, want %v", err, tt.want)
+			}
+			if principal.Subject != tt.wantSub {
+				t.Fatalf("principal = %+v, want subject %q", principal, tt.wantSub)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Package auth autentica a los clientes de la API con claves estáticas o con
+// JWT verificados localmente, y define los roles con los que se autorizan.
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+)
+
+var (
+	// ErrNoCredentials indica que la petición no trae credenciales del tipo
+	// que entiende el autenticador
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials indica que las credenciales no son válidas
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Role es un rol de la API
+type Role string
+
+const (
+	// RoleStation puede enviar lecturas de los satélites
+	RoleStation Role = "station"
+	// RoleAnalyst puede consultar lecturas y calcular localizaciones
+	RoleAnalyst Role = "analyst"
+	// RoleAdmin puede hacer cualquier operación
+	RoleAdmin Role = "admin"
+)
+
+// Valid indica si el rol es uno de los conocidos
+func (r Role) Valid() bool {
+	return r == RoleStation || r == RoleAnalyst || r == RoleAdmin
+}
+
+// Principal es la identidad autenticada de una petición
+type Principal struct {
+	// Subject identifica al cliente: el id de la clave o el sub del JWT
+	Subject string `json:"subject"`
+	Roles   []Role `json:"roles"`
+	// Method es el mecanismo con el que se autenticó (api_key, jwt o mtls)
+	Method string `json:"method"`
+}
+
+// HasRole indica si el principal tiene alguno de los roles. El rol admin
+// cumple cualquier requisito.
+func (p Principal) HasRole(roles ...Role) bool {
+	if slices.Contains(p.Roles, RoleAdmin) {
+		return true
+	}
+	for _, role := range roles {
+		if slices.Contains(p.Roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator obtiene el principal a partir de las cabeceras de una
+// petición. Devuelve ErrNoCredentials si no vienen credenciales que entienda
+// y un error que envuelve ErrInvalidCredentials si vienen pero no son válidas.
+type Authenticator interface {
+	Authenticate(ctx context.Context, header http.Header) (Principal, error)
+}
+
+// Chain prueba cada autenticador en orden y se queda con el primero que
+// encuentra credenciales
+type Chain []Authenticator
+
+// Authenticate implementa Authenticator
+func (c Chain) Authenticate(ctx context.Context, header http.Header) (Principal, error) {
+	for _, authenticator := range c {
+		principal, err := authenticator.Authenticate(ctx, header)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return principal, err
+	}
+	return Principal{}, ErrNoCredentials
+}
+
+type principalKey struct{}
+
+// WithPrincipal devuelve un contexto que lleva el principal de la petición
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom recupera el principal del contexto, si lo hay
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
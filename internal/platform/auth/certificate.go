@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"slices"
+	"strings"
+)
+
+// SatelliteURIPrefix es el prefijo de los SAN URI que nombran un satélite,
+// p. ej. urn:satellite:kenobi
+const SatelliteURIPrefix = "urn:satellite:"
+
+// CertificateSatellites devuelve los satélites que identifica un certificado
+// de cliente: su CN, sus SAN DNS y sus SAN URI urn:satellite:<nombre>
+func CertificateSatellites(cert *x509.Certificate) []string {
+	var satellites []string
+	add := func(name string) {
+		if name != "" && !slices.Contains(satellites, name) {
+			satellites = append(satellites, name)
+		}
+	}
+	add(cert.Subject.CommonName)
+	for _, name := range cert.DNSNames {
+		add(name)
+	}
+	for _, uri := range cert.URIs {
+		if name, ok := strings.CutPrefix(uri.String(), SatelliteURIPrefix); ok {
+			add(name)
+		}
+	}
+	return satellites
+}
+
+// VerifiedSatellites devuelve los satélites del certificado de cliente de una
+// conexión TLS, solo si el servidor lo verificó contra sus CA de clientes
+func VerifiedSatellites(state *tls.ConnectionState) []string {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	return CertificateSatellites(state.PeerCertificates[0])
+}
+
+// CertificatePrincipal es el principal de una conexión con un certificado de
+// cliente verificado que identifica a satellites: una estación que solo puede
+// enviar las lecturas de esos satélites
+func CertificatePrincipal(satellites []string) Principal {
+	return Principal{Subject: satellites[0], Roles: []Role{RoleStation}, Method: "mtls"}
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"slices"
+	"testing"
+)
+
+func TestCertificateSatellites(t *testing.T) {
+	uri := func(s string) *url.URL {
+		u, err := url.Parse(s)
+		if err != nil {
+			t.Fatalf("url.Parse(%q): %v", s, err)
+		}
+		return u
+	}
+
+	tests := []struct {
+		name string
+		cert *x509.Certificate
+		want []string
+	}{
+		{"common name", &x509.Certificate{Subject: pkix.Name{CommonName: "kenobi"}}, []string{"kenobi"}},
+		{"dns names", &x509.Certificate{DNSNames: []string{"kenobi", "sato"}}, []string{"kenobi", "sato"}},
+		{"satellite uri", &x509.Certificate{URIs: []*url.URL{uri("urn:satellite:skywalker")}}, []string{"skywalker"}},
+		// Otros SAN URI no nombran satélites
+		{"other uri", &x509.Certificate{URIs: []*url.URL{uri("spiffe://quasar/kenobi")}}, nil},
+		{"repeated", &x509.Certificate{
+			Subject:  pkix.Name{CommonName: "kenobi"},
+			DNSNames: []string{"kenobi"},
+			URIs:     []*url.URL{uri("urn:satellite:kenobi"), uri("urn:satellite:sato")},
+		}, []string{"kenobi", "sato"}},
+		{"nothing", &x509.Certificate{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CertificateSatellites(tt.cert); !slices.Equal(got, tt.want) {
+				t.Fatalf("CertificateSatellites() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifiedSatellites(t *testing.T) {
+	kenobi := &x509.Certificate{Subject: pkix.Name{CommonName: "kenobi"}}
+
+	tests := []struct {
+		name  string
+		state *tls.ConnectionState
+		want  []string
+	}{
+		{"verified", &tls.ConnectionState{PeerCertificates: []*x509.Certificate{kenobi}, VerifiedChains: [][]*x509.Certificate{{kenobi}}}, []string{"kenobi"}},
+		// Un certificado que el servidor no verificó no identifica a nadie
+		{"not verified", &tls.ConnectionState{PeerCertificates: []*x509.Certificate{kenobi}}, nil},
+		{"no certificate", &tls.ConnectionState{}, nil},
+		{"no tls", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifiedSatellites(tt.state); !slices.Equal(got, tt.want) {
+				t.Fatalf("VerifiedSatellites() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCertificatePrincipal(t *testing.T) {
+	principal := CertificatePrincipal([]string{"kenobi", "sato"})
+	if principal.Subject != "kenobi" || principal.Method != "mtls" || !slices.Equal(principal.Roles, []Role{RoleStation}) {
+		t.Fatalf("CertificatePrincipal() = %+v", principal)
+	}
+	// Una estación con certificado no tiene permisos de analista
+	if principal.HasRole(RoleAnalyst) {
+		t.Fatal("certificate principal has the analyst role")
+	}
+}
@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Algoritmos de firma admitidos
+const (
+	AlgHS256 = "HS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// JWTConfig configura la verificación de los JWT. Basta con una de las dos
+// claves; un token firmado con un algoritmo sin clave configurada se rechaza.
+type JWTConfig struct {
+	// HS256Secret es el secreto compartido de los tokens HS256
+	HS256Secret []byte
+	// Ed25519Key es la clave pública de los tokens EdDSA
+	Ed25519Key ed25519.PublicKey
+	// Issuer, si no está vacío, debe coincidir con el claim iss
+	Issuer string
+	// Audience, si no está vacío, debe estar en el claim aud
+	Audience string
+	// Leeway es el margen que se admite en exp y nbf por desfase de relojes
+	Leeway time.Duration
+}
+
+// JWTVerifier autentica con JWT en la cabecera Authorization: Bearer
+type JWTVerifier struct {
+	config JWTConfig
+	now    func() time.Time
+}
+
+// NewJWTVerifier crea un verificador con la configuración indicada
+func NewJWTVerifier(config JWTConfig) (*JWTVerifier, error) {
+	if len(config.HS256Secret) == 0 && len(config.Ed25519Key) == 0 {
+		return nil, errors.New("jwt: no verification key configured")
+	}
+	if len(config.HS256Secret) > 0 && len(config.HS256Secret) < 32 {
+		return nil, errors.New("jwt: HS256 secret must be at least 32 bytes")
+	}
+	if len(config.Ed25519Key) > 0 && len(config.Ed25519Key) != ed25519.PublicKeySize {
+		return nil, errors.New("jwt: invalid Ed25519 public key")
+	}
+	return &JWTVerifier{config: config, now: time.Now}, nil
+}
+
+// ParseEd25519PublicKey lee una clave pública Ed25519 en PEM (PKIX)
+func ParseEd25519PublicKey(data []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("jwt: public key is not PEM")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("jwt: %w", err)
+	}
+	public, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("jwt: public key is not Ed25519")
+	}
+	return public, nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *int64   `json:"exp"`
+	NotBefore *int64   `json:"nbf"`
+	Roles     []Role   `json:"roles"`
+}
+
+// audience admite el claim aud como cadena o como lista
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// Authenticate implementa Authenticator
+func (v *JWTVerifier) Authenticate(_ context.Context, header http.Header) (Principal, error) {
+	scheme, token, found := strings.Cut(header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return Principal{}, ErrNoCredentials
+	}
+	claims, err := v.verify(strings.TrimSpace(token))
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	return Principal{Subject: claims.Subject, Roles: claims.Roles, Method: "jwt"}, nil
+}
+
+// verify comprueba la firma y los claims de un token
+func (v *JWTVerifier) verify(token string) (jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return jwtClaims{}, errors.New("malformed token")
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return jwtClaims{}, fmt.Errorf("malformed header: %v", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return jwtClaims{}, errors.New("malformed signature")
+	}
+
+	// El algoritmo de la cabecera solo elige entre las claves configuradas;
+	// none y cualquier otro se rechazan
+	signed := []byte(parts[0] + "." + parts[1])
+	switch {
+	case header.Alg == AlgHS256 && len(v.config.HS256Secret) > 0:
+		mac := hmac.New(sha256.New, v.config.HS256Secret)
+		mac.Write(signed)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return jwtClaims{}, errors.New("bad signature")
+		}
+	case header.Alg == AlgEdDSA && len(v.config.Ed25519Key) > 0:
+		if !ed25519.Verify(v.config.Ed25519Key, signed, signature) {
+			return jwtClaims{}, errors.New("bad signature")
+		}
+	default:
+		return jwtClaims{}, fmt.Errorf("unsupported algorithm %q", header.Alg)
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return jwtClaims{}, fmt.Errorf("malformed claims: %v", err)
+	}
+	now := v.now()
+	switch {
+	case claims.Subject == "":
+		return jwtClaims{}, errors.New("missing sub")
+	case claims.ExpiresAt == nil:
+		return jwtClaims{}, errors.New("missing exp")
+	case now.After(time.Unix(*claims.ExpiresAt, 0).Add(v.config.Leeway)):
+		return jwtClaims{}, errors.New("token expired")
+	case claims.NotBefore != nil && now.Before(time.Unix(*claims.NotBefore, 0).Add(-v.config.Leeway)):
+		return jwtClaims{}, errors.New("token not yet valid")
+	case v.config.Issuer != "" && claims.Issuer != v.config.Issuer:
+		return jwtClaims{}, errors.New("unexpected issuer")
+	case v.config.Audience != "" && !slices.Contains(claims.Audience, v.config.Audience):
+		return jwtClaims{}, errors.New("unexpected audience")
+	}
+	for _, role := range claims.Roles {
+		if !role.Valid() {
+			return jwtClaims{}, fmt.Errorf("unknown role %q", role)
+		}
+	}
+	return claims, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// signJWT firma claims con el algoritmo alg; con alg desconocido no firma
+func signJWT(t *testing.T, alg string, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("marshal claims: %v", err)
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var signature []byte
+	switch alg {
+	case AlgHS256:
+		mac := hmac.New(sha256.New, testHMACSecret)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case AlgEdDSA:
+		signature = ed25519.Sign(testEd25519Key, []byte(signed))
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func bearer(token string) http.Header {
+	return http.Header{"Authorization": {"Bearer " + token}}
+}
+
+func TestJWTVerifierAuthenticate(t *testing.T) {
+	now := testNow.Unix()
+	claims := func(changes map[string]any) map[string]any {
+		result := map[string]any{"sub": "ops", "iss": "quasar", "aud": "api", "exp": now + 60, "roles": []string{"analyst"}}
+		for key, value := range changes {
+			if value == nil {
+				delete(result, key)
+				continue
+			}
+			result[key] = value
+		}
+		return result
+	}
+	// Las claims de un token con la firma de otro
+	honest := strings.Split(signJWT(t, AlgHS256, claims(nil)), ".")
+	forged := strings.Split(signJWT(t, AlgHS256, claims(map[string]any{"roles": []string{"admin"}})), ".")
+	tampered := honest[0] + "." + forged[1] + "." + honest[2]
+
+	tests := []struct {
+		name    string
+		header  http.Header
+		want    error
+		wantSub string
+	}{
+		{"hs256", bearer(signJWT(t, AlgHS256, claims(nil))), nil, "ops"},
+		{"eddsa", bearer(signJWT(t, AlgEdDSA, claims(nil))), nil, "ops"},
+		{"audience list", bearer(signJWT(t, AlgHS256, claims(map[string]any{"aud": []string{"other", "api"}}))), nil, "ops"},
+		{"within leeway", bearer(signJWT(t, AlgHS256, claims(map[string]any{"exp": now - 20}))), nil, "ops"},
+		{"no header", http.Header{}, ErrNoCredentials, ""},
+		{"other scheme", http.Header{"Authorization": {"Basic b3BzOm9wcw=="}}, ErrNoCredentials, ""},
+		{"alg none", bearer(signJWT(t, "none", claims(nil))), ErrInvalidCredentials, ""},
+		{"unsupported alg", bearer(signJWT(t, "RS256", claims(nil))), ErrInvalidCredentials, ""},
+		{"tampered claims", bearer(tampered), ErrInvalidCredentials, ""},
+		{"malformed", bearer("not.a-token"), ErrInvalidCredentials, ""},
+		{"expired", bearer(signJWT(t, AlgHS256, claims(map[string]any{"exp": now - 60}))), ErrInvalidCredentials, ""},
+		{"no exp", bearer(signJWT(t, AlgHS256, claims(map[string]any{"exp": nil}))), ErrInvalidCredentials, ""},
+		{"not yet valid", bearer(signJWT(t, AlgHS256, claims(map[string]any{"nbf": now + 60}))), ErrInvalidCredentials, ""},
+		{"no sub", bearer(signJWT(t, AlgHS256, claims(map[string]any{"sub": nil}))), ErrInvalidCredentials, ""},
+		{"other issuer", bearer(signJWT(t, AlgHS256, claims(map[string]any{"iss": "evil"}))), ErrInvalidCredentials, ""},
+		{"other audience", bearer(signJWT(t, AlgHS256, claims(map[string]any{"aud": "other"}))), ErrInvalidCredentials, ""},
+		{"unknown role", bearer(signJWT(t, AlgHS256, claims(map[string]any{"roles": []string{"root"}}))), ErrInvalidCredentials, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := NewJWTVerifier(JWTConfig{
+				HS256Secret: testHMACSecret,
+				Ed25519Key:  testEd25519Key.Public().(ed25519.PublicKey),
+				Issuer:      "quasar",
+				Audience:    "api",
+				Leeway:      30 * time.Second,
+			})
+			if err != nil {
+				t.Fatalf("NewJWTVerifier() = %v", err)
+			}
+			v.now = func() time.Time { return testNow }
+
+			principal, err := v.Authenticate(context.Background(), tt.header)
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Fatalf("Authenticate() = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				return
+			}
+			if principal.Subject != tt.wantSub || principal.Method != "jwt" || !slices.Equal(principal.Roles, []Role{RoleAnalyst}) {
+				t.Fatalf("principal = %+v", principal)
+			}
+		})
+	}
+}
+
+func TestJWTVerifierOnlyUsesConfiguredKeys(t *testing.T) {
+	// Sin clave Ed25519 un token EdDSA se rechaza aunque su firma sea válida
+	v, err := NewJWTVerifier(JWTConfig{HS256Secret: testHMACSecret})
+	if err != nil {
+		t.Fatalf("NewJWTVerifier() = %v", err)
+	}
+	v.now = func() time.Time { return testNow }
+	token := signJWT(t, AlgEdDSA, map[string]any{"sub": "ops", "exp": testNow.Unix() + 60})
+	if _, err := v.Authenticate(context.Background(), bearer(token)); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Authenticate() = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestNewJWTVerifier(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  JWTConfig
+		wantErr bool
+	}{
+		{"hs256", JWTConfig{HS256Secret: testHMACSecret}, false},
+		{"eddsa", JWTConfig{Ed25519Key: testEd25519Key.Public().(ed25519.PublicKey)}, false},
+		{"no key", JWTConfig{}, true},
+		{"short secret", JWTConfig{HS256Secret: []byte("short")}, true},
+		{"bad public key", JWTConfig{Ed25519Key: ed25519.PublicKey("short")}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewJWTVerifier(tt.config); (err != nil) != tt.wantErr {
+				t.Fatalf("NewJWTVerifier() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseEd25519PublicKey(t *testing.T) {
+	der, err := x509.MarshalPKIXPublicKey(testEd25519Key.Public())
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey: %v", err)
+	}
+	valid := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"pem", valid, false},
+		{"not pem", []byte("not a key"), true},
+		{"not pkix", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte("junk")}), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParseEd25519PublicKey(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseEd25519PublicKey() = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && !key.Equal(testEd25519Key.Public()) {
+				t.Fatal("ParseEd25519PublicKey() returned another key")
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"container/heap"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Cabeceras de una lectura firmada por su satélite
+const (
+	// SignatureHeader lleva la firma en base64
+	SignatureHeader = "X-Signature"
+	// TimestampHeader lleva el instante de la firma en segundos Unix
+	TimestampHeader = "X-Signature-Timestamp"
+)
+
+var (
+	// ErrUnknownSatellite indica que el satélite no tiene credencial registrada
+	ErrUnknownSatellite = errors.New("satellite has no registered credential")
+	// ErrMissingSignature indica que la petición no trae firma o instante
+	ErrMissingSignature = errors.New("missing signature or timestamp")
+	// ErrBadSignature indica que la firma no corresponde a la petición
+	ErrBadSignature = errors.New("bad signature")
+	// ErrStaleTimestamp indica que el instante está fuera de la ventana admitida
+	ErrStaleTimestamp = errors.New("timestamp outside the replay window")
+	// ErrReplayedSignature indica que la firma ya se usó
+	ErrReplayedSignature = errors.New("signature already used")
+)
+
+// SatelliteCredential es la credencial de un satélite: un secreto compartido
+// para HMAC-SHA256 o una clave pública Ed25519, ambos en base64
+type SatelliteCredential struct {
+	Satellite        string `json:"satellite"`
+	HMACSecret       string `json:"hmac_secret,omitempty"`
+	Ed25519PublicKey string `json:"ed25519_public_key,omitempty"`
+}
+
+type satelliteKey struct {
+	hmacSecret []byte
+	publicKey  ed25519.PublicKey
+}
+
+// SignatureVerifier comprueba que una lectura la firmó su propio satélite,
+// hace poco y por primera vez
+type SignatureVerifier struct {
+	keys   map[string]satelliteKey
+	window time.Duration
+	now    func() time.Time
+
+	mutex sync.Mutex
+	// seen guarda las firmas aceptadas hasta que salen de la ventana y
+	// expiries las ordena por caducidad para descartarlas sin recorrer seen
+	seen     map[string]struct{}
+	expiries expiryQueue
+}
+
+// seenSignature es una firma aceptada y el instante en que sale de la ventana
+type seenSignature struct {
+	id      string
+	expires time.Time
+}
+
+// expiryQueue es un montículo de firmas aceptadas por caducidad
+type expiryQueue []seenSignature
+
+func (q expiryQueue) Len() int           { return len(q) }
+func (q expiryQueue) Less(i, j int) bool { return q[i].expires.Before(q[j].expires) }
+func (q expiryQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *expiryQueue) Push(x any)        { *q = append(*q, x.(seenSignature)) }
+func (q *expiryQueue) Pop() any {
+	old := *q
+	last := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return last
+}
+
+// ParseSatelliteCredentials decodifica y valida un fichero JSON con la lista
+// de credenciales. window es la diferencia máxima admitida entre el instante
+// de la firma y el del servidor, en cualquiera de los dos sentidos.
+func ParseSatelliteCredentials(data []byte, window time.Duration) (*SignatureVerifier, error) {
+	var credentials []SatelliteCredential
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		return nil, fmt.Errorf("invalid satellite credentials: %w", err)
+	}
+	if window <= 0 {
+		return nil, errors.New("invalid satellite credentials: the replay window must be positive")
+	}
+	v := &SignatureVerifier{
+		keys:   make(map[string]satelliteKey, len(credentials)),
+		window: window,
+		now:    time.Now,
+		seen:   make(map[string]struct{}),
+	}
+	for i, credential := range credentials {
+		if credential.Satellite == "" {
+			return nil, fmt.Errorf("invalid satellite credentials: credential %d has no satellite", i)
+		}
+		if _, exists := v.keys[credential.Satellite]; exists {
+			return nil, fmt.Errorf("invalid satellite credentials: satellite %q is repeated", credential.Satellite)
+		}
+		if (credential.HMACSecret == "") == (credential.Ed25519PublicKey == "") {
+			return nil, fmt.Errorf("invalid satellite credentials: satellite %q needs exactly one of hmac_secret or ed25519_public_key", credential.Satellite)
+		}
+		var key satelliteKey
+		if credential.HMACSecret != "" {
+			secret, err := base64.StdEncoding.DecodeString(credential.HMACSecret)
+			if err != nil || len(secret) < 32 {
+				return nil, fmt.Errorf("invalid satellite credentials: satellite %q: hmac_secret must be at least 32 bytes in base64", credential.Satellite)
+			}
+			key.hmacSecret = secret
+		} else {
+			public, err := base64.StdEncoding.DecodeString(credential.Ed25519PublicKey)
+			if err != nil || len(public) != ed25519.PublicKeySize {
+				return nil, fmt.Errorf("invalid satellite credentials: satellite %q: ed25519_public_key must be 32 bytes in base64", credential.Satellite)
+			}
+			key.publicKey = public
+		}
+		v.keys[credential.Satellite] = key
+	}
+	return v, nil
+}
+
+// SigningString es lo que firma el satélite: su nombre, el instante en
+// segundos Unix y el SHA-256 en hexadecimal del cuerpo, separados por saltos
+// de línea
+func SigningString(satellite string, timestamp int64, body []byte) []byte {
+	sum := sha256.Sum256(body)
+	return []byte(satellite + "\n" + strconv.FormatInt(timestamp, 10) + "\n" + hex.EncodeToString(sum[:]))
+}
+
+// Verify comprueba la firma en base64 de body para satellite con el instante
+// timestamp, tal como llegan en las cabeceras. Una firma aceptada no se
+// vuelve a aceptar mientras su instante siga dentro de la ventana.
+func (v *SignatureVerifier) Verify(satellite, timestamp, signature string, body []byte) error {
+	key, exists := v.keys[satellite]
+	if !exists {
+		return ErrUnknownSatellite
+	}
+	if timestamp == "" || signature == "" {
+		return ErrMissingSignature
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: timestamp is not a Unix time", ErrStaleTimestamp)
+	}
+	signedAt := time.Unix(seconds, 0)
+	now := v.now()
+	if signedAt.Before(now.Add(-v.window)) || signedAt.After(now.Add(v.window)) {
+		return ErrStaleTimestamp
+	}
+	raw, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return ErrBadSignature
+	}
+
+	message := SigningString(satellite, seconds, body)
+	if key.hmacSecret != nil {
+		mac := hmac.New(sha256.New, key.hmacSecret)
+		mac.Write(message)
+		if !hmac.Equal(raw, mac.Sum(nil)) {
+			return ErrBadSignature
+		}
+	} else if !ed25519.Verify(key.publicKey, message, raw) {
+		return ErrBadSignature
+	}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	for v.expiries.Len() > 0 && now.After(v.expiries[0].expires) {
+		delete(v.seen, heap.Pop(&v.expiries).(seenSignature).id)
+	}
+	// La caché se indexa por los bytes de la firma y no por su texto: la
+	// decodificación ignora los bits de relleno, así que varios textos
+	// distintos dan la misma firma
+	id := satellite + "\n" + string(raw)
+	if _, replayed := v.seen[id]; replayed {
+		return ErrReplayedSignature
+	}
+	v.seen[id] = struct{}{}
+	heap.Push(&v.expiries, seenSignature{id: id, expires: signedAt.Add(v.window)})
+	return nil
+}
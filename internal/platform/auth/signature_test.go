@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var (
+	testHMACSecret = []byte("0123456789abcdef0123456789abcdef")
+	testEd25519Key = ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	testNow        = time.Unix(1_700_000_000, 0)
+)
+
+func newTestVerifier(t *testing.T) *SignatureVerifier {
+	t.Helper()
+	data := fmt.Sprintf(`[{"satellite":"kenobi","hmac_secret":%q},{"satellite":"skywalker","ed25519_public_key":%q}]`,
+		base64.StdEncoding.EncodeToString(testHMACSecret),
+		base64.StdEncoding.EncodeToString(testEd25519Key.Public().(ed25519.PublicKey)))
+	v, err := ParseSatelliteCredentials([]byte(data), 5*time.Minute)
+	if err != nil {
+		t.Fatalf("ParseSatelliteCredentials: %v", err)
+	}
+	v.now = func() time.Time { return testNow }
+	return v
+}
+
+func signHMAC(satellite string, ts int64, body []byte) string {
+	mac := hmac.New(sha256.New, testHMACSecret)
+	mac.Write(SigningString(satellite, ts, body))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signEd25519(satellite string, ts int64, body []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(testEd25519Key, SigningString(satellite, ts, body)))
+}
+
+func TestSignatureVerifierVerify(t *testing.T) {
+	body := []byte(`{"distance":100,"message":["este","","un","",""]}`)
+	now := testNow.Unix()
+
+	tests := []struct {
+		name      string
+		satellite string
+		timestamp string
+		signature string
+		body      []byte
+		want      error
+	}{
+		{"hmac", "kenobi", strconv.FormatInt(now, 10), signHMAC("kenobi", now, body), body, nil},
+		{"ed25519", "skywalker", strconv.FormatInt(now, 10), signEd25519("skywalker", now, body), body, nil},
+		{"unknown satellite", "sato", strconv.FormatInt(now, 10), signHMAC("sato", now, body), body, ErrUnknownSatellite},
+		{"missing signature", "kenobi", strconv.FormatInt(now, 10), "", body, ErrMissingSignature},
+		{"missing timestamp", "kenobi", "", signHMAC("kenobi", now, body), body, ErrMissingSignature},
+		{"timestamp not a number", "kenobi", "yesterday", signHMAC("kenobi", now, body), body, ErrStaleTimestamp},
+		{"stale timestamp", "kenobi", strconv.FormatInt(now-600, 10), signHMAC("kenobi", now-600, body), body, ErrStaleTimestamp},
+		{"future timestamp", "kenobi", strconv.FormatInt(now+600, 10), signHMAC("kenobi", now+600, body), body, ErrStaleTimestamp},
+		{"tampered body", "kenobi", strconv.FormatInt(now, 10), signHMAC("kenobi", now, body), []byte(`{"distance":101}`), ErrBadSignature},
+		{"other satellite's signature", "skywalker", strconv.FormatInt(now, 10), signHMAC("skywalker", now, body), body, ErrBadSignature},
+		{"timestamp not signed", "kenobi", strconv.FormatInt(now+1, 10), signHMAC("kenobi", now, body), body, ErrBadSignature},
+		{"not base64", "kenobi", strconv.FormatInt(now, 10), "not base64!", body, ErrBadSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestVerifier(t)
+			err := v.Verify(tt.satellite, tt.timestamp, tt.signature, tt.body)
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Fatalf("Verify() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignatureVerifierRejectsReplays(t *testing.T) {
+	body := []byte(`{"distance":100}`)
+	now := testNow.Unix()
+	timestamp := strconv.FormatInt(now, 10)
+	signature := signHMAC("kenobi", now, body)
+
+	// Un SHA-256 son 32 bytes: en base64 el último carácter antes del "=" lleva
+	// 2 bits de relleno que la decodificación no estricta ignora
+	raw, _ := base64.StdEncoding.DecodeString(signature)
+	last := len(signature) - 2
+	reencoded := signature[:last] + string(signature[last]+1) + signature[last+1:]
+	if decoded, err := base64.StdEncoding.DecodeString(reencoded); err != nil || string(decoded) != string(raw) {
+		t.Fatalf("re-encoded signature %q does not decode to the same bytes", reencoded)
+	}
+
+	tests := []struct {
+		name   string
+		replay string
+		want   error
+	}{
+		{"same text", signature, ErrReplayedSignature},
+		{"re-encoded padding bits", reencoded, ErrReplayedSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestVerifier(t)
+			if err := v.Verify("kenobi", timestamp, signature, body); err != nil {
+				t.Fatalf("first Verify() = %v", err)
+			}
+			if err := v.Verify("kenobi", timestamp, tt.replay, body); !errors.Is(err, tt.want) {
+				t.Fatalf("replayed Verify() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignatureVerifierExpiresSeenSignatures(t *testing.T) {
+	v := newTestVerifier(t)
+	body := []byte(`{"distance":100}`)
+	for i := range 3 {
+		ts := testNow.Unix() - int64(i)
+		if err := v.Verify("kenobi", strconv.FormatInt(ts, 10), signHMAC("kenobi", ts, body), body); err != nil {
+			t.Fatalf("Verify(%d) = %v", i, err)
+		}
+	}
+	if len(v.seen) != 3 || v.expiries.Len() != 3 {
+		t.Fatalf("seen %d signatures and %d expiries, want 3", len(v.seen), v.expiries.Len())
+	}
+
+	// Pasada la ventana, la siguiente verificación descarta las caducadas
+	v.now = func() time.Time { return testNow.Add(10 * time.Minute) }
+	ts := v.now().Unix()
+	if err := v.Verify("kenobi", strconv.FormatInt(ts, 10), signHMAC("kenobi", ts, body), body); err != nil {
+		t.Fatalf("Verify() = %v", err)
+	}
+	if len(v.seen) != 1 || v.expiries.Len() != 1 {
+		t.Fatalf("seen %d signatures and %d expiries after the window, want 1", len(v.seen), v.expiries.Len())
+	}
+}
+
+func TestParseSatelliteCredentials(t *testing.T) {
+	secret := base64.StdEncoding.EncodeToString(testHMACSecret)
+	tests := []struct {
+		name   string
+		data   string
+		window time.Duration
+	}{
+		{"not json", `{`, time.Minute},
+		{"no window", `[]`, 0},
+		{"no satellite", fmt.Sprintf(`[{"hmac_secret":%q}]`, secret), time.Minute},
+		{"repeated satellite", fmt.Sprintf(`[{"satellite":"a","hmac_secret":%q},{"satellite":"a","hmac_secret":%q}]`, secret, secret), time.Minute},
+		{"no key", `[{"satellite":"a"}]`, time.Minute},
+		{"both keys", fmt.Sprintf(`[{"satellite":"a","hmac_secret":%q,"ed25519_public_key":%q}]`, secret, secret), time.Minute},
+		{"short secret", `[{"satellite":"a","hmac_secret":"c2hvcnQ="}]`, time.Minute},
+		{"bad public key", `[{"satellite":"a","ed25519_public_key":"c2hvcnQ="}]`, time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseSatelliteCredentials([]byte(tt.data), tt.window); err == nil {
+				t.Fatal("ParseSatelliteCredentials() accepted invalid credentials")
+			}
+		})
+	}
+}
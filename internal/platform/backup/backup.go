@@ -0,0 +1,128 @@
+// Package backup exporta el estado completo del repositorio (la constelación
+// y las lecturas actuales) a un archivo JSON versionado y lo vuelve a importar.
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fuegodequasar/internal/platform/repository"
+	"math"
+	"time"
+)
+
+// FormatVersion es la versión del formato de archivo que genera Export
+const FormatVersion = 1
+
+// Mode indica cómo se aplica un archivo sobre el estado existente
+type Mode string
+
+const (
+	// ModeMerge guarda los satélites del archivo y deja intactos los demás
+	ModeMerge Mode = "merge"
+	// ModeReplace deja el repositorio exactamente con los satélites del archivo
+	ModeReplace Mode = "replace"
+)
+
+// ErrInvalidArchive envuelve los problemas encontrados al validar un archivo
+var ErrInvalidArchive = errors.New("invalid backup archive")
+
+// Archive es la copia de seguridad serializable del repositorio
+type Archive struct {
+	Version    int                    `json:"version"`
+	CreatedAt  time.Time              `json:"created_at"`
+	Satellites []repository.Satellite `json:"satellites"`
+}
+
+// Result resume una importación
+type Result struct {
+	Mode     Mode     `json:"mode"`
+	Restored []string `json:"restored"`
+}
+
+// ParseMode valida el modo de importación; vacío equivale a ModeMerge
+func ParseMode(value string) (Mode, error) {
+	switch Mode(value) {
+	case "", ModeMerge:
+		return ModeMerge, nil
+	case ModeReplace:
+		return ModeReplace, nil
+	}
+	return "", fmt.Errorf("unknown restore mode %q", value)
+}
+
+// Export lee todos los satélites del repositorio y construye el archivo
+func Export(ctx context.Context, repo repository.RepositoryService) (Archive, error) {
+	satellites, err := repo.GetAllSatellites(ctx)
+	if err != nil {
+		return Archive{}, err
+	}
+	return Archive{
+		Version:    FormatVersion,
+		CreatedAt:  time.Now().UTC(),
+		Satellites: satellites,
+	}, nil
+}
+
+// Validate comprueba que el archivo se pueda aplicar: versión conocida,
+// nombres presentes y únicos y valores numéricos finitos
+func (a Archive) Validate() error {
+	if a.Version != FormatVersion {
+		return fmt.Errorf("%w: unsupported version %d, expected %d", ErrInvalidArchive, a.Version, FormatVersion)
+	}
+	seen := make(map[string]bool, len(a.Satellites))
+	for i, satellite := range a.Satellites {
+		if satellite.Name == "" {
+			return fmt.Errorf("%w: satellites[%d]: empty name", ErrInvalidArchive, i)
+		}
+		if seen[satellite.Name] {
+			return fmt.Errorf("%w: satellites[%d]: duplicate satellite %q", ErrInvalidArchive, i, satellite.Name)
+		}
+		seen[satellite.Name] = true
+
+		for field, value := range map[string]float32{
+			"position.x": satellite.Position.X,
+			"position.y": satellite.Position.Y,
+			"distance":   satellite.Distance,
+		} {
+			if math.IsNaN(float64(value)) || math.IsInf(float64(value), 0) {
+				return fmt.Errorf("%w: satellites[%d].%s: not a finite number", ErrInvalidArchive, i, field)
+			}
+		}
+		if !satellite.Status.State.Valid() {
+			return fmt.Errorf("%w: satellites[%d].status.state: unknown state %q", ErrInvalidArchive, i, satellite.Status.State)
+		}
+		if satellite.Distance < 0 {
+			return fmt.Errorf("%w: satellites[%d].distance: must not be negative", ErrInvalidArchive, i)
+		}
+	}
+	return nil
+}
+
+// Import valida el archivo y lo aplica sobre el repositorio según el modo
+func Import(ctx context.Context, repo repository.RepositoryService, archive Archive, mode Mode) (Result, error) {
+	if err := archive.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	result := Result{Mode: mode, Restored: make([]string, 0, len(archive.Satellites))}
+	for _, satellite := range archive.Satellites {
+		result.Restored = append(result.Restored, satellite.Name)
+	}
+
+	switch mode {
+	case ModeReplace:
+		if err := repo.ReplaceAllSatellites(ctx, archive.Satellites); err != nil {
+			return Result{}, err
+		}
+	case ModeMerge:
+		for _, satellite := range archive.Satellites {
+			if err := repo.SaveSatellite(ctx, satellite); err != nil {
+				return Result{}, fmt.Errorf("restoring %q: %w", satellite.Name, err)
+			}
+		}
+	default:
+		return Result{}, fmt.Errorf("unknown restore mode %q", mode)
+	}
+	return result, nil
+}
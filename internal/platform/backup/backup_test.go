@@ -0,0 +1,131 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fuegodequasar/internal/platform/repository"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestArchiveValidate(t *testing.T) {
+	valid := repository.Satellite{Name: "kenobi", Position: repository.Point{X: -500, Y: -200}, Distance: 100}
+	with := func(change func(*repository.Satellite)) repository.Satellite {
+		satellite := valid
+		change(&satellite)
+		return satellite
+	}
+
+	tests := []struct {
+		name    string
+		archive Archive
+		wantErr bool
+	}{
+		{"valid", Archive{Version: FormatVersion, Satellites: []repository.Satellite{valid}}, false},
+		{"empty", Archive{Version: FormatVersion}, false},
+		{"unknown version", Archive{Version: FormatVersion + 1, Satellites: []repository.Satellite{valid}}, true},
+		{"no version", Archive{Satellites: []repository.Satellite{valid}}, true},
+		{"empty name", Archive{Version: FormatVersion, Satellites: []repository.Satellite{with(func(s *repository.Satellite) { s.Name = "" })}}, true},
+		{"duplicate name", Archive{Version: FormatVersion, Satellites: []repository.Satellite{valid, valid}}, true},
+		{"position not finite", Archive{Version: FormatVersion, Satellites: []repository.Satellite{
+			with(func(s *repository.Satellite) { s.Position.X = float32(math.Inf(1)) })}}, true},
+		{"distance not a number", Archive{Version: FormatVersion, Satellites: []repository.Satellite{
+			with(func(s *repository.Satellite) { s.Distance = float32(math.NaN()) })}}, true},
+		{"negative distance", Archive{Version: FormatVersion, Satellites: []repository.Satellite{
+			with(func(s *repository.Satellite) { s.Distance = -1 })}}, true},
+		{"unknown state", Archive{Version: FormatVersion, Satellites: []repository.Satellite{
+			with(func(s *repository.Satellite) { s.Status.State = "sleeping" })}}, true},
+		{"known state", Archive{Version: FormatVersion, Satellites: []repository.Satellite{
+			with(func(s *repository.Satellite) { s.Status.State = repository.StatusMaintenance })}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.archive.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidArchive) {
+				t.Fatalf("Validate() = %v, want it to wrap ErrInvalidArchive", err)
+			}
+		})
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Mode
+		wantErr bool
+	}{
+		{"", ModeMerge, false},
+		{"merge", ModeMerge, false},
+		{"replace", ModeReplace, false},
+		{"Replace", "", true},
+		{"append", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMode(tt.value)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseMode(%q) = %q, %v, want %q (error %v)", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestImport(t *testing.T) {
+	ctx := context.Background()
+	archive := Archive{Version: FormatVersion, Satellites: []repository.Satellite{
+		{Name: "kenobi", Position: repository.Point{X: -500, Y: -200}, Distance: 100, Message: []string{"este", "", "un"}},
+		{Name: "yoda", Position: repository.Point{X: 0, Y: 0}, Distance: 50},
+	}}
+
+	tests := []struct {
+		name string
+		mode Mode
+		want []string
+	}{
+		// merge conserva skywalker y sato, que no están en el archivo
+		{"merge", ModeMerge, []string{"kenobi", "sato", "skywalker", "yoda"}},
+		{"replace", ModeReplace, []string{"kenobi", "yoda"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.New()
+			result, err := Import(ctx, repo, archive, tt.mode)
+			if err != nil {
+				t.Fatalf("Import() = %v", err)
+			}
+			if result.Mode != tt.mode || !slices.Equal(result.Restored, []string{"kenobi", "yoda"}) {
+				t.Fatalf("result = %+v", result)
+			}
+
+			satellites, err := repo.GetAllSatellites(ctx)
+			if err != nil {
+				t.Fatalf("GetAllSatellites() = %v", err)
+			}
+			var names []string
+			for _, satellite := range satellites {
+				names = append(names, satellite.Name)
+			}
+			slices.Sort(names)
+			if !slices.Equal(names, tt.want) {
+				t.Fatalf("satellites = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportRejectsInvalidArchive(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.New()
+	before, _ := repo.GetAllSatellites(ctx)
+
+	archive := Archive{Version: FormatVersion, Satellites: []repository.Satellite{{Name: "kenobi"}, {Name: ""}}}
+	if _, err := Import(ctx, repo, archive, ModeReplace); !errors.Is(err, ErrInvalidArchive) {
+		t.Fatalf("Import() = %v, want ErrInvalidArchive", err)
+	}
+	after, _ := repo.GetAllSatellites(ctx)
+	if len(after) != len(before) {
+		t.Fatalf("an invalid archive changed the repository: %d satellites, had %d", len(after), len(before))
+	}
+}
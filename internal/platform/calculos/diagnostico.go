@@ -0,0 +1,51 @@
+package calculos
+
+// Nombres de los métodos de cálculo de la posición
+const (
+	// SolverExact es la trilateración exacta con tres circunferencias
+	SolverExact = "trilateration"
+	// SolverWeighted es la trilateración ponderada con N circunferencias
+	SolverWeighted = "weighted_least_squares"
+)
+
+// Diagnostics describe cómo se calculó una posición, para poder revisar un
+// resultado sospechoso
+type Diagnostics struct {
+	// Solver es el método usado (SolverExact o SolverWeighted)
+	Solver string
+	// Position es la posición calculada en doble precisión; (0, 0) si falló
+	Position Point
+	// Residuals son los residuos |x - p_i| - r_i de la posición estimada, en el
+	// orden de los puntos; vacío si no se llegó a estimar ninguna
+	Residuals []float64
+	// Tolerance es el residuo máximo admitido; 0 si el método no lo limita
+	Tolerance float64
+	// Determinant es el determinante del sistema lineal; cerca de 0 indica
+	// una geometría casi colineal
+	Determinant float64
+	// Iterations son las iteraciones de Gauss-Newton realizadas
+	Iterations int
+	// Err es el motivo del fallo, si lo hubo
+	Err error
+}
+
+// MergeTrace describe cómo se reconstruyó un mensaje
+type MergeTrace struct {
+	// Length es el número de palabras del mensaje reconstruido
+	Length int
+	// Padding son las palabras vacías añadidas al inicio de cada copia para
+	// igualar su longitud
+	Padding []int
+	// Words explica de dónde sale cada palabra
+	Words []MergeWord
+}
+
+// MergeWord es la decisión tomada para una posición del mensaje
+type MergeWord struct {
+	Word string
+	// Source es el índice de la copia de la que se tomó la palabra; -1 si
+	// ninguna copia la tenía
+	Source int
+	// Discarded son las palabras distintas que traían copias posteriores
+	Discarded []string
+}
@@ -0,0 +1,161 @@
+package calculos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// maxIteraciones limita las iteraciones de Gauss-Newton de TrilateracionPonderada
+const maxIteraciones = 50
+
+// SolverError indica que la trilateración no encontró una posición
+type SolverError struct {
+	Reason string
+	// Residuals son los residuos |x - p_i| - r_i de la última estimación, si
+	// se llegó a calcular alguna
+	Residuals []float64
+}
+
+func (e *SolverError) Error() string {
+	return e.Reason
+}
+
+// GetLocationWeighted es la versión para N satélites con pesos de GetLocation.
+// Un peso menor reduce la influencia de ese satélite en la posición estimada.
+func GetLocationWeighted(points []Point32, distances []float32, weights []float64) (Point32, error) {
+	pos, diag := GetLocationWeightedDiagnostics(points, distances, weights)
+	return pos, diag.Err
+}
+
+// GetLocationWeightedDiagnostics es GetLocationWeighted con el detalle del
+// cálculo; el error, si lo hay, queda en Diagnostics.Err
+func GetLocationWeightedDiagnostics(points []Point32, distances []float32, weights []float64) (Point32, Diagnostics) {
+	p := make([]Point, len(points))
+	for i, point := range points {
+		p[i] = Point{X: float64(point.X), Y: float64(point.Y)}
+	}
+	r := make([]float64, len(distances))
+	for i, distance := range distances {
+		r[i] = float64(distance)
+	}
+
+	pos, diag, err := trilateracionPonderada(p, r, weights)
+	if err != nil {
+		diag.Err = err
+		return Point32{}, diag
+	}
+	return Point32{X: float32(pos.X), Y: float32(pos.Y)}, diag
+}
+
+// TrilateracionPonderada estima la posición minimizando la suma ponderada de
+// los cuadrados de los residuos sum(w_i * (|x - p_i| - r_i)^2). Parte de la
+// solución lineal (resta de circunferencias) y la refina con Gauss-Newton, de
+// modo que con datos coherentes coincide con Trilateracion y con datos ruidosos
+// los satélites de menor peso influyen menos.
+func TrilateracionPonderada(points []Point, radii, weights []float64) (Point, error) {
+	pos, _, err := trilateracionPonderada(points, radii, weights)
+	return pos, err
+}
+
+// trilateracionPonderada es TrilateracionPonderada con el detalle del cálculo
+func trilateracionPonderada(points []Point, radii, weights []float64) (Point, Diagnostics, error) {
+	diag := Diagnostics{Solver: SolverWeighted}
+	n := len(points)
+	if n < 3 {
+		return Point{}, diag, fmt.Errorf("se necesitan al menos 3 puntos, hay %d", n)
+	}
+	if len(radii) != n || len(weights) != n {
+		return Point{}, diag, errors.New("puntos, radios y pesos deben tener la misma longitud")
+	}
+	for i, w := range weights {
+		if w <= 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return Point{}, diag, fmt.Errorf("peso %d inválido: %v", i, w)
+		}
+	}
+
+	x, det, err := solucionLineal(points, radii, weights)
+	diag.Determinant = det
+	if err != nil {
+		return Point{}, diag, err
+	}
+
+	for iter := 0; iter < maxIteraciones; iter++ {
+		diag.Iterations = iter + 1
+		// Ecuaciones normales (J^T W J) dx = -J^T W r
+		jtwj := mat.NewSymDense(2, nil)
+		jtwr := mat.NewVecDense(2, nil)
+		for i, p := range points {
+			dx, dy := x.X-p.X, x.Y-p.Y
+			d := math.Hypot(dx, dy)
+			if d < 1e-12 {
+				// En el centro de la circunferencia el gradiente no está definido
+				continue
+			}
+			jx, jy := dx/d, dy/d
+			r := d - radii[i]
+			w := weights[i]
+			jtwj.SetSym(0, 0, jtwj.At(0, 0)+w*jx*jx)
+			jtwj.SetSym(0, 1, jtwj.At(0, 1)+w*jx*jy)
+			jtwj.SetSym(1, 1, jtwj.At(1, 1)+w*jy*jy)
+			jtwr.SetVec(0, jtwr.AtVec(0)-w*jx*r)
+			jtwr.SetVec(1, jtwr.AtVec(1)-w*jy*r)
+		}
+
+		var step mat.VecDense
+		if err := step.SolveVec(jtwj, jtwr); err != nil {
+			diag.Residuals = residuos(points, radii, x)
+			return Point{}, diag, &SolverError{
+				Reason:    fmt.Sprintf("geometría degenerada en la iteración %d: %v", iter, err),
+				Residuals: diag.Residuals,
+			}
+		}
+		x.X += step.AtVec(0)
+		x.Y += step.AtVec(1)
+		if math.Hypot(step.AtVec(0), step.AtVec(1)) < 1e-9 {
+			break
+		}
+	}
+	diag.Residuals = residuos(points, radii, x)
+	diag.Position = x
+	return x, diag, nil
+}
+
+// solucionLineal resuelve por mínimos cuadrados ponderados el sistema lineal
+// que resulta de restar la primera circunferencia a las demás. Devuelve también
+// el determinante de las ecuaciones normales A^T A del sistema.
+func solucionLineal(points []Point, radii, weights []float64) (Point, float64, error) {
+	p1, r1 := points[0], radii[0]
+	rows := len(points) - 1
+	a := mat.NewDense(rows, 2, nil)
+	b := mat.NewVecDense(rows, nil)
+	for i := 1; i < len(points); i++ {
+		p, r := points[i], radii[i]
+		// Cada ecuación combina dos satélites: se pondera con el menor de sus pesos
+		w := math.Sqrt(math.Min(weights[0], weights[i]))
+		a.Set(i-1, 0, w*2*(p.X-p1.X))
+		a.Set(i-1, 1, w*2*(p.Y-p1.Y))
+		b.SetVec(i-1, w*(r1*r1-r*r-p1.X*p1.X+p.X*p.X-p1.Y*p1.Y+p.Y*p.Y))
+	}
+
+	var ata mat.Dense
+	ata.Mul(a.T(), a)
+	det := mat.Det(&ata)
+
+	var x mat.VecDense
+	if err := x.SolveVec(a, b); err != nil {
+		return Point{}, det, &SolverError{Reason: fmt.Sprintf("puntos colineales o configuración incoherente: %v", err)}
+	}
+	return Point{X: x.AtVec(0), Y: x.AtVec(1)}, det, nil
+}
+
+// residuos devuelve |x - p_i| - r_i para cada circunferencia
+func residuos(points []Point, radii []float64, x Point) []float64 {
+	result := make([]float64, len(points))
+	for i, p := range points {
+		result[i] = math.Hypot(x.X-p.X, x.Y-p.Y) - radii[i]
+	}
+	return result
+}
@@ -0,0 +1,171 @@
+package calculos
+
+import (
+	"math"
+	"testing"
+)
+
+var (
+	kenobi    = Point{X: -500, Y: -200}
+	skywalker = Point{X: 100, Y: -100}
+	sato      = Point{X: 500, Y: 100}
+)
+
+// distancias devuelve las distancias exactas de target a cada punto
+func distancias(target Point, points ...Point) []float64 {
+	radii := make([]float64, len(points))
+	for i, p := range points {
+		radii[i] = math.Hypot(target.X-p.X, target.Y-p.Y)
+	}
+	return radii
+}
+
+func TestTrilateracionPonderada(t *testing.T) {
+	target := Point{X: -100, Y: 75.5}
+	fourth := Point{X: 0, Y: 600}
+
+	tests := []struct {
+		name    string
+		points  []Point
+		radii   []float64
+		weights []float64
+		want    Point
+	}{
+		{"three satellites", []Point{kenobi, skywalker, sato}, distancias(target, kenobi, skywalker, sato), []float64{1, 1, 1}, target},
+		{"four satellites", []Point{kenobi, skywalker, sato, fourth}, distancias(target, kenobi, skywalker, sato, fourth), []float64{1, 1, 1, 1}, target},
+		// Con datos coherentes los pesos no cambian la solución
+		{"uneven weights", []Point{kenobi, skywalker, sato, fourth}, distancias(target, kenobi, skywalker, sato, fourth), []float64{1, 0.5, 2, 0.1}, target},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TrilateracionPonderada(tt.points, tt.radii, tt.weights)
+			if err != nil {
+				t.Fatalf("TrilateracionPonderada() = %v", err)
+			}
+			if math.Hypot(got.X-tt.want.X, got.Y-tt.want.Y) > 1e-6 {
+				t.Fatalf("TrilateracionPonderada() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrilateracionPonderadaMatchesTrilateracion(t *testing.T) {
+	radii := distancias(Point{X: 250, Y: -40}, kenobi, skywalker, sato)
+	exact, err := Trilateracion(kenobi, skywalker, sato, radii[0], radii[1], radii[2], 1e-6)
+	if err != nil {
+		t.Fatalf("Trilateracion() = %v", err)
+	}
+	weighted, err := TrilateracionPonderada([]Point{kenobi, skywalker, sato}, radii, []float64{1, 1, 1})
+	if err != nil {
+		t.Fatalf("TrilateracionPonderada() = %v", err)
+	}
+	if math.Hypot(exact.X-weighted.X, exact.Y-weighted.Y) > 1e-6 {
+		t.Fatalf("weighted %+v differs from exact %+v", weighted, exact)
+	}
+}
+
+func TestTrilateracionPonderadaDownweightsNoisySatellite(t *testing.T) {
+	target := Point{X: -100, Y: 75.5}
+	fourth := Point{X: 0, Y: 600}
+	points := []Point{kenobi, skywalker, sato, fourth}
+	radii := distancias(target, points...)
+	// La lectura del cuarto satélite llega con 40 unidades de error
+	radii[3] += 40
+
+	tests := []struct {
+		name   string
+		weight float64
+	}{
+		{"same weight", 1},
+		{"degraded", 0.5},
+		{"almost ignored", 0.01},
+	}
+	previous := math.Inf(1)
+	for _, tt := range tests {
+		got, err := TrilateracionPonderada(points, radii, []float64{1, 1, 1, tt.weight})
+		if err != nil {
+			t.Fatalf("%s: TrilateracionPonderada() = %v", tt.name, err)
+		}
+		// Cuanto menos pesa la lectura errónea, más cerca queda la posición real
+		distance := math.Hypot(got.X-target.X, got.Y-target.Y)
+		if distance >= previous {
+			t.Fatalf("%s: error %.4f, want less than %.4f with a lower weight", tt.name, distance, previous)
+		}
+		previous = distance
+	}
+}
+
+func TestTrilateracionPonderadaErrors(t *testing.T) {
+	radii := distancias(Point{X: 10, Y: 10}, kenobi, skywalker, sato)
+
+	tests := []struct {
+		name    string
+		points  []Point
+		radii   []float64
+		weights []float64
+	}{
+		{"two satellites", []Point{kenobi, skywalker}, radii[:2], []float64{1, 1}},
+		{"missing radius", []Point{kenobi, skywalker, sato}, radii[:2], []float64{1, 1, 1}},
+		{"missing weight", []Point{kenobi, skywalker, sato}, radii, []float64{1, 1}},
+		{"zero weight", []Point{kenobi, skywalker, sato}, radii, []float64{1, 0, 1}},
+		{"negative weight", []Point{kenobi, skywalker, sato}, radii, []float64{1, -1, 1}},
+		{"weight not a number", []Point{kenobi, skywalker, sato}, radii, []float64{1, math.NaN(), 1}},
+		{"infinite weight", []Point{kenobi, skywalker, sato}, radii, []float64{1, math.Inf(1), 1}},
+		{"collinear", []Point{{X: 0, Y: 0}, {X: 100, Y: 0}, {X: 200, Y: 0}}, []float64{50, 50, 150}, []float64{1, 1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := TrilateracionPonderada(tt.points, tt.radii, tt.weights); err == nil {
+				t.Fatalf("TrilateracionPonderada() = %+v, want an error", got)
+			}
+		})
+	}
+}
+
+func TestGetLocationWeightedDiagnostics(t *testing.T) {
+	target := Point{X: -100, Y: 75.5}
+	radii := distancias(target, kenobi, skywalker, sato)
+	points := []Point32{
+		{X: float32(kenobi.X), Y: float32(kenobi.Y)},
+		{X: float32(skywalker.X), Y: float32(skywalker.Y)},
+		{X: float32(sato.X), Y: float32(sato.Y)},
+	}
+	distances := []float32{float32(radii[0]), float32(radii[1]), float32(radii[2])}
+
+	tests := []struct {
+		name    string
+		weights []float64
+		wantErr bool
+	}{
+		{"located", []float64{1, 1, 0.5}, false},
+		{"invalid weight", []float64{1, 1, 0}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos, diag := GetLocationWeightedDiagnostics(points, distances, tt.weights)
+			if diag.Solver != SolverWeighted {
+				t.Fatalf("Solver = %q, want %q", diag.Solver, SolverWeighted)
+			}
+			if tt.wantErr {
+				if diag.Err == nil || pos != (Point32{}) {
+					t.Fatalf("GetLocationWeightedDiagnostics() = %+v, %v, want (0, 0) and an error", pos, diag.Err)
+				}
+				return
+			}
+			if diag.Err != nil {
+				t.Fatalf("Err = %v", diag.Err)
+			}
+			if math.Hypot(float64(pos.X)-target.X, float64(pos.Y)-target.Y) > 0.01 {
+				t.Fatalf("position = %+v, want %+v", pos, target)
+			}
+			if len(diag.Residuals) != len(points) || diag.Iterations == 0 {
+				t.Fatalf("diagnostics = %+v, want %d residuals and at least one iteration", diag, len(points))
+			}
+			for i, residual := range diag.Residuals {
+				if math.Abs(residual) > 0.01 {
+					t.Fatalf("residual %d = %v, want about 0", i, residual)
+				}
+			}
+		})
+	}
+}
@@ -6,26 +6,48 @@ import (
 )
 
 func GetMessage(KenoviMessage, SkywalkerMessage, SatoMessage []string) (ShipCleanMessage string, err1 error) {
+	return MergeMessages(KenoviMessage, SkywalkerMessage, SatoMessage)
+}
+
+// MergeMessages reconstruye el mensaje a partir de las copias recibidas por
+// cualquier número de satélites. Ante dos palabras distintas en la misma
+// posición gana la del satélite que aparece antes.
+func MergeMessages(messages ...[]string) (string, error) {
+	message, _, err := MergeMessagesTrace(messages...)
+	return message, err
+}
+
+// MergeMessagesTrace es MergeMessages con la traza de la reconstrucción: el
+// relleno de cada copia y de qué copia sale cada palabra
+func MergeMessagesTrace(messages ...[]string) (string, MergeTrace, error) {
 	// Primero encontrar el máximo largo
-	maxLen := max(len(KenoviMessage), len(SkywalkerMessage), len(SatoMessage))
+	maxLen := 0
+	for _, msg := range messages {
+		maxLen = max(maxLen, len(msg))
+	}
 
 	// Normalizar los mensajes al mismo largo agregando "" al inicio si es necesario
-	KenoviMessage = normalize(KenoviMessage, maxLen)
-	SkywalkerMessage = normalize(SkywalkerMessage, maxLen)
-	SatoMessage = normalize(SatoMessage, maxLen)
+	trace := MergeTrace{Length: maxLen, Padding: make([]int, len(messages)), Words: make([]MergeWord, maxLen)}
+	normalized := make([][]string, len(messages))
+	for i, msg := range messages {
+		normalized[i] = normalize(msg, maxLen)
+		trace.Padding[i] = maxLen - len(msg)
+	}
 
 	// Reconstruir palabra por palabra
 	result := make([]string, maxLen)
 	for i := 0; i < maxLen; i++ {
-		word := ""
-		if KenoviMessage[i] != "" {
-			word = KenoviMessage[i]
-		} else if SkywalkerMessage[i] != "" {
-			word = SkywalkerMessage[i]
-		} else if SatoMessage[i] != "" {
-			word = SatoMessage[i]
+		trace.Words[i].Source = -1
+		for j, msg := range normalized {
+			switch {
+			case msg[i] == "":
+			case trace.Words[i].Source < 0:
+				result[i] = msg[i]
+				trace.Words[i] = MergeWord{Word: msg[i], Source: j}
+			case msg[i] != result[i]:
+				trace.Words[i].Discarded = append(trace.Words[i].Discarded, msg[i])
+			}
 		}
-		result[i] = word
 	}
 
 	// Comprobar si pudimos reconstruir al menos una palabra
@@ -37,22 +59,11 @@ func GetMessage(KenoviMessage, SkywalkerMessage, SatoMessage []string) (ShipClea
 		}
 	}
 	if !found {
-		return "", errors.New("no se pudo reconstruir ningún mensaje")
+		return "", trace, errors.New("no se pudo reconstruir ningún mensaje")
 	}
 
 	// Unir las palabras con espacio
-	ShipCleanMessage = strings.TrimSpace(strings.Join(result, " "))
-	return ShipCleanMessage, nil
-}
-
-// max devuelve el mayor de tres enteros
-func max(a, b, c int) int {
-	if a >= b && a >= c {
-		return a
-	} else if b >= a && b >= c {
-		return b
-	}
-	return c
+	return strings.TrimSpace(strings.Join(result, " ")), trace, nil
 }
 
 // normalize rellena con "" al inicio para igualar longitud
@@ -34,6 +34,14 @@ func checkPairwiseIntersection(p1, p2 Point, r1, r2 float64) bool {
 
 // GetLocation enmascara la función Trilateracion para que cumpla con la firma requerida.
 func GetLocation(p1, p2, p3 Point32, r1, r2, r3 float32) Point32 {
+	pos, _ := GetLocationDiagnostics(p1, p2, p3, r1, r2, r3)
+	return pos
+}
+
+// GetLocationDiagnostics es GetLocation con el detalle del cálculo. Si la
+// trilateración falla devuelve (0, 0) igual que GetLocation y el motivo queda
+// en Diagnostics.Err.
+func GetLocationDiagnostics(p1, p2, p3 Point32, r1, r2, r3 float32) (Point32, Diagnostics) {
 	// Convertimos Point32 → Point (float64)
 	p1f := Point{X: float64(p1.X), Y: float64(p1.Y)}
 	p2f := Point{X: float64(p2.X), Y: float64(p2.Y)}
@@ -47,18 +55,18 @@ func GetLocation(p1, p2, p3 Point32, r1, r2, r3 float32) Point32 {
 	// tolerancia para residuos
 	tol := 10.0
 	// Llamo a Trilateracion
-	pos, err := Trilateracion(p1f, p2f, p3f, r1f, r2f, r3f, tol)
+	pos, diag, err := trilateracion(p1f, p2f, p3f, r1f, r2f, r3f, tol)
 	if err != nil {
 		// Devolver un valor por defecto cuando falla:
-		fmt.Println("Trilateracion error:", err) // <-- Agrega este log
-		return Point32{X: 0, Y: 0}
+		diag.Err = err
+		return Point32{X: 0, Y: 0}, diag
 	}
 
 	// Convertimos el resultado a Point32
 	return Point32{
 		X: float32(pos.X),
 		Y: float32(pos.Y),
-	}
+	}, diag
 }
 
 // Trilateracion calcula la posición (x, y) de la fuente
@@ -67,21 +75,12 @@ func GetLocation(p1, p2, p3 Point32, r1, r2, r3 float32) Point32 {
 // tol es la tolerancia máxima aceptable en unidades de distancia
 // (ej. 0.1, 0.5, para datos con mucho ruido o 1e-6 para datos muy precisos).
 func Trilateracion(p1, p2, p3 Point, r1, r2, r3 float64, tol float64) (Point, error) {
+	pos, _, err := trilateracion(p1, p2, p3, r1, r2, r3, tol)
+	return pos, err
+}
 
-	if !checkPairwiseIntersection(p1, p2, r1, r2) {
-		return Point{}, fmt.Errorf(
-			"las circunferencias 1 y 2 no pueden intersectar (pareja incoherente): p1=(%.2f,%.2f) r1=%.2f, p2=(%.2f,%.2f) r2=%.2f",
-			p1.X, p1.Y, r1, p2.X, p2.Y, r2)
-	}
-	if !checkPairwiseIntersection(p1, p3, r1, r3) {
-		return Point{}, fmt.Errorf("las circunferencias 1 y 3 no pueden intersectar (pareja incoherente): p1=(%.2f,%.2f) r1=%.2f, p3=(%.2f,%.2f) r3=%.2f",
-			p1.X, p1.Y, r1, p3.X, p3.Y, r3)
-	}
-	if !checkPairwiseIntersection(p2, p3, r2, r3) {
-		return Point{}, fmt.Errorf("las circunferencias 2 y 3 no pueden intersectar (pareja incoherente): p2=(%.2f,%.2f) r2=%.2f, p3=(%.2f,%.2f) r3=%.2f",
-			p2.X, p2.Y, r2, p3.X, p3.Y, r3)
-	}
-
+// trilateracion es Trilateracion con el detalle del cálculo
+func trilateracion(p1, p2, p3 Point, r1, r2, r3 float64, tol float64) (Point, Diagnostics, error) {
 	// Construimos las ecuaciones lineales (resta de circunferencias)
 	A := 2 * (p2.X - p1.X)
 	B := 2 * (p2.Y - p1.Y)
@@ -93,8 +92,24 @@ func Trilateracion(p1, p2, p3 Point, r1, r2, r3 float64, tol float64) (Point, er
 
 	// Determinante de la matriz
 	den := A*E - B*D
+	diag := Diagnostics{Solver: SolverExact, Tolerance: tol, Determinant: den}
+
+	if !checkPairwiseIntersection(p1, p2, r1, r2) {
+		return Point{}, diag, fmt.Errorf(
+			"las circunferencias 1 y 2 no pueden intersectar (pareja incoherente): p1=(%.2f,%.2f) r1=%.2f, p2=(%.2f,%.2f) r2=%.2f",
+			p1.X, p1.Y, r1, p2.X, p2.Y, r2)
+	}
+	if !checkPairwiseIntersection(p1, p3, r1, r3) {
+		return Point{}, diag, fmt.Errorf("las circunferencias 1 y 3 no pueden intersectar (pareja incoherente): p1=(%.2f,%.2f) r1=%.2f, p3=(%.2f,%.2f) r3=%.2f",
+			p1.X, p1.Y, r1, p3.X, p3.Y, r3)
+	}
+	if !checkPairwiseIntersection(p2, p3, r2, r3) {
+		return Point{}, diag, fmt.Errorf("las circunferencias 2 y 3 no pueden intersectar (pareja incoherente): p2=(%.2f,%.2f) r2=%.2f, p3=(%.2f,%.2f) r3=%.2f",
+			p2.X, p2.Y, r2, p3.X, p3.Y, r3)
+	}
+
 	if math.Abs(den) < 1e-12 {
-		return Point{}, fmt.Errorf("determinante cero o casi cero: puntos colineales o configuración incoherente")
+		return Point{}, diag, fmt.Errorf("determinante cero o casi cero: puntos colineales o configuración incoherente")
 	}
 
 	x := (C*E - B*F) / den
@@ -104,6 +119,7 @@ func Trilateracion(p1, p2, p3 Point, r1, r2, r3 float64, tol float64) (Point, er
 	d1 := math.Hypot(x-p1.X, y-p1.Y)
 	d2 := math.Hypot(x-p2.X, y-p2.Y)
 	d3 := math.Hypot(x-p3.X, y-p3.Y)
+	diag.Residuals = []float64{d1 - r1, d2 - r2, d3 - r3}
 
 	rerr1 := math.Abs(d1 - r1)
 	rerr2 := math.Abs(d2 - r2)
@@ -112,11 +128,12 @@ func Trilateracion(p1, p2, p3 Point, r1, r2, r3 float64, tol float64) (Point, er
 	maxErr := math.Max(rerr1, math.Max(rerr2, rerr3))
 
 	if maxErr > tol {
-		return Point{}, fmt.Errorf("no hay intersección coherente: max residual = %.6f > tol(%.6f). residuos = [%.6f, %.6f, %.6f]",
+		return Point{}, diag, fmt.Errorf("no hay intersección coherente: max residual = %.6f > tol(%.6f). residuos = [%.6f, %.6f, %.6f]",
 			maxErr, tol, rerr1, rerr2, rerr3)
 	}
 
-	return Point{X: x, Y: y}, nil
+	diag.Position = Point{X: x, Y: y}
+	return diag.Position, diag, nil
 }
 
 /*
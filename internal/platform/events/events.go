@@ -0,0 +1,225 @@
+// Package events guarda las observaciones de los satélites como un flujo
+// inmutable de eventos. El estado actual del repositorio es una proyección de
+// ese flujo, y proyectarlo hasta cualquier instante reproduce el estado que
+// tenía la API en ese momento.
+package events
+
+import (
+	"errors"
+	"fuegodequasar/internal/platform/repository"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Kind es el tipo de un evento
+type Kind string
+
+const (
+	// KindSatelliteRegistered da de alta un satélite con su posición y estado
+	KindSatelliteRegistered Kind = "satellite_registered"
+	// KindSatelliteUpdated cambia la posición o el estado de un satélite existente
+	KindSatelliteUpdated Kind = "satellite_updated"
+	// KindSatelliteRemoved da de baja un satélite
+	KindSatelliteRemoved Kind = "satellite_removed"
+	// KindReadingReceived registra la distancia y el mensaje recibidos por un satélite
+	KindReadingReceived Kind = "reading_received"
+	// KindReadingCleared descarta la lectura de un satélite
+	KindReadingCleared Kind = "reading_cleared"
+	// KindTransmissionClosed cierra la transmisión en curso descartando todas las lecturas
+	KindTransmissionClosed Kind = "transmission_closed"
+)
+
+// Event es un hecho inmutable del flujo. Satellite está vacío en los eventos
+// que afectan a toda la constelación.
+type Event struct {
+	Seq       uint64    `json:"seq"`
+	Time      time.Time `json:"time"`
+	Kind      Kind      `json:"kind"`
+	Satellite string    `json:"satellite,omitempty"`
+	// Version es la versión que deja el evento en el satélite. Los eventos que
+	// genera una misma escritura comparten versión.
+	Version  uint64             `json:"version,omitempty"`
+	Position *repository.Point  `json:"position,omitempty"`
+	Status   *repository.Status `json:"status,omitempty"`
+	Distance float32            `json:"distance,omitempty"`
+	Message  []string           `json:"message,omitempty"`
+}
+
+// ErrBeyondHorizon indica que el punto pedido es anterior al horizonte de
+// reproducción: sus eventos ya se compactaron en la instantánea
+var ErrBeyondHorizon = errors.New("point is before the replay horizon")
+
+// Retention acota los eventos que conserva el flujo. Los eventos que salen de
+// la retención se compactan en una instantánea, así que el estado actual no se
+// pierde, pero el flujo solo se puede listar y reproducir a partir del
+// horizonte: la secuencia del último evento compactado.
+type Retention struct {
+	// MaxAge compacta los eventos más antiguos que este intervalo
+	MaxAge time.Duration
+	// MaxEvents conserva solo los eventos más recientes
+	MaxEvents int
+}
+
+// Store es el flujo de eventos en memoria, de solo anexado, con retención
+type Store struct {
+	retention Retention
+	now       func() time.Time
+
+	mutex sync.RWMutex
+	// snapshot es la proyección de los eventos compactados; su Seq es el horizonte
+	snapshot *Projection
+	// snapshotTime es el instante del último evento compactado
+	snapshotTime time.Time
+	events       []Event
+}
+
+// NewStore crea un flujo vacío con la retención indicada
+func NewStore(retention Retention) *Store {
+	return &Store{retention: retention, now: time.Now, snapshot: NewProjection()}
+}
+
+// Append añade los eventos al flujo en orden, asignándoles número de secuencia
+// y el mismo instante, y los devuelve tal como quedaron guardados
+func (s *Store) Append(events ...Event) []Event {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now().UTC()
+	appended := make([]Event, len(events))
+	for i, event := range events {
+		event.Seq = s.snapshot.Seq + uint64(len(s.events)) + 1
+		event.Time = now
+		event.Message = slices.Clone(event.Message)
+		s.events = append(s.events, event)
+		appended[i] = clone(event)
+	}
+	s.compact()
+	return appended
+}
+
+// compact aplica la retención llevando a la instantánea los eventos que
+// sobran; requiere el mutex tomado. Los eventos se anexan en orden
+// cronológico, así que los que sobran están siempre al principio.
+func (s *Store) compact() {
+	end := 0
+	if s.retention.MaxAge > 0 {
+		cutoff := s.now().Add(-s.retention.MaxAge)
+		for end < len(s.events) && s.events[end].Time.Before(cutoff) {
+			end++
+		}
+	}
+	if limit := s.retention.MaxEvents; limit > 0 && len(s.events)-end > limit {
+		end = len(s.events) - limit
+	}
+	if end == 0 {
+		return
+	}
+	for _, event := range s.events[:end] {
+		s.snapshot.Apply(event)
+	}
+	s.snapshotTime = s.events[end-1].Time
+	s.events = s.events[end:]
+	// Copiar si se ha compactado mucho para no retener el array original
+	if cap(s.events) > 2*len(s.events)+16 {
+		s.events = slices.Clone(s.events)
+	}
+}
+
+// Len devuelve el número de eventos anexados al flujo, incluidos los compactados
+func (s *Store) Len() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return int(s.snapshot.Seq) + len(s.events)
+}
+
+// Horizon devuelve la secuencia del último evento compactado: los eventos
+// hasta ella, incluida, ya no se pueden listar ni reproducir por separado
+func (s *Store) Horizon() uint64 {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.snapshot.Seq
+}
+
+// Since devuelve hasta limit eventos con secuencia mayor que seq; limit 0 no
+// limita. Los eventos compactados no se devuelven.
+func (s *Store) Since(seq uint64, limit int) []Event {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	start := int(min(max(seq, s.snapshot.Seq)-s.snapshot.Seq, uint64(len(s.events))))
+	end := len(s.events)
+	if limit > 0 {
+		end = min(start+limit, end)
+	}
+	return cloneAll(s.events[start:end])
+}
+
+// Replay devuelve la proyección del flujo completo
+func (s *Store) Replay() *Projection {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.replayLocked(len(s.events))
+}
+
+// ReplayUntil devuelve la proyección de los eventos ocurridos hasta el instante
+// at, incluido, o ErrBeyondHorizon si at es anterior al horizonte
+func (s *Store) ReplayUntil(at time.Time) (*Projection, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.snapshot.Seq > 0 && at.Before(s.snapshotTime) {
+		return nil, ErrBeyondHorizon
+	}
+	end, _ := slices.BinarySearchFunc(s.events, at, func(e Event, t time.Time) int {
+		if e.Time.After(t) {
+			return 1
+		}
+		return -1
+	})
+	return s.replayLocked(end), nil
+}
+
+// ReplayUntilSeq devuelve la proyección de los eventos con secuencia menor o
+// igual que seq, o ErrBeyondHorizon si seq es anterior al horizonte
+func (s *Store) ReplayUntilSeq(seq uint64) (*Projection, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if seq < s.snapshot.Seq {
+		return nil, ErrBeyondHorizon
+	}
+	return s.replayLocked(int(min(seq-s.snapshot.Seq, uint64(len(s.events))))), nil
+}
+
+// replayLocked aplica sobre una copia de la instantánea los primeros end
+// eventos conservados; requiere el mutex tomado
+func (s *Store) replayLocked(end int) *Projection {
+	projection := s.snapshot.clone()
+	for _, event := range s.events[:end] {
+		projection.Apply(clone(event))
+	}
+	return projection
+}
+
+func clone(event Event) Event {
+	event.Message = slices.Clone(event.Message)
+	if event.Position != nil {
+		position := *event.Position
+		event.Position = &position
+	}
+	if event.Status != nil {
+		status := *event.Status
+		event.Status = &status
+	}
+	return event
+}
+
+func cloneAll(events []Event) []Event {
+	result := make([]Event, len(events))
+	for i, event := range events {
+		result[i] = clone(event)
+	}
+	return result
+}
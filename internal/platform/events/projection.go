@@ -0,0 +1,107 @@
+package events
+
+import (
+	"fuegodequasar/internal/platform/repository"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Projection es el estado de los satélites que resulta de aplicar eventos en orden
+type Projection struct {
+	satellites map[string]repository.Satellite
+	// Seq es la secuencia del último evento aplicado
+	Seq uint64
+}
+
+// NewProjection crea una proyección vacía
+func NewProjection() *Projection {
+	return &Projection{satellites: make(map[string]repository.Satellite)}
+}
+
+// Project construye la proyección de una secuencia de eventos
+func Project(events []Event) *Projection {
+	projection := NewProjection()
+	for _, event := range events {
+		projection.Apply(event)
+	}
+	return projection
+}
+
+// clone devuelve una copia independiente de la proyección. Los mensajes se
+// pueden compartir porque Apply los sustituye en lugar de modificarlos.
+func (p *Projection) clone() *Projection {
+	return &Projection{satellites: maps.Clone(p.satellites), Seq: p.Seq}
+}
+
+// Apply aplica un evento sobre la proyección
+func (p *Projection) Apply(event Event) {
+	p.Seq = event.Seq
+
+	if event.Kind == KindTransmissionClosed {
+		for name, satellite := range p.satellites {
+			satellite.Distance = 0
+			satellite.Message = nil
+			satellite.Version++
+			satellite.UpdatedAt = event.Time
+			p.satellites[name] = satellite
+		}
+		return
+	}
+
+	satellite, exists := p.satellites[event.Satellite]
+	switch event.Kind {
+	case KindSatelliteRegistered:
+		satellite = repository.Satellite{Name: event.Satellite}
+		applyMetadata(&satellite, event)
+	case KindSatelliteUpdated:
+		applyMetadata(&satellite, event)
+	case KindSatelliteRemoved:
+		delete(p.satellites, event.Satellite)
+		return
+	case KindReadingReceived:
+		satellite.Distance = event.Distance
+		satellite.Message = slices.Clone(event.Message)
+	case KindReadingCleared:
+		satellite.Distance = 0
+		satellite.Message = nil
+	default:
+		return
+	}
+	if !exists && event.Kind != KindSatelliteRegistered {
+		// Un evento sobre un satélite no registrado no tiene efecto
+		return
+	}
+	satellite.Version = event.Version
+	satellite.UpdatedAt = event.Time
+	p.satellites[event.Satellite] = satellite
+}
+
+func applyMetadata(satellite *repository.Satellite, event Event) {
+	if event.Position != nil {
+		satellite.Position = *event.Position
+	}
+	if event.Status != nil {
+		satellite.Status = *event.Status
+	}
+}
+
+// Get devuelve una copia del satélite y si existe
+func (p *Projection) Get(name string) (repository.Satellite, bool) {
+	satellite, exists := p.satellites[name]
+	satellite.Message = slices.Clone(satellite.Message)
+	return satellite, exists
+}
+
+// Satellites devuelve copias de todos los satélites ordenados por nombre
+func (p *Projection) Satellites() []repository.Satellite {
+	satellites := make([]repository.Satellite, 0, len(p.satellites))
+	for _, satellite := range p.satellites {
+		satellite.Message = slices.Clone(satellite.Message)
+		satellites = append(satellites, satellite)
+	}
+	slices.SortFunc(satellites, func(a, b repository.Satellite) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return satellites
+}
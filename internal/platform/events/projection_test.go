@@ -0,0 +1,290 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fuegodequasar/internal/platform/repository"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestProjectionApply(t *testing.T) {
+	position := &repository.Point{X: -500, Y: -200}
+	moved := &repository.Point{X: 1, Y: 2}
+	maintenance := &repository.Status{State: repository.StatusMaintenance, Reason: "antenna"}
+
+	tests := []struct {
+		name   string
+		events []Event
+		want   []repository.Satellite
+	}{
+		{"registered", []Event{
+			{Seq: 1, Kind: KindSatelliteRegistered, Satellite: "kenobi", Version: 1, Position: position},
+		}, []repository.Satellite{{Name: "kenobi", Position: *position, Version: 1}}},
+		{"reading received", []Event{
+			{Seq: 1, Kind: KindSatelliteRegistered, Satellite: "kenobi", Version: 1, Position: position},
+			{Seq: 2, Kind: KindReadingReceived, Satellite: "kenobi", Version: 2, Distance: 100, Message: []string{"este", ""}},
+		}, []repository.Satellite{{Name: "kenobi", Position: *position, Version: 2, Distance: 100, Message: []string{"este", ""}}}},
+		{"updated keeps the reading", []Event{
+			{Seq: 1, Kind: KindSatelliteRegistered, Satellite: "kenobi", Version: 1, Position: position},
+			{Seq: 2, Kind: KindReadingReceived, Satellite: "kenobi", Version: 2, Distance: 100},
+			{Seq: 3, Kind: KindSatelliteUpdated, Satellite: "kenobi", Version: 3, Position: moved, Status: maintenance},
+		}, []repository.Satellite{{Name: "kenobi", Position: *moved, Status: *maintenance, Version: 3, Distance: 100}}},
+		{"reading cleared", []Event{
+			{Seq: 1, Kind: KindSatelliteRegistered, Satellite: "kenobi", Version: 1, Position: position},
+			{Seq: 2, Kind: KindReadingReceived, Satellite: "kenobi", Version: 2, Distance: 100, Message: []string{"este"}},
+			{Seq: 3, Kind: KindReadingCleared, Satellite: "kenobi", Version: 3},
+		}, []repository.Satellite{{Name: "kenobi", Position: *position, Version: 3}}},
+		{"removed", []Event{
+			{Seq: 1, Kind: KindSatelliteRegistered, Satellite: "kenobi", Version: 1},
+			{Seq: 2, Kind: KindSatelliteRegistered, Satellite: "sato", Version: 1},
+			{Seq: 3, Kind: KindSatelliteRemoved, Satellite: "kenobi"},
+		}, []repository.Satellite{{Name: "sato", Version: 1}}},
+		{"transmission closed", []Event{
+			{Seq: 1, Kind: KindSatelliteRegistered, Satellite: "kenobi", Version: 1},
+			{Seq: 2, Kind: KindReadingReceived, Satellite: "kenobi", Version: 2, Distance: 100},
+			{Seq: 3, Kind: KindSatelliteRegistered, Satellite: "sato", Version: 1},
+			{Seq: 4, Kind: KindTransmissionClosed},
+		}, []repository.Satellite{{Name: "kenobi", Version: 3}, {Name: "sato", Version: 2}}},
+		{"unregistered satellite", []Event{
+			{Seq: 1, Kind: KindReadingReceived, Satellite: "yoda", Version: 1, Distance: 100},
+			{Seq: 2, Kind: KindSatelliteUpdated, Satellite: "yoda", Version: 2, Position: position},
+		}, []repository.Satellite{}},
+		{"unknown kind", []Event{
+			{Seq: 1, Kind: KindSatelliteRegistered, Satellite: "kenobi", Version: 1},
+			{Seq: 2, Kind: "satellite_renamed", Satellite: "kenobi", Version: 2},
+		}, []repository.Satellite{{Name: "kenobi", Version: 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projection := Project(tt.events)
+			got := projection.Satellites()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Satellites() = %+v, want %+v", got, tt.want)
+			}
+			if want := tt.events[len(tt.events)-1].Seq; projection.Seq != want {
+				t.Fatalf("Seq = %d, want %d", projection.Seq, want)
+			}
+		})
+	}
+}
+
+func TestProjectionGetReturnsCopies(t *testing.T) {
+	projection := Project([]Event{
+		{Seq: 1, Kind: KindSatelliteRegistered, Satellite: "kenobi", Version: 1},
+		{Seq: 2, Kind: KindReadingReceived, Satellite: "kenobi", Version: 2, Message: []string{"este"}},
+	})
+	satellite, _ := projection.Get("kenobi")
+	satellite.Message[0] = "otro"
+	if again, _ := projection.Get("kenobi"); again.Message[0] != "este" {
+		t.Fatalf("Get() shares the message with the projection: %v", again.Message)
+	}
+}
+
+// TestReplay comprueba que proyectar el flujo hasta cada evento reproduce el
+// estado que devolvía el repositorio en ese momento
+func TestReplay(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(Retention{})
+	repo := NewRepository(store, repository.DefaultConstellation())
+
+	kenobi, err := repo.GetSatellite(ctx, "kenobi")
+	if err != nil {
+		t.Fatalf("GetSatellite() = %v", err)
+	}
+	withReading := kenobi
+	withReading.Distance, withReading.Message = 100, []string{"este", "", "un"}
+	inMaintenance := withReading
+	inMaintenance.Status = repository.Status{State: repository.StatusMaintenance, Since: time.Unix(0, 0).UTC()}
+
+	writes := []struct {
+		name  string
+		write func() error
+	}{
+		{"reading", func() error { return repo.SaveSatellite(ctx, withReading) }},
+		{"status", func() error { return repo.SaveSatellite(ctx, inMaintenance) }},
+		{"cleared", func() error { return repo.ClearSatellite(ctx, "kenobi") }},
+		{"new satellite", func() error {
+			return repo.SaveSatellite(ctx, repository.Satellite{Name: "yoda", Distance: 50, Message: []string{"es"}})
+		}},
+		{"transmission closed", func() error { return repo.ClearAllSatellites(ctx) }},
+		{"replaced", func() error {
+			return repo.ReplaceAllSatellites(ctx, []repository.Satellite{withReading})
+		}},
+	}
+
+	snapshots := map[uint64][]repository.Satellite{}
+	snapshot := func() {
+		satellites, err := repo.GetAllSatellites(ctx)
+		if err != nil {
+			t.Fatalf("GetAllSatellites() = %v", err)
+		}
+		snapshots[uint64(store.Len())] = satellites
+	}
+	snapshot()
+	for _, w := range writes {
+		if err := w.write(); err != nil {
+			t.Fatalf("%s: %v", w.name, err)
+		}
+		snapshot()
+	}
+
+	for seq, want := range snapshots {
+		projection, err := store.ReplayUntilSeq(seq)
+		if err != nil {
+			t.Fatalf("ReplayUntilSeq(%d) = %v", seq, err)
+		}
+		got := projection.Satellites()
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("projection up to seq %d = %+v, want %+v", seq, got, want)
+		}
+	}
+
+	// Un repositorio nuevo sobre el mismo flujo recupera el último estado
+	rebuilt, err := NewRepository(store, nil).GetAllSatellites(ctx)
+	if err != nil {
+		t.Fatalf("GetAllSatellites() = %v", err)
+	}
+	if want := snapshots[uint64(store.Len())]; !reflect.DeepEqual(rebuilt, want) {
+		t.Fatalf("rebuilt repository = %+v, want %+v", rebuilt, want)
+	}
+}
+
+func TestStoreSince(t *testing.T) {
+	store := NewStore(Retention{})
+	store.Append(
+		Event{Kind: KindSatelliteRegistered, Satellite: "kenobi"},
+		Event{Kind: KindSatelliteRegistered, Satellite: "skywalker"},
+		Event{Kind: KindSatelliteRegistered, Satellite: "sato"},
+	)
+
+	tests := []struct {
+		name  string
+		seq   uint64
+		limit int
+		want  []uint64
+	}{
+		{"all", 0, 0, []uint64{1, 2, 3}},
+		{"after seq", 1, 0, []uint64{2, 3}},
+		{"limited", 0, 2, []uint64{1, 2}},
+		{"after seq and limited", 1, 1, []uint64{2}},
+		{"past the end", 5, 0, []uint64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]uint64, 0)
+			for _, event := range store.Since(tt.seq, tt.limit) {
+				got = append(got, event.Seq)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Since(%d, %d) = %v, want %v", tt.seq, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestStoreCompaction comprueba que los eventos que salen de la retención se
+// compactan sin perder el estado y que solo se reproduce desde el horizonte
+func TestStoreCompaction(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(Retention{MaxEvents: 4})
+	repo := NewRepository(store, repository.DefaultConstellation())
+
+	snapshots := map[uint64][]repository.Satellite{}
+	for i := range 5 {
+		reading := repository.Satellite{Name: "kenobi", Position: repository.Point{X: -500, Y: -200}, Distance: float32(100 + i), Message: []string{"este"}}
+		if err := repo.SaveSatellite(ctx, reading); err != nil {
+			t.Fatalf("SaveSatellite() = %v", err)
+		}
+		satellites, err := repo.GetAllSatellites(ctx)
+		if err != nil {
+			t.Fatalf("GetAllSatellites() = %v", err)
+		}
+		snapshots[uint64(store.Len())] = satellites
+	}
+
+	// 3 altas y 5 lecturas, de las que se conservan las 4 últimas
+	if store.Len() != 8 || store.Horizon() != 4 {
+		t.Fatalf("Len() = %d and Horizon() = %d, want 8 and 4", store.Len(), store.Horizon())
+	}
+	var seqs []uint64
+	for _, event := range store.Since(0, 0) {
+		seqs = append(seqs, event.Seq)
+	}
+	if !reflect.DeepEqual(seqs, []uint64{5, 6, 7, 8}) {
+		t.Fatalf("Since(0, 0) = %v, want the retained events", seqs)
+	}
+
+	for seq, want := range snapshots {
+		projection, err := store.ReplayUntilSeq(seq)
+		if seq < store.Horizon() {
+			if !errors.Is(err, ErrBeyondHorizon) {
+				t.Errorf("ReplayUntilSeq(%d) = %v, want ErrBeyondHorizon", seq, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("ReplayUntilSeq(%d) = %v", seq, err)
+		}
+		if got := projection.Satellites(); projection.Seq != seq || !reflect.DeepEqual(got, want) {
+			t.Errorf("projection up to seq %d = %+v at seq %d, want %+v", seq, got, projection.Seq, want)
+		}
+	}
+
+	// Un repositorio nuevo sobre el flujo compactado recupera el último estado
+	rebuilt, err := NewRepository(store, nil).GetAllSatellites(ctx)
+	if err != nil {
+		t.Fatalf("GetAllSatellites() = %v", err)
+	}
+	if want := snapshots[uint64(store.Len())]; !reflect.DeepEqual(rebuilt, want) {
+		t.Fatalf("rebuilt repository = %+v, want %+v", rebuilt, want)
+	}
+}
+
+func TestStoreCompactionByAge(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	store := NewStore(Retention{MaxAge: time.Hour})
+	store.now = func() time.Time { return now }
+
+	store.Append(Event{Kind: KindSatelliteRegistered, Satellite: "kenobi", Version: 1})
+	first := now
+	now = now.Add(30 * time.Minute)
+	store.Append(Event{Kind: KindReadingReceived, Satellite: "kenobi", Version: 2, Distance: 100})
+	if store.Horizon() != 0 {
+		t.Fatalf("Horizon() = %d within the retention, want 0", store.Horizon())
+	}
+
+	// El alta sale de la retención pero sigue en la instantánea
+	now = now.Add(45 * time.Minute)
+	store.Append(Event{Kind: KindReadingCleared, Satellite: "kenobi", Version: 3})
+	if store.Horizon() != 1 {
+		t.Fatalf("Horizon() = %d, want 1", store.Horizon())
+	}
+
+	tests := []struct {
+		name    string
+		at      time.Time
+		wantErr error
+		wantSeq uint64
+	}{
+		{"before the horizon", first.Add(-time.Second), ErrBeyondHorizon, 0},
+		{"at the horizon", first, nil, 1},
+		{"after a retained event", first.Add(30 * time.Minute), nil, 2},
+		{"now", now, nil, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projection, err := store.ReplayUntil(tt.at)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReplayUntil() = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if _, exists := projection.Get("kenobi"); projection.Seq != tt.wantSeq || !exists {
+				t.Fatalf("projection at seq %d, want %d with kenobi", projection.Seq, tt.wantSeq)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package events
+
+import (
+	"context"
+	"fuegodequasar/internal/platform/repository"
+	"slices"
+	"sync"
+)
+
+// Repository implementa repository.RepositoryService sobre un flujo de
+// eventos: cada escritura se traduce en eventos que se anexan al flujo y se
+// aplican a la proyección de la que se sirven las lecturas.
+type Repository struct {
+	store    *Store
+	notifier repository.Notifier
+
+	mutex      sync.RWMutex
+	projection *Projection
+}
+
+// NewRepository reconstruye el estado a partir del flujo. Si el flujo está
+// vacío registra antes los satélites de constellation.
+func NewRepository(store *Store, constellation []repository.Satellite) *Repository {
+	if store.Len() == 0 {
+		registered := make([]Event, 0, len(constellation))
+		for _, satellite := range constellation {
+			registered = append(registered, saveEvents(repository.Satellite{}, false, satellite, 1)...)
+		}
+		store.Append(registered...)
+	}
+	return &Repository{
+		store:      store,
+		projection: store.Replay(),
+	}
+}
+
+// Store devuelve el flujo de eventos sobre el que trabaja el repositorio
+func (r *Repository) Store() *Store {
+	return r.store
+}
+
+func (r *Repository) GetSatellite(ctx context.Context, name string) (repository.Satellite, error) {
+	if err := ctx.Err(); err != nil {
+		return repository.Satellite{}, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if satellite, exists := r.projection.Get(name); exists {
+		return satellite, nil
+	}
+	return repository.Satellite{}, repository.ErrSatelliteNotFound
+}
+
+func (r *Repository) GetAllSatellites(ctx context.Context) ([]repository.Satellite, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.projection.Satellites(), nil
+}
+
+func (r *Repository) SaveSatellite(ctx context.Context, satellite repository.Satellite) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.save(satellite)
+	return nil
+}
+
+func (r *Repository) SaveSatelliteIfVersion(ctx context.Context, satellite repository.Satellite, version uint64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if current, _ := r.projection.Get(satellite.Name); current.Version != version {
+		return repository.ErrVersionConflict
+	}
+	r.save(satellite)
+	return nil
+}
+
+func (r *Repository) ClearSatellite(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	current, exists := r.projection.Get(name)
+	if !exists {
+		return repository.ErrSatelliteNotFound
+	}
+	r.commit(Event{Kind: KindReadingCleared, Satellite: name, Version: current.Version + 1})
+	return nil
+}
+
+func (r *Repository) ClearAllSatellites(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.commit(Event{Kind: KindTransmissionClosed})
+	return nil
+}
+
+func (r *Repository) ReplaceAllSatellites(ctx context.Context, satellites []repository.Satellite) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	keep := make(map[string]bool, len(satellites))
+	for _, satellite := range satellites {
+		keep[satellite.Name] = true
+	}
+	var removed []Event
+	for _, current := range r.projection.Satellites() {
+		if !keep[current.Name] {
+			removed = append(removed, Event{Kind: KindSatelliteRemoved, Satellite: current.Name})
+		}
+	}
+	r.commit(removed...)
+	for _, satellite := range satellites {
+		r.save(satellite)
+	}
+	return nil
+}
+
+// save traduce la escritura de un satélite en eventos y los aplica; requiere el mutex tomado
+func (r *Repository) save(satellite repository.Satellite) {
+	current, exists := r.projection.Get(satellite.Name)
+	r.commit(saveEvents(current, exists, satellite, current.Version+1)...)
+}
+
+func (r *Repository) Subscribe(ctx context.Context, buffer int) (*repository.Subscription, error) {
+	return r.notifier.Subscribe(ctx, buffer)
+}
+
+// commit anexa los eventos al flujo, los aplica a la proyección y notifica un
+// cambio por cada satélite afectado; requiere el mutex tomado
+func (r *Repository) commit(events ...Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	var changes []repository.Change
+	for _, event := range r.store.Append(events...) {
+		// El último estado de un satélite que se da de baja hay que leerlo antes de aplicar
+		removed, _ := r.projection.Get(event.Satellite)
+		r.projection.Apply(event)
+
+		switch event.Kind {
+		case KindTransmissionClosed:
+			for _, satellite := range r.projection.Satellites() {
+				changes = append(changes, repository.Change{Kind: repository.ChangeCleared, Satellite: satellite})
+			}
+		case KindSatelliteRemoved:
+			changes = append(changes, repository.Change{Kind: repository.ChangeRemoved, Satellite: removed})
+		default:
+			satellite, _ := r.projection.Get(event.Satellite)
+			kind := repository.ChangeSaved
+			if event.Kind == KindReadingCleared {
+				kind = repository.ChangeCleared
+			}
+			// Los eventos de una misma escritura comparten versión: se notifica una sola vez
+			if n := len(changes); n > 0 && changes[n-1].Satellite.Name == satellite.Name &&
+				changes[n-1].Satellite.Version == satellite.Version {
+				changes[n-1] = repository.Change{Kind: kind, Satellite: satellite}
+				continue
+			}
+			changes = append(changes, repository.Change{Kind: kind, Satellite: satellite})
+		}
+	}
+	r.notifier.Publish(changes...)
+}
+
+// saveEvents calcula los eventos que llevan un satélite del estado current al
+// estado satellite, todos con la versión indicada
+func saveEvents(current repository.Satellite, exists bool, satellite repository.Satellite, version uint64) []Event {
+	position, status := satellite.Position, satellite.Status
+	if !exists {
+		events := []Event{{
+			Kind:      KindSatelliteRegistered,
+			Satellite: satellite.Name,
+			Version:   version,
+			Position:  &position,
+			Status:    &status,
+		}}
+		if hasReading(satellite) {
+			events = append(events, readingEvent(satellite, version))
+		}
+		return events
+	}
+
+	var events []Event
+	metadataChanged := current.Position != satellite.Position || current.Status != satellite.Status
+	if metadataChanged {
+		events = append(events, Event{
+			Kind:      KindSatelliteUpdated,
+			Satellite: satellite.Name,
+			Version:   version,
+			Position:  &position,
+			Status:    &status,
+		})
+	}
+	// Reenviar la misma lectura también es una lectura recibida; solo se omite
+	// cuando la escritura cambia posición o estado y deja la lectura igual
+	sameReading := current.Distance == satellite.Distance && slices.Equal(current.Message, satellite.Message)
+	if !sameReading || !metadataChanged {
+		if hasReading(satellite) {
+			events = append(events, readingEvent(satellite, version))
+		} else {
+			events = append(events, Event{Kind: KindReadingCleared, Satellite: satellite.Name, Version: version})
+		}
+	}
+	return events
+}
+
+func readingEvent(satellite repository.Satellite, version uint64) Event {
+	return Event{
+		Kind:      KindReadingReceived,
+		Satellite: satellite.Name,
+		Version:   version,
+		Distance:  satellite.Distance,
+		Message:   satellite.Message,
+	}
+}
+
+func hasReading(satellite repository.Satellite) bool {
+	return satellite.Distance != 0 || len(satellite.Message) > 0
+}
@@ -0,0 +1,14 @@
+package events_test
+
+import (
+	"fuegodequasar/internal/platform/events"
+	"fuegodequasar/internal/platform/repository"
+	"fuegodequasar/internal/platform/repository/repositorytest"
+	"testing"
+)
+
+func TestRepositoryConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.RepositoryService {
+		return events.NewRepository(events.NewStore(events.Retention{}), repository.DefaultConstellation())
+	})
+}
@@ -0,0 +1,214 @@
+// Package history guarda cada lectura recibida de los satélites como una serie
+// temporal, con retención configurable, para poder analizar la deriva de las
+// distancias medidas.
+package history
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Reading es una lectura recibida de un satélite en un momento dado
+type Reading struct {
+	Satellite string    `json:"satellite"`
+	Time      time.Time `json:"time"`
+	Distance  float32   `json:"distance"`
+	Message   []string  `json:"message"`
+}
+
+// Retention limita cuántas lecturas se conservan. Los valores cero no limitan.
+type Retention struct {
+	// MaxAge descarta las lecturas más antiguas que este intervalo
+	MaxAge time.Duration
+	// MaxPerSatellite conserva solo las lecturas más recientes de cada satélite
+	MaxPerSatellite int
+}
+
+// Query selecciona una página de lecturas de un satélite
+type Query struct {
+	// From y To acotan el intervalo [From, To); los valores cero no acotan
+	From time.Time
+	To   time.Time
+	// Offset y Limit paginan el resultado en orden cronológico; Limit 0 no limita
+	Offset int
+	Limit  int
+}
+
+// WindowStats resume las distancias de las lecturas de una ventana de tiempo
+type WindowStats struct {
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Count  int       `json:"count"`
+	Mean   float64   `json:"mean"`
+	StdDev float64   `json:"stddev"`
+	Min    float32   `json:"min"`
+	Max    float32   `json:"max"`
+}
+
+// Store guarda en memoria las series de lecturas de todos los satélites
+type Store struct {
+	retention Retention
+	now       func() time.Time
+
+	mutex    sync.RWMutex
+	readings map[string][]Reading
+}
+
+// NewStore crea un almacén vacío con la retención indicada
+func NewStore(retention Retention) *Store {
+	return &Store{
+		retention: retention,
+		now:       time.Now,
+		readings:  make(map[string][]Reading),
+	}
+}
+
+// Record añade una lectura a la serie de su satélite y aplica la retención
+func (s *Store) Record(reading Reading) {
+	if reading.Time.IsZero() {
+		reading.Time = s.now().UTC()
+	}
+	reading.Message = slices.Clone(reading.Message)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	series := s.readings[reading.Satellite]
+	// Las lecturas llegan casi siempre en orden; se inserta en su sitio por si no
+	i, _ := slices.BinarySearchFunc(series, reading.Time, func(r Reading, t time.Time) int {
+		return r.Time.Compare(t)
+	})
+	// Tras las lecturas con el mismo instante, para conservar el orden de llegada
+	for i < len(series) && series[i].Time.Equal(reading.Time) {
+		i++
+	}
+	series = slices.Insert(series, i, reading)
+	s.readings[reading.Satellite] = s.prune(series)
+}
+
+// prune aplica la retención a una serie ordenada; requiere el mutex tomado
+func (s *Store) prune(series []Reading) []Reading {
+	if s.retention.MaxAge > 0 {
+		cutoff := s.now().Add(-s.retention.MaxAge)
+		i, _ := slices.BinarySearchFunc(series, cutoff, func(r Reading, t time.Time) int {
+			return r.Time.Compare(t)
+		})
+		series = series[i:]
+	}
+	if limit := s.retention.MaxPerSatellite; limit > 0 && len(series) > limit {
+		series = series[len(series)-limit:]
+	}
+	// Copiar si se ha descartado mucho para no retener el array original
+	if cap(series) > 2*len(series)+16 {
+		series = slices.Clone(series)
+	}
+	return series
+}
+
+// Query devuelve la página de lecturas del satélite que cumple la consulta y
+// el total de lecturas en el intervalo antes de paginar
+func (s *Store) Query(satellite string, query Query) ([]Reading, int) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	matching := s.between(satellite, query.From, query.To)
+	total := len(matching)
+
+	start := min(query.Offset, total)
+	end := total
+	if query.Limit > 0 {
+		end = min(start+query.Limit, total)
+	}
+	page := make([]Reading, 0, end-start)
+	for _, reading := range matching[start:end] {
+		reading.Message = slices.Clone(reading.Message)
+		page = append(page, reading)
+	}
+	return page, total
+}
+
+// Stats calcula media, desviación típica, mínimo y máximo de las distancias del
+// satélite en ventanas consecutivas de duración window alineadas a window. Con
+// window 0 devuelve una única ventana con todo el intervalo. Las ventanas sin
+// lecturas se omiten.
+func (s *Store) Stats(satellite string, from, to time.Time, window time.Duration) []WindowStats {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	matching := s.between(satellite, from, to)
+	stats := make([]WindowStats, 0)
+	if len(matching) == 0 {
+		return stats
+	}
+
+	var current *WindowStats
+	var sum, sumSquares float64
+	closeWindow := func() {
+		if current == nil {
+			return
+		}
+		n := float64(current.Count)
+		current.Mean = sum / n
+		// Varianza poblacional; max evita negativos por redondeo
+		current.StdDev = math.Sqrt(math.Max(0, sumSquares/n-current.Mean*current.Mean))
+		stats = append(stats, *current)
+	}
+
+	for _, reading := range matching {
+		windowFrom, windowTo := from, to
+		if window > 0 {
+			windowFrom = reading.Time.Truncate(window)
+			windowTo = windowFrom.Add(window)
+		} else {
+			if windowFrom.IsZero() {
+				windowFrom = matching[0].Time
+			}
+			if windowTo.IsZero() {
+				windowTo = matching[len(matching)-1].Time
+			}
+		}
+		if current == nil || !current.From.Equal(windowFrom) {
+			closeWindow()
+			current = &WindowStats{From: windowFrom, To: windowTo, Min: reading.Distance, Max: reading.Distance}
+			sum, sumSquares = 0, 0
+		}
+
+		d := float64(reading.Distance)
+		current.Count++
+		sum += d
+		sumSquares += d * d
+		current.Min = min(current.Min, reading.Distance)
+		current.Max = max(current.Max, reading.Distance)
+	}
+	closeWindow()
+	return stats
+}
+
+// between devuelve la subserie dentro de [from, to) sin copiarla; requiere el mutex tomado
+func (s *Store) between(satellite string, from, to time.Time) []Reading {
+	series := s.readings[satellite]
+	if s.retention.MaxAge > 0 {
+		// Las lecturas caducadas no se devuelven aunque aún no se hayan purgado
+		if cutoff := s.now().Add(-s.retention.MaxAge); from.Before(cutoff) {
+			from = cutoff
+		}
+	}
+
+	start, end := 0, len(series)
+	if !from.IsZero() {
+		start, _ = slices.BinarySearchFunc(series, from, func(r Reading, t time.Time) int {
+			return r.Time.Compare(t)
+		})
+	}
+	if !to.IsZero() {
+		end, _ = slices.BinarySearchFunc(series, to, func(r Reading, t time.Time) int {
+			return r.Time.Compare(t)
+		})
+	}
+	if start > end {
+		return nil
+	}
+	return series[start:end]
+}
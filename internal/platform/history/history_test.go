@@ -0,0 +1,167 @@
+package history
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2026, time.January, 1, 10, 0, 0, 0, time.UTC)
+
+// newTestStore crea un almacén con una lectura de kenobi por minuto desde
+// start, con las distancias indicadas, y con el reloj en el último minuto
+func newTestStore(retention Retention, distances ...float32) *Store {
+	s := NewStore(retention)
+	s.now = func() time.Time { return start.Add(time.Duration(len(distances)-1) * time.Minute) }
+	for i, distance := range distances {
+		s.Record(Reading{Satellite: "kenobi", Time: start.Add(time.Duration(i) * time.Minute), Distance: distance})
+	}
+	return s
+}
+
+func distancesOf(readings []Reading) []float32 {
+	result := make([]float32, len(readings))
+	for i, reading := range readings {
+		result[i] = reading.Distance
+	}
+	return result
+}
+
+func TestStoreRetention(t *testing.T) {
+	tests := []struct {
+		name      string
+		retention Retention
+		want      []float32
+	}{
+		{"unlimited", Retention{}, []float32{1, 2, 3, 4}},
+		{"max per satellite", Retention{MaxPerSatellite: 2}, []float32{3, 4}},
+		{"max age", Retention{MaxAge: 90 * time.Second}, []float32{3, 4}},
+		{"both", Retention{MaxAge: 150 * time.Second, MaxPerSatellite: 1}, []float32{4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStore(tt.retention, 1, 2, 3, 4)
+			got, total := s.Query("kenobi", Query{})
+			if !slices.Equal(distancesOf(got), tt.want) || total != len(tt.want) {
+				t.Fatalf("Query() = %v (total %d), want %v", distancesOf(got), total, tt.want)
+			}
+		})
+	}
+}
+
+func TestStoreQueryHidesExpiredReadings(t *testing.T) {
+	s := newTestStore(Retention{MaxAge: 90 * time.Second}, 1, 2, 3, 4)
+	// El reloj avanza sin nuevas lecturas: la purga no ha corrido todavía
+	s.now = func() time.Time { return start.Add(4 * time.Minute) }
+	got, _ := s.Query("kenobi", Query{})
+	if want := []float32{4}; !slices.Equal(distancesOf(got), want) {
+		t.Fatalf("Query() = %v, want %v", distancesOf(got), want)
+	}
+}
+
+func TestStoreRecordKeepsOrder(t *testing.T) {
+	s := NewStore(Retention{})
+	for _, reading := range []Reading{
+		{Satellite: "kenobi", Time: start.Add(2 * time.Minute), Distance: 3},
+		{Satellite: "kenobi", Time: start, Distance: 1},
+		{Satellite: "kenobi", Time: start.Add(time.Minute), Distance: 2},
+		// Mismo instante que la anterior: va detrás, en orden de llegada
+		{Satellite: "kenobi", Time: start.Add(time.Minute), Distance: 2.5},
+		{Satellite: "sato", Time: start, Distance: 9},
+	} {
+		s.Record(reading)
+	}
+	got, _ := s.Query("kenobi", Query{})
+	if want := []float32{1, 2, 2.5, 3}; !slices.Equal(distancesOf(got), want) {
+		t.Fatalf("Query() = %v, want %v", distancesOf(got), want)
+	}
+}
+
+func TestStoreQuery(t *testing.T) {
+	s := newTestStore(Retention{}, 1, 2, 3, 4, 5)
+
+	tests := []struct {
+		name      string
+		query     Query
+		want      []float32
+		wantTotal int
+	}{
+		{"all", Query{}, []float32{1, 2, 3, 4, 5}, 5},
+		{"from", Query{From: start.Add(2 * time.Minute)}, []float32{3, 4, 5}, 3},
+		{"to is excluded", Query{To: start.Add(2 * time.Minute)}, []float32{1, 2}, 2},
+		{"interval", Query{From: start.Add(time.Minute), To: start.Add(3 * time.Minute)}, []float32{2, 3}, 2},
+		{"empty interval", Query{From: start.Add(3 * time.Minute), To: start.Add(time.Minute)}, []float32{}, 0},
+		{"limit", Query{Limit: 2}, []float32{1, 2}, 5},
+		{"offset", Query{Offset: 3}, []float32{4, 5}, 5},
+		{"offset and limit", Query{Offset: 1, Limit: 2}, []float32{2, 3}, 5},
+		{"offset past the end", Query{Offset: 10}, []float32{}, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, total := s.Query("kenobi", tt.query)
+			if !slices.Equal(distancesOf(got), tt.want) || total != tt.wantTotal {
+				t.Fatalf("Query() = %v (total %d), want %v (total %d)", distancesOf(got), total, tt.want, tt.wantTotal)
+			}
+		})
+	}
+
+	if got, total := s.Query("sato", Query{}); len(got) != 0 || total != 0 {
+		t.Fatalf("Query(sato) = %v (total %d), want nothing", got, total)
+	}
+}
+
+func TestStoreQueryReturnsCopies(t *testing.T) {
+	s := NewStore(Retention{})
+	message := []string{"este", "es"}
+	s.Record(Reading{Satellite: "kenobi", Time: start, Distance: 1, Message: message})
+	message[0] = "otro"
+
+	got, _ := s.Query("kenobi", Query{})
+	got[0].Message[1] = "no"
+	again, _ := s.Query("kenobi", Query{})
+	if want := []string{"este", "es"}; !slices.Equal(again[0].Message, want) {
+		t.Fatalf("stored message = %v, want %v", again[0].Message, want)
+	}
+}
+
+func TestStoreStats(t *testing.T) {
+	// Una lectura por minuto: 100, 110 | 120, 140 | 160
+	s := newTestStore(Retention{}, 100, 110, 120, 140, 160)
+
+	tests := []struct {
+		name   string
+		from   time.Time
+		to     time.Time
+		window time.Duration
+		want   []WindowStats
+	}{
+		{"single window", time.Time{}, time.Time{}, 0, []WindowStats{
+			{From: start, To: start.Add(4 * time.Minute), Count: 5, Mean: 126, StdDev: math.Sqrt(464), Min: 100, Max: 160},
+		}},
+		{"two minute windows", time.Time{}, time.Time{}, 2 * time.Minute, []WindowStats{
+			{From: start, To: start.Add(2 * time.Minute), Count: 2, Mean: 105, StdDev: 5, Min: 100, Max: 110},
+			{From: start.Add(2 * time.Minute), To: start.Add(4 * time.Minute), Count: 2, Mean: 130, StdDev: 10, Min: 120, Max: 140},
+			{From: start.Add(4 * time.Minute), To: start.Add(6 * time.Minute), Count: 1, Mean: 160, StdDev: 0, Min: 160, Max: 160},
+		}},
+		{"bounded interval", start.Add(time.Minute), start.Add(3 * time.Minute), 0, []WindowStats{
+			{From: start.Add(time.Minute), To: start.Add(3 * time.Minute), Count: 2, Mean: 115, StdDev: 5, Min: 110, Max: 120},
+		}},
+		{"no readings", start.Add(time.Hour), time.Time{}, time.Minute, []WindowStats{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.Stats("kenobi", tt.from, tt.to, tt.window)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Stats() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				g, w := got[i], tt.want[i]
+				if !g.From.Equal(w.From) || !g.To.Equal(w.To) || g.Count != w.Count || g.Min != w.Min || g.Max != w.Max ||
+					math.Abs(g.Mean-w.Mean) > 1e-9 || math.Abs(g.StdDev-w.StdDev) > 1e-9 {
+					t.Fatalf("window %d = %+v, want %+v", i, g, w)
+				}
+			}
+		})
+	}
+}
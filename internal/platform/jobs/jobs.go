@@ -0,0 +1,305 @@
+// Package jobs ejecuta trabajos en segundo plano con una cola acotada, un
+// número fijo de workers y retención de los resultados durante un tiempo.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrQueueFull indica que la cola de trabajos pendientes está llena
+	ErrQueueFull = errors.New("job queue is full")
+	// ErrShuttingDown indica que el gestor ya no acepta trabajos
+	ErrShuttingDown = errors.New("job manager is shutting down")
+	// ErrJobNotFound indica que el trabajo no existe o ya expiró
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobFinished indica que el trabajo ya terminó y no se puede cancelar
+	ErrJobFinished = errors.New("job already finished")
+)
+
+// Status es el estado de un trabajo
+type Status string
+
+const (
+	// StatusQueued espera a que quede libre un worker
+	StatusQueued Status = "queued"
+	// StatusRunning se está ejecutando
+	StatusRunning Status = "running"
+	// StatusSucceeded terminó y tiene resultado
+	StatusSucceeded Status = "succeeded"
+	// StatusFailed terminó con error
+	StatusFailed Status = "failed"
+	// StatusCanceled se canceló antes de terminar
+	StatusCanceled Status = "canceled"
+)
+
+// Finished indica si el estado es final
+func (s Status) Finished() bool {
+	return s == StatusSucceeded || s == StatusFailed || s == StatusCanceled
+}
+
+// Func es el trabajo a ejecutar. Debe terminar en cuanto se cancele ctx.
+type Func func(ctx context.Context) (any, error)
+
+// Job es una instantánea del estado de un trabajo
+type Job struct {
+	ID         string    `json:"id" example:"6f1c2a9e0b7d4e3f8a5b6c7d8e9f0a1b"`
+	Kind       string    `json:"kind" example:"batch"`
+	Status     Status    `json:"status" example:"running"`
+	CreatedAt  time.Time `json:"created_at"`
+	StartedAt  time.Time `json:"started_at,omitzero"`
+	FinishedAt time.Time `json:"finished_at,omitzero"`
+	// Result es el resultado del trabajo cuando termina con éxito
+	Result any    `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// Problem es el detalle del error cuando el trabajo falla con un Failure
+	Problem any `json:"problem,omitempty"`
+}
+
+// Failure es el error de un trabajo que lleva además un detalle para el
+// cliente, como un problema RFC 7807, que se publica en Job.Problem
+type Failure struct {
+	Message string
+	Problem any
+}
+
+func (f *Failure) Error() string {
+	return f.Message
+}
+
+// Config configura el gestor de trabajos
+type Config struct {
+	// Workers es el número de trabajos que se ejecutan a la vez (mínimo 1)
+	Workers int
+	// QueueSize es el número de trabajos que pueden esperar turno (mínimo 1)
+	QueueSize int
+	// Retention es cuánto se conserva un trabajo terminado; 0 lo conserva siempre
+	Retention time.Duration
+}
+
+type job struct {
+	Job
+	fn     Func
+	ctx    context.Context
+	cancel context.CancelFunc
+	// canceled indica que el cliente pidió cancelar el trabajo
+	canceled bool
+}
+
+// Manager encola y ejecuta trabajos
+type Manager struct {
+	config Config
+	queue  chan *job
+	wg     sync.WaitGroup
+
+	// ctx es el padre de todos los trabajos; se cancela si el apagado no
+	// termina a tiempo
+	ctx       context.Context
+	cancelAll context.CancelFunc
+
+	mutex   sync.Mutex
+	jobs    map[string]*job
+	closing bool
+}
+
+// NewManager crea un gestor y arranca sus workers
+func NewManager(config Config) *Manager {
+	config.Workers = max(config.Workers, 1)
+	config.QueueSize = max(config.QueueSize, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Manager{
+		config:    config,
+		queue:     make(chan *job, config.QueueSize),
+		ctx:       ctx,
+		cancelAll: cancel,
+		jobs:      make(map[string]*job),
+	}
+	for range config.Workers {
+		m.wg.Add(1)
+		go m.work()
+	}
+	return m
+}
+
+// Submit encola un trabajo del tipo kind. Nunca bloquea: si la cola está llena
+// devuelve ErrQueueFull.
+func (m *Manager) Submit(kind string, fn Func) (Job, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.closing {
+		return Job{}, ErrShuttingDown
+	}
+	m.pruneLocked(time.Now())
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	j := &job{
+		Job:    Job{ID: newID(), Kind: kind, Status: StatusQueued, CreatedAt: time.Now().UTC()},
+		fn:     fn,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	select {
+	case m.queue <- j:
+	default:
+		cancel()
+		return Job{}, ErrQueueFull
+	}
+	m.jobs[j.ID] = j
+	return j.Job, nil
+}
+
+// Get devuelve el estado de un trabajo
+func (m *Manager) Get(id string) (Job, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.pruneLocked(time.Now())
+	j, exists := m.jobs[id]
+	if !exists {
+		return Job{}, ErrJobNotFound
+	}
+	return j.Job, nil
+}
+
+// Cancel cancela un trabajo. Un trabajo en cola no llega a ejecutarse; uno en
+// curso recibe la cancelación por su contexto y queda cancelado al terminar.
+func (m *Manager) Cancel(id string) (Job, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.pruneLocked(time.Now())
+	j, exists := m.jobs[id]
+	if !exists {
+		return Job{}, ErrJobNotFound
+	}
+	if j.Status.Finished() {
+		return j.Job, ErrJobFinished
+	}
+	j.canceled = true
+	j.cancel()
+	if j.Status == StatusQueued {
+		j.finishLocked(nil, context.Canceled)
+	}
+	return j.Job, nil
+}
+
+// Shutdown deja de aceptar trabajos y espera a que terminen los encolados y
+// los que están en curso. Si ctx vence antes, cancela los que quedan, espera a
+// que sus workers los den por cancelados y devuelve el error de ctx.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mutex.Lock()
+	if !m.closing {
+		m.closing = true
+		close(m.queue)
+	}
+	m.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		m.cancelAll()
+		return nil
+	case <-ctx.Done():
+		m.cancelAll()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (m *Manager) work() {
+	defer m.wg.Done()
+	for j := range m.queue {
+		m.run(j)
+	}
+}
+
+func (m *Manager) run(j *job) {
+	m.mutex.Lock()
+	if j.Status != StatusQueued {
+		// Cancelado mientras esperaba turno
+		m.mutex.Unlock()
+		return
+	}
+	if err := j.ctx.Err(); err != nil {
+		j.finishLocked(nil, err)
+		m.mutex.Unlock()
+		return
+	}
+	j.Status = StatusRunning
+	j.StartedAt = time.Now().UTC()
+	m.mutex.Unlock()
+
+	result, err := call(j)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if j.ctx.Err() != nil {
+		// El resultado de un trabajo cancelado no es fiable aunque fn no fallara
+		result, err = nil, j.ctx.Err()
+	}
+	j.finishLocked(result, err)
+	j.cancel()
+}
+
+// call ejecuta el trabajo convirtiendo un pánico en un error, para que un
+// trabajo defectuoso no se lleve por delante al worker
+func call(j *job) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("jobs: job %s panicked: %v", j.ID, r)
+			result, err = nil, errors.New("internal error")
+		}
+	}()
+	return j.fn(j.ctx)
+}
+
+// finishLocked deja el trabajo en su estado final; requiere el mutex tomado
+func (j *job) finishLocked(result any, err error) {
+	j.FinishedAt = time.Now().UTC()
+	switch {
+	case errors.Is(err, context.Canceled) && j.canceled:
+		j.Status, j.Error = StatusCanceled, "job canceled"
+	case errors.Is(err, context.Canceled):
+		j.Status, j.Error = StatusCanceled, "job canceled during shutdown"
+	case err != nil:
+		j.Status, j.Error = StatusFailed, err.Error()
+		var failure *Failure
+		if errors.As(err, &failure) {
+			j.Problem = failure.Problem
+		}
+	default:
+		j.Status, j.Result = StatusSucceeded, result
+	}
+}
+
+// pruneLocked descarta los trabajos terminados hace más de la retención;
+// requiere el mutex tomado
+func (m *Manager) pruneLocked(now time.Time) {
+	if m.config.Retention <= 0 {
+		return
+	}
+	for id, j := range m.jobs {
+		if j.Status.Finished() && now.Sub(j.FinishedAt) > m.config.Retention {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+func newID() string {
+	var b [16]byte
+	// crypto/rand.Read no falla en las plataformas soportadas
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,202 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// waitFinished espera a que el trabajo llegue a un estado final
+func waitFinished(t *testing.T, m *Manager, id string) Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := m.Get(id)
+		if err != nil {
+			t.Fatalf("Get(%s) = %v", id, err)
+		}
+		if job.Status.Finished() {
+			return job
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", id)
+	return Job{}
+}
+
+// blocking es un trabajo que avisa al empezar y termina al cerrar release o
+// al cancelarse su contexto
+func blocking(started chan<- struct{}, release <-chan struct{}) Func {
+	return func(ctx context.Context) (any, error) {
+		close(started)
+		select {
+		case <-release:
+			return "released", nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func TestManagerRunsJobs(t *testing.T) {
+	tests := []struct {
+		name        string
+		fn          Func
+		wantStatus  Status
+		wantResult  any
+		wantError   string
+		wantProblem any
+	}{
+		{"succeeded", func(context.Context) (any, error) { return 42, nil }, StatusSucceeded, 42, "", nil},
+		{"failed", func(context.Context) (any, error) { return nil, errors.New("no location") }, StatusFailed, nil, "no location", nil},
+		{"failed with problem", func(context.Context) (any, error) {
+			return nil, fmt.Errorf("locate: %w", &Failure{Message: "no location", Problem: "not_enough_satellites"})
+		}, StatusFailed, nil, "locate: no location", "not_enough_satellites"},
+		{"panicked", func(context.Context) (any, error) { panic("boom") }, StatusFailed, nil, "internal error", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(Config{})
+			defer m.Shutdown(context.Background())
+
+			submitted, err := m.Submit("locate", tt.fn)
+			if err != nil {
+				t.Fatalf("Submit() = %v", err)
+			}
+			if submitted.Status != StatusQueued || submitted.Kind != "locate" || submitted.ID == "" {
+				t.Fatalf("Submit() = %+v", submitted)
+			}
+			job := waitFinished(t, m, submitted.ID)
+			if job.Status != tt.wantStatus || job.Result != tt.wantResult || job.Error != tt.wantError || job.Problem != tt.wantProblem {
+				t.Fatalf("job = %+v, want status %q, result %v, error %q and problem %v", job, tt.wantStatus, tt.wantResult, tt.wantError, tt.wantProblem)
+			}
+			if job.StartedAt.IsZero() || job.FinishedAt.Before(job.StartedAt) {
+				t.Fatalf("job times = %v .. %v", job.StartedAt, job.FinishedAt)
+			}
+		})
+	}
+}
+
+func TestManagerQueueFull(t *testing.T) {
+	m := NewManager(Config{Workers: 1, QueueSize: 1})
+	started, release := make(chan struct{}), make(chan struct{})
+	defer m.Shutdown(context.Background())
+	defer close(release)
+
+	if _, err := m.Submit("batch", blocking(started, release)); err != nil {
+		t.Fatalf("Submit() = %v", err)
+	}
+	<-started
+	if _, err := m.Submit("batch", blocking(make(chan struct{}), release)); err != nil {
+		t.Fatalf("Submit() with a free queue slot = %v", err)
+	}
+	if _, err := m.Submit("batch", blocking(make(chan struct{}), release)); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Submit() with a full queue = %v, want ErrQueueFull", err)
+	}
+}
+
+func TestManagerCancel(t *testing.T) {
+	m := NewManager(Config{Workers: 1, QueueSize: 2})
+	defer m.Shutdown(context.Background())
+
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	running, _ := m.Submit("batch", blocking(started, release))
+	<-started
+	queued, _ := m.Submit("batch", blocking(make(chan struct{}), release))
+	finished, _ := m.Submit("locate", func(context.Context) (any, error) { return nil, nil })
+
+	tests := []struct {
+		name       string
+		id         string
+		wantErr    error
+		wantStatus Status
+	}{
+		// Un trabajo en cola queda cancelado en el acto
+		{"queued", queued.ID, nil, StatusCanceled},
+		// Uno en curso recibe la cancelación y sigue en curso hasta que termina
+		{"running", running.ID, nil, StatusRunning},
+		{"unknown", "no-such-job", ErrJobNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job, err := m.Cancel(tt.id)
+			if !errors.Is(err, tt.wantErr) || job.Status != tt.wantStatus {
+				t.Fatalf("Cancel() = %+v, %v, want status %q and error %v", job, err, tt.wantStatus, tt.wantErr)
+			}
+		})
+	}
+
+	if job := waitFinished(t, m, running.ID); job.Status != StatusCanceled || job.Error != "job canceled" {
+		t.Fatalf("canceled running job = %+v", job)
+	}
+	// El worker descarta el trabajo cancelado en cola y ejecuta el siguiente
+	waitFinished(t, m, finished.ID)
+	if _, err := m.Cancel(finished.ID); !errors.Is(err, ErrJobFinished) {
+		t.Fatalf("Cancel() of a finished job = %v, want ErrJobFinished", err)
+	}
+}
+
+func TestManagerShutdown(t *testing.T) {
+	t.Run("waits for running jobs", func(t *testing.T) {
+		m := NewManager(Config{Workers: 1, QueueSize: 2})
+		started, release := make(chan struct{}), make(chan struct{})
+		running, _ := m.Submit("batch", blocking(started, release))
+		<-started
+
+		done := make(chan error, 1)
+		go func() { done <- m.Shutdown(context.Background()) }()
+		// Tras empezar el apagado no se aceptan trabajos
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			if _, err := m.Submit("batch", func(context.Context) (any, error) { return nil, nil }); errors.Is(err, ErrShuttingDown) {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("Submit() still accepts jobs after Shutdown")
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		close(release)
+		if err := <-done; err != nil {
+			t.Fatalf("Shutdown() = %v", err)
+		}
+		if job, _ := m.Get(running.ID); job.Status != StatusSucceeded {
+			t.Fatalf("job = %+v, want it to finish", job)
+		}
+	})
+
+	t.Run("cancels jobs when ctx expires", func(t *testing.T) {
+		m := NewManager(Config{Workers: 1, QueueSize: 2})
+		started := make(chan struct{})
+		running, _ := m.Submit("batch", blocking(started, make(chan struct{})))
+		<-started
+		queued, _ := m.Submit("batch", blocking(make(chan struct{}), make(chan struct{})))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := m.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Shutdown() = %v, want context.DeadlineExceeded", err)
+		}
+		for _, id := range []string{running.ID, queued.ID} {
+			if job, _ := m.Get(id); job.Status != StatusCanceled || job.Error != "job canceled during shutdown" {
+				t.Fatalf("job = %+v, want it canceled during shutdown", job)
+			}
+		}
+	})
+}
+
+func TestManagerRetention(t *testing.T) {
+	m := NewManager(Config{Retention: 50 * time.Millisecond})
+	defer m.Shutdown(context.Background())
+
+	job, _ := m.Submit("locate", func(context.Context) (any, error) { return nil, nil })
+	waitFinished(t, m, job.ID)
+	time.Sleep(100 * time.Millisecond)
+	if _, err := m.Get(job.ID); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("Get() after the retention = %v, want ErrJobNotFound", err)
+	}
+}
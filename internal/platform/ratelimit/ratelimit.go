@@ -0,0 +1,258 @@
+// Package ratelimit limita el ritmo de peticiones de cada cliente con cubos de
+// tokens, con reglas por ruta, y lleva la cuenta de su cuota diaria.
+package ratelimit
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KeyKind indica qué identifica al cliente al que se aplica una regla
+type KeyKind string
+
+const (
+	// KeyAPIKey es la identidad autenticada: la clave de API o el sujeto del token
+	KeyAPIKey KeyKind = "api_key"
+	// KeySatellite es el satélite de la ruta
+	KeySatellite KeyKind = "satellite"
+	// KeyIP es la IP del cliente
+	KeyIP KeyKind = "ip"
+)
+
+// AnyRoute es la ruta de la regla que se aplica a las rutas sin regla propia
+const AnyRoute = "*"
+
+// sweepInterval es cada cuánto se descartan los cubos que ya están llenos
+const sweepInterval = time.Minute
+
+// Rule limita una ruta: cada cliente dispone de un cubo de Burst tokens que se
+// rellena a Rate tokens por segundo, y de DailyQuota peticiones por día UTC
+type Rule struct {
+	// Route es el método y la ruta tal como se registra, sin el prefijo de
+	// versión (p. ej. "POST /topsecret_split/:satellite_name"), o "*"
+	Route string  `json:"route"`
+	Key   KeyKind `json:"key"`
+	// Rate son los tokens que recupera el cubo por segundo
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+	// DailyQuota es el máximo de peticiones aceptadas por día; 0 no limita
+	DailyQuota int `json:"daily_quota,omitempty"`
+}
+
+// ParseRules decodifica y valida un fichero JSON con la lista de reglas
+func ParseRules(data []byte) ([]Rule, error) {
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("invalid rate limit rules: %w", err)
+	}
+	seen := make(map[string]bool, len(rules))
+	for i, rule := range rules {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("rate limit rule %d: %w", i, err)
+		}
+		if seen[rule.Route] {
+			return nil, fmt.Errorf("rate limit rule %d: route %q is repeated", i, rule.Route)
+		}
+		seen[rule.Route] = true
+	}
+	return rules, nil
+}
+
+func (r Rule) validate() error {
+	if r.Route != AnyRoute {
+		method, path, ok := strings.Cut(r.Route, " ")
+		if !ok || method == "" || method != strings.ToUpper(method) || !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("route %q must be \"METHOD /path\" or %q", r.Route, AnyRoute)
+		}
+	}
+	switch r.Key {
+	case KeyAPIKey, KeySatellite, KeyIP:
+	default:
+		return fmt.Errorf("key %q must be %s, %s or %s", r.Key, KeyAPIKey, KeySatellite, KeyIP)
+	}
+	if math.IsNaN(r.Rate) || math.IsInf(r.Rate, 0) || r.Rate <= 0 {
+		return fmt.Errorf("rate must be a positive number")
+	}
+	if r.Burst < 1 {
+		return fmt.Errorf("burst must be at least 1")
+	}
+	if r.DailyQuota < 0 {
+		return fmt.Errorf("daily_quota must not be negative")
+	}
+	return nil
+}
+
+// Decision es el resultado de pedir un token
+type Decision struct {
+	Allowed bool
+	// QuotaExceeded indica que se rechazó por la cuota diaria y no por el ritmo
+	QuotaExceeded bool
+	// Limit y Remaining son la capacidad del cubo y los tokens que le quedan
+	Limit     int
+	Remaining int
+	// Reset es lo que falta para que el cubo vuelva a estar lleno o, si se
+	// agotó la cuota, para el día siguiente
+	Reset time.Duration
+	// RetryAfter es lo que falta para que se acepte la siguiente petición
+	RetryAfter time.Duration
+}
+
+// Usage es la cuenta del día de un cliente en una regla
+type Usage struct {
+	Route string `json:"route" example:"POST /topsecret_split/:satellite_name"`
+	Key   string `json:"key" example:"kenobi"`
+	Used  int    `json:"used" example:"120"`
+	// Quota es la cuota diaria de la regla; 0 si no tiene
+	Quota     int       `json:"quota" example:"10000"`
+	Remaining *int      `json:"remaining,omitempty" example:"9880"`
+	ResetsAt  time.Time `json:"resets_at"`
+}
+
+// clientKey identifica el cubo y la cuenta de un cliente en una regla
+type clientKey struct {
+	route string
+	key   string
+}
+
+// bucket es un cubo de tokens
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter aplica las reglas a cada cliente. Los cubos y las cuentas están en
+// memoria, por lo que cada instancia lleva las suyas.
+type Limiter struct {
+	rules map[string]Rule
+	now   func() time.Time
+
+	mutex     sync.Mutex
+	buckets   map[clientKey]*bucket
+	usage     map[clientKey]int
+	day       time.Time
+	lastSweep time.Time
+}
+
+// NewLimiter crea un limitador con las reglas indicadas, ya validadas
+func NewLimiter(rules []Rule) *Limiter {
+	l := &Limiter{
+		rules:   make(map[string]Rule, len(rules)),
+		now:     time.Now,
+		buckets: make(map[clientKey]*bucket),
+		usage:   make(map[clientKey]int),
+	}
+	for _, rule := range rules {
+		l.rules[rule.Route] = rule
+	}
+	return l
+}
+
+// Rule devuelve la regla de una ruta o, si no tiene, la de AnyRoute
+func (l *Limiter) Rule(method, path string) (Rule, bool) {
+	if rule, ok := l.rules[method+" "+path]; ok {
+		return rule, true
+	}
+	rule, ok := l.rules[AnyRoute]
+	return rule, ok
+}
+
+// Allow consume un token del cubo del cliente key en la regla, si hay y si no
+// ha agotado la cuota diaria
+func (l *Limiter) Allow(rule Rule, key string) Decision {
+	now := l.now()
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.rollDay(now)
+	l.sweep(now)
+
+	id := clientKey{route: rule.Route, key: key}
+	b, ok := l.buckets[id]
+	if !ok {
+		b = &bucket{tokens: float64(rule.Burst), last: now}
+		l.buckets[id] = b
+	}
+	b.tokens = min(float64(rule.Burst), b.tokens+now.Sub(b.last).Seconds()*rule.Rate)
+	b.last = now
+
+	decision := Decision{Limit: rule.Burst}
+	if rule.DailyQuota > 0 && l.usage[id] >= rule.DailyQuota {
+		decision.QuotaExceeded = true
+		decision.Reset = l.day.AddDate(0, 0, 1).Sub(now)
+		decision.RetryAfter = decision.Reset
+		decision.Remaining = int(b.tokens)
+		return decision
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		l.usage[id]++
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = seconds((1 - b.tokens) / rule.Rate)
+	}
+	decision.Remaining = int(b.tokens)
+	decision.Reset = seconds((float64(rule.Burst) - b.tokens) / rule.Rate)
+	return decision
+}
+
+// Usage devuelve la cuenta del día de cada cliente, ordenada por regla y cliente
+func (l *Limiter) Usage() []Usage {
+	now := l.now()
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.rollDay(now)
+	resetsAt := l.day.AddDate(0, 0, 1)
+	usage := make([]Usage, 0, len(l.usage))
+	for id, used := range l.usage {
+		entry := Usage{Route: id.route, Key: id.key, Used: used, ResetsAt: resetsAt}
+		if quota := l.rules[id.route].DailyQuota; quota > 0 {
+			remaining := max(0, quota-used)
+			entry.Quota, entry.Remaining = quota, &remaining
+		}
+		usage = append(usage, entry)
+	}
+	slices.SortFunc(usage, func(a, b Usage) int {
+		if c := strings.Compare(a.Route, b.Route); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+	return usage
+}
+
+// rollDay pone a cero las cuentas al empezar un nuevo día UTC; requiere el mutex tomado
+func (l *Limiter) rollDay(now time.Time) {
+	day := now.UTC().Truncate(24 * time.Hour)
+	if !day.Equal(l.day) {
+		l.day = day
+		clear(l.usage)
+	}
+}
+
+// sweep descarta los cubos que ya se habrán rellenado, que equivalen a uno
+// nuevo; requiere el mutex tomado
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for id, b := range l.buckets {
+		rule := l.rules[id.route]
+		if b.tokens+now.Sub(b.last).Seconds()*rule.Rate >= float64(rule.Burst) {
+			delete(l.buckets, id)
+		}
+	}
+}
+
+// seconds convierte segundos en una duración
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
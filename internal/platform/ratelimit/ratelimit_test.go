@@ -0,0 +1,185 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+// clock es un reloj manual para los cubos
+type clock struct{ now time.Time }
+
+func (c *clock) Now() time.Time          { return c.now }
+func (c *clock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// newLimiter crea un limitador que usa el reloj c
+func newLimiter(c *clock, rules ...Rule) *Limiter {
+	l := NewLimiter(rules)
+	l.now = c.Now
+	return l
+}
+
+func TestParseRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"valid", `[{"route":"POST /topsecret_split/:satellite_name","key":"satellite","rate":1,"burst":5,"daily_quota":100}]`, false},
+		{"any route", `[{"route":"*","key":"ip","rate":0.5,"burst":1}]`, false},
+		{"empty", `[]`, false},
+		{"not json", `{`, true},
+		{"no method", `[{"route":"/topsecret","key":"ip","rate":1,"burst":1}]`, true},
+		{"lowercase method", `[{"route":"post /topsecret","key":"ip","rate":1,"burst":1}]`, true},
+		{"relative path", `[{"route":"POST topsecret","key":"ip","rate":1,"burst":1}]`, true},
+		{"unknown key", `[{"route":"*","key":"user","rate":1,"burst":1}]`, true},
+		{"zero rate", `[{"route":"*","key":"ip","rate":0,"burst":1}]`, true},
+		{"negative rate", `[{"route":"*","key":"ip","rate":-1,"burst":1}]`, true},
+		{"zero burst", `[{"route":"*","key":"ip","rate":1,"burst":0}]`, true},
+		{"negative quota", `[{"route":"*","key":"ip","rate":1,"burst":1,"daily_quota":-1}]`, true},
+		{"repeated route", `[{"route":"*","key":"ip","rate":1,"burst":1},{"route":"*","key":"api_key","rate":1,"burst":1}]`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseRules([]byte(tt.data)); (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRules() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLimiterRule(t *testing.T) {
+	route := Rule{Route: "POST /topsecret", Key: KeyIP, Rate: 1, Burst: 1}
+	fallback := Rule{Route: AnyRoute, Key: KeyIP, Rate: 10, Burst: 10}
+
+	tests := []struct {
+		name   string
+		rules  []Rule
+		method string
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{"own rule", []Rule{route, fallback}, "POST", "/topsecret", route.Route, true},
+		{"other method", []Rule{route, fallback}, "GET", "/topsecret", AnyRoute, true},
+		{"fallback", []Rule{route, fallback}, "GET", "/health", AnyRoute, true},
+		{"no fallback", []Rule{route}, "GET", "/health", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, ok := NewLimiter(tt.rules).Rule(tt.method, tt.path)
+			if ok != tt.wantOK || rule.Route != tt.want {
+				t.Fatalf("Rule(%s %s) = %q, %v, want %q, %v", tt.method, tt.path, rule.Route, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestLimiterTokenBucket(t *testing.T) {
+	c := &clock{now: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	rule := Rule{Route: AnyRoute, Key: KeyIP, Rate: 2, Burst: 3}
+	l := newLimiter(c, rule)
+
+	tests := []struct {
+		name          string
+		advance       time.Duration
+		key           string
+		wantAllowed   bool
+		wantRemaining int
+		wantRetry     time.Duration
+	}{
+		// El cubo empieza lleno y admite una ráfaga de Burst peticiones
+		{"first", 0, "a", true, 2, 0},
+		{"second", 0, "a", true, 1, 0},
+		{"third", 0, "a", true, 0, 0},
+		{"empty", 0, "a", false, 0, 500 * time.Millisecond},
+		// Cada cliente tiene su cubo
+		{"other client", 0, "b", true, 2, 0},
+		// A 2 tokens por segundo, en medio segundo vuelve a haber uno
+		{"refilled one", 500 * time.Millisecond, "a", true, 0, 0},
+		{"empty again", 0, "a", false, 0, 500 * time.Millisecond},
+		// El cubo no pasa de Burst aunque pase mucho tiempo
+		{"refilled full", time.Hour, "a", true, 2, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.Advance(tt.advance)
+			d := l.Allow(rule, tt.key)
+			if d.Allowed != tt.wantAllowed || d.Remaining != tt.wantRemaining || d.RetryAfter != tt.wantRetry {
+				t.Fatalf("Allow() = %+v, want allowed %v, remaining %d and retry after %v", d, tt.wantAllowed, tt.wantRemaining, tt.wantRetry)
+			}
+			if d.Limit != rule.Burst || d.QuotaExceeded {
+				t.Fatalf("Allow() = %+v", d)
+			}
+		})
+	}
+}
+
+func TestLimiterDailyQuota(t *testing.T) {
+	c := &clock{now: time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC)}
+	rule := Rule{Route: "POST /topsecret", Key: KeyAPIKey, Rate: 100, Burst: 100, DailyQuota: 2}
+	l := newLimiter(c, rule)
+
+	for i := range 2 {
+		if d := l.Allow(rule, "station"); !d.Allowed {
+			t.Fatalf("request %d = %+v, want it allowed", i, d)
+		}
+	}
+	d := l.Allow(rule, "station")
+	if d.Allowed || !d.QuotaExceeded || d.RetryAfter != time.Hour {
+		t.Fatalf("Allow() over the quota = %+v, want it rejected until midnight UTC", d)
+	}
+
+	usage := l.Usage()
+	if len(usage) != 1 || usage[0].Used != 2 || usage[0].Quota != 2 || usage[0].Remaining == nil || *usage[0].Remaining != 0 {
+		t.Fatalf("Usage() = %+v", usage)
+	}
+	if want := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC); !usage[0].ResetsAt.Equal(want) {
+		t.Fatalf("ResetsAt = %v, want %v", usage[0].ResetsAt, want)
+	}
+
+	// Al empezar el día UTC las cuentas vuelven a cero
+	c.Advance(time.Hour)
+	if d := l.Allow(rule, "station"); !d.Allowed {
+		t.Fatalf("Allow() the next day = %+v, want it allowed", d)
+	}
+	if usage := l.Usage(); len(usage) != 1 || usage[0].Used != 1 {
+		t.Fatalf("Usage() the next day = %+v", usage)
+	}
+}
+
+func TestLimiterUsage(t *testing.T) {
+	c := &clock{now: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	split := Rule{Route: "POST /topsecret_split/:satellite_name", Key: KeySatellite, Rate: 1, Burst: 5, DailyQuota: 10}
+	fallback := Rule{Route: AnyRoute, Key: KeyIP, Rate: 1, Burst: 5}
+	l := newLimiter(c, split, fallback)
+
+	l.Allow(split, "sato")
+	l.Allow(split, "kenobi")
+	l.Allow(split, "kenobi")
+	l.Allow(fallback, "10.0.0.1")
+
+	usage := l.Usage()
+	want := []struct {
+		route string
+		key   string
+		used  int
+		quota int
+	}{
+		{AnyRoute, "10.0.0.1", 1, 0},
+		{split.Route, "kenobi", 2, 10},
+		{split.Route, "sato", 1, 10},
+	}
+	if len(usage) != len(want) {
+		t.Fatalf("Usage() = %+v", usage)
+	}
+	for i, w := range want {
+		u := usage[i]
+		if u.Route != w.route || u.Key != w.key || u.Used != w.used || u.Quota != w.quota {
+			t.Fatalf("Usage()[%d] = %+v, want %+v", i, u, w)
+		}
+		// Sin cuota no hay restante
+		if (u.Remaining != nil) != (w.quota > 0) || (u.Remaining != nil && *u.Remaining != w.quota-w.used) {
+			t.Fatalf("Usage()[%d].Remaining = %v", i, u.Remaining)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrInjectedFault es el error que devuelve FaultInjector cuando decide fallar
+var ErrInjectedFault = errors.New("injected repository fault")
+
+// Nombres de método que aceptan las reglas de FaultRule
+const (
+	MethodGetSatellite           = "GetSatellite"
+	MethodSaveSatellite          = "SaveSatellite"
+	MethodSaveSatelliteIfVersion = "SaveSatelliteIfVersion"
+	MethodGetAllSatellites       = "GetAllSatellites"
+	MethodClearSatellite         = "ClearSatellite"
+	MethodClearAllSatellites     = "ClearAllSatellites"
+	MethodReplaceAllSatellites   = "ReplaceAllSatellites"
+)
+
+// FaultRule describe un fallo a inyectar. Los campos vacíos de Method y
+// Satellite coinciden con cualquier método o satélite. Una regla con Satellite
+// no afecta a las operaciones sobre toda la colección (ClearAllSatellites,
+// ReplaceAllSatellites), para no romper la constelación entera; en
+// GetAllSatellites solo afecta a ese satélite.
+type FaultRule struct {
+	// Method limita la regla a un método del repositorio
+	Method string `json:"method,omitempty"`
+	// Satellite limita la regla a las operaciones sobre ese satélite
+	Satellite string `json:"satellite,omitempty"`
+	// ErrorRate es la probabilidad (0..1) de devolver ErrInjectedFault sin tocar el repositorio
+	ErrorRate float64 `json:"error_rate,omitempty"`
+	// PartialRate es la probabilidad (0..1) de un fallo parcial: las escrituras se
+	// aplican pero devuelven error, y GetAllSatellites omite los satélites afectados
+	PartialRate float64 `json:"partial_rate,omitempty"`
+	// LatencyMS es el retardo añadido antes de ejecutar la operación
+	LatencyMS int `json:"latency_ms,omitempty"`
+}
+
+// FaultConfig agrupa las reglas de inyección de fallos
+type FaultConfig struct {
+	Rules []FaultRule `json:"rules"`
+	// Seed fija la semilla aleatoria para reproducir una ejecución; 0 usa la hora actual
+	Seed int64 `json:"seed,omitempty"`
+}
+
+// ParseFaultConfig decodifica y valida una configuración en JSON
+func ParseFaultConfig(data []byte) (FaultConfig, error) {
+	var config FaultConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return FaultConfig{}, fmt.Errorf("invalid fault config: %w", err)
+	}
+	for i, rule := range config.Rules {
+		switch rule.Method {
+		case "", MethodGetSatellite, MethodSaveSatellite, MethodSaveSatelliteIfVersion,
+			MethodGetAllSatellites, MethodClearSatellite, MethodClearAllSatellites, MethodReplaceAllSatellites:
+		default:
+			return FaultConfig{}, fmt.Errorf("invalid fault config: rule %d: unknown method %q", i, rule.Method)
+		}
+		if rule.ErrorRate < 0 || rule.ErrorRate > 1 || rule.PartialRate < 0 || rule.PartialRate > 1 {
+			return FaultConfig{}, fmt.Errorf("invalid fault config: rule %d: rates must be between 0 and 1", i)
+		}
+		if rule.LatencyMS < 0 {
+			return FaultConfig{}, fmt.Errorf("invalid fault config: rule %d: latency must not be negative", i)
+		}
+	}
+	return config, nil
+}
+
+// FaultInjector envuelve un RepositoryService e inyecta errores, latencia y
+// fallos parciales según su configuración. Solo debe usarse fuera de producción.
+type FaultInjector struct {
+	inner RepositoryService
+	rules []FaultRule
+
+	mutex sync.Mutex
+	rnd   *rand.Rand
+}
+
+// NewFaultInjector crea el decorador sobre inner
+func NewFaultInjector(inner RepositoryService, config FaultConfig) *FaultInjector {
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &FaultInjector{
+		inner: inner,
+		rules: config.Rules,
+		rnd:   rand.New(rand.NewSource(seed)),
+	}
+}
+
+// fault es la decisión tomada para una llamada concreta
+type fault int
+
+const (
+	faultNone fault = iota
+	faultError
+	faultPartial
+)
+
+// decide aplica la latencia de las reglas que coinciden y sortea si la llamada falla
+func (f *FaultInjector) decide(ctx context.Context, method, satellite string) (fault, error) {
+	if err := f.delay(ctx, method, satellite); err != nil {
+		return faultNone, err
+	}
+	return f.roll(method, satellite), nil
+}
+
+// delay espera la suma de las latencias de las reglas que coinciden
+func (f *FaultInjector) delay(ctx context.Context, method, satellite string) error {
+	var latency time.Duration
+	for _, rule := range f.rules {
+		if rule.matches(method, satellite) {
+			latency += time.Duration(rule.LatencyMS) * time.Millisecond
+		}
+	}
+	if latency <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(latency)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// roll sortea el fallo de la llamada; gana la primera regla que dispare
+func (f *FaultInjector) roll(method, satellite string) fault {
+	for _, rule := range f.rules {
+		if !rule.matches(method, satellite) {
+			continue
+		}
+		if f.chance(rule.ErrorRate) {
+			return faultError
+		}
+		if f.chance(rule.PartialRate) {
+			return faultPartial
+		}
+	}
+	return faultNone
+}
+
+// matches indica si la regla aplica al método y satélite; satellite vacío
+// representa las operaciones sobre toda la colección, a las que solo aplican
+// las reglas sin satélite
+func (r FaultRule) matches(method, satellite string) bool {
+	if r.Method != "" && r.Method != method {
+		return false
+	}
+	return r.Satellite == "" || r.Satellite == satellite
+}
+
+func (f *FaultInjector) chance(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.rnd.Float64() < rate
+}
+
+func (f *FaultInjector) injectedError(method, satellite string) error {
+	if satellite == "" {
+		return fmt.Errorf("%s: %w", method, ErrInjectedFault)
+	}
+	return fmt.Errorf("%s(%s): %w", method, satellite, ErrInjectedFault)
+}
+
+// write ejecuta una escritura aplicando la decisión de fallo
+func (f *FaultInjector) write(ctx context.Context, method, satellite string, apply func() error) error {
+	decision, err := f.decide(ctx, method, satellite)
+	if err != nil {
+		return err
+	}
+	switch decision {
+	case faultError:
+		return f.injectedError(method, satellite)
+	case faultPartial:
+		// La escritura se aplica pero el llamador no puede saberlo
+		if err := apply(); err != nil {
+			return err
+		}
+		return f.injectedError(method, satellite)
+	}
+	return apply()
+}
+
+func (f *FaultInjector) GetSatellite(ctx context.Context, name string) (Satellite, error) {
+	decision, err := f.decide(ctx, MethodGetSatellite, name)
+	if err != nil {
+		return Satellite{}, err
+	}
+	if decision != faultNone {
+		return Satellite{}, f.injectedError(MethodGetSatellite, name)
+	}
+	return f.inner.GetSatellite(ctx, name)
+}
+
+func (f *FaultInjector) SaveSatellite(ctx context.Context, satellite Satellite) error {
+	return f.write(ctx, MethodSaveSatellite, satellite.Name, func() error {
+		return f.inner.SaveSatellite(ctx, satellite)
+	})
+}
+
+func (f *FaultInjector) SaveSatelliteIfVersion(ctx context.Context, satellite Satellite, version uint64) error {
+	return f.write(ctx, MethodSaveSatelliteIfVersion, satellite.Name, func() error {
+		return f.inner.SaveSatelliteIfVersion(ctx, satellite, version)
+	})
+}
+
+// GetAllSatellites sortea las reglas por cada satélite devuelto, de modo que un
+// fallo parcial omite solo los satélites afectados
+func (f *FaultInjector) GetAllSatellites(ctx context.Context) ([]Satellite, error) {
+	if err := f.delay(ctx, MethodGetAllSatellites, ""); err != nil {
+		return nil, err
+	}
+	satellites, err := f.inner.GetAllSatellites(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cada satélite se sortea por separado para poder omitir solo algunos
+	result := make([]Satellite, 0, len(satellites))
+	for _, satellite := range satellites {
+		switch f.roll(MethodGetAllSatellites, satellite.Name) {
+		case faultError:
+			return nil, f.injectedError(MethodGetAllSatellites, satellite.Name)
+		case faultPartial:
+			continue
+		}
+		result = append(result, satellite)
+	}
+	return result, nil
+}
+
+func (f *FaultInjector) ClearSatellite(ctx context.Context, name string) error {
+	return f.write(ctx, MethodClearSatellite, name, func() error {
+		return f.inner.ClearSatellite(ctx, name)
+	})
+}
+
+func (f *FaultInjector) ClearAllSatellites(ctx context.Context) error {
+	return f.write(ctx, MethodClearAllSatellites, "", func() error {
+		return f.inner.ClearAllSatellites(ctx)
+	})
+}
+
+func (f *FaultInjector) ReplaceAllSatellites(ctx context.Context, satellites []Satellite) error {
+	return f.write(ctx, MethodReplaceAllSatellites, "", func() error {
+		return f.inner.ReplaceAllSatellites(ctx, satellites)
+	})
+}
+
+// Subscribe no inyecta fallos: las notificaciones reflejan lo que realmente cambió
+func (f *FaultInjector) Subscribe(ctx context.Context, buffer int) (*Subscription, error) {
+	return f.inner.Subscribe(ctx, buffer)
+}
@@ -0,0 +1,229 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fuegodequasar/internal/platform/repository"
+	"fuegodequasar/internal/platform/repository/repositorytest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFaultInjectorConformance(t *testing.T) {
+	// Sin reglas el decorador no inyecta nada y debe comportarse como el repositorio que envuelve
+	repositorytest.Run(t, func(t *testing.T) repository.RepositoryService {
+		return repository.NewFaultInjector(repository.New(), repository.FaultConfig{})
+	})
+}
+
+// call ejecuta un método del repositorio y devuelve solo su error
+type call func(ctx context.Context, repo repository.RepositoryService) error
+
+// calls son las llamadas de cada método sobre kenobi o sobre toda la colección
+var calls = map[string]call{
+	repository.MethodGetSatellite: func(ctx context.Context, repo repository.RepositoryService) error {
+		_, err := repo.GetSatellite(ctx, "kenobi")
+		return err
+	},
+	repository.MethodSaveSatellite: func(ctx context.Context, repo repository.RepositoryService) error {
+		return repo.SaveSatellite(ctx, repository.Satellite{Name: "kenobi", Distance: 100})
+	},
+	repository.MethodSaveSatelliteIfVersion: func(ctx context.Context, repo repository.RepositoryService) error {
+		return repo.SaveSatelliteIfVersion(ctx, repository.Satellite{Name: "kenobi", Distance: 100}, 1)
+	},
+	repository.MethodGetAllSatellites: func(ctx context.Context, repo repository.RepositoryService) error {
+		_, err := repo.GetAllSatellites(ctx)
+		return err
+	},
+	repository.MethodClearSatellite: func(ctx context.Context, repo repository.RepositoryService) error {
+		return repo.ClearSatellite(ctx, "kenobi")
+	},
+	repository.MethodClearAllSatellites: func(ctx context.Context, repo repository.RepositoryService) error {
+		return repo.ClearAllSatellites(ctx)
+	},
+	repository.MethodReplaceAllSatellites: func(ctx context.Context, repo repository.RepositoryService) error {
+		return repo.ReplaceAllSatellites(ctx, repository.DefaultConstellation())
+	},
+}
+
+func TestFaultInjectorErrorRate(t *testing.T) {
+	for method, fn := range calls {
+		t.Run(method, func(t *testing.T) {
+			inner := repository.New()
+			repo := repository.NewFaultInjector(inner, repository.FaultConfig{
+				Rules: []repository.FaultRule{{Method: method, ErrorRate: 1}},
+			})
+			if err := fn(context.Background(), repo); !errors.Is(err, repository.ErrInjectedFault) {
+				t.Fatalf("%s = %v, want ErrInjectedFault", method, err)
+			}
+			// Un error inyectado no llega a tocar el repositorio
+			if kenobi, _ := inner.GetSatellite(context.Background(), "kenobi"); kenobi.Version != 1 {
+				t.Fatalf("kenobi version = %d after an injected error, want 1", kenobi.Version)
+			}
+			// Las demás llamadas no se ven afectadas
+			for other, fn := range calls {
+				if other == method {
+					continue
+				}
+				if err := fn(context.Background(), repo); errors.Is(err, repository.ErrInjectedFault) {
+					t.Fatalf("%s = %v, want no injected fault", other, err)
+				}
+			}
+		})
+	}
+}
+
+func TestFaultInjectorPartialWrite(t *testing.T) {
+	inner := repository.New()
+	repo := repository.NewFaultInjector(inner, repository.FaultConfig{
+		Rules: []repository.FaultRule{{Method: repository.MethodSaveSatellite, PartialRate: 1}},
+	})
+
+	err := repo.SaveSatellite(context.Background(), repository.Satellite{Name: "kenobi", Distance: 100})
+	if !errors.Is(err, repository.ErrInjectedFault) {
+		t.Fatalf("SaveSatellite() = %v, want ErrInjectedFault", err)
+	}
+	// La escritura se aplicó aunque el llamador recibió un error
+	kenobi, err := inner.GetSatellite(context.Background(), "kenobi")
+	if err != nil || kenobi.Distance != 100 {
+		t.Fatalf("kenobi = %+v, %v, want the partial write applied", kenobi, err)
+	}
+}
+
+func TestFaultInjectorPartialRead(t *testing.T) {
+	tests := []struct {
+		name string
+		rule repository.FaultRule
+		want []string
+	}{
+		{"one satellite", repository.FaultRule{Method: repository.MethodGetAllSatellites, Satellite: "kenobi", PartialRate: 1}, []string{"sato", "skywalker"}},
+		{"every satellite", repository.FaultRule{Method: repository.MethodGetAllSatellites, PartialRate: 1}, []string{}},
+		{"other method", repository.FaultRule{Method: repository.MethodGetSatellite, PartialRate: 1}, []string{"kenobi", "sato", "skywalker"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.NewFaultInjector(repository.New(), repository.FaultConfig{Rules: []repository.FaultRule{tt.rule}})
+			satellites, err := repo.GetAllSatellites(context.Background())
+			if err != nil {
+				t.Fatalf("GetAllSatellites() = %v", err)
+			}
+			names := []string{}
+			for _, satellite := range satellites {
+				names = append(names, satellite.Name)
+			}
+			if !slices.Equal(names, tt.want) {
+				t.Fatalf("GetAllSatellites() = %q, want %q", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestFaultInjectorLatency(t *testing.T) {
+	repo := repository.NewFaultInjector(repository.New(), repository.FaultConfig{
+		Rules: []repository.FaultRule{{LatencyMS: 10_000}},
+	})
+
+	for method, fn := range calls {
+		t.Run(method, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			if err := fn(ctx, repo); !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("%s = %v, want context.DeadlineExceeded", method, err)
+			}
+			// La espera termina con el contexto y no con la latencia configurada
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Fatalf("%s took %v, want it to stop with ctx", method, elapsed)
+			}
+		})
+	}
+
+	// Sin reglas que coincidan no hay retardo
+	fast := repository.NewFaultInjector(repository.New(), repository.FaultConfig{
+		Rules: []repository.FaultRule{{Method: repository.MethodClearSatellite, LatencyMS: 10_000}},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := fast.GetSatellite(ctx, "kenobi"); err != nil {
+		t.Fatalf("GetSatellite() = %v", err)
+	}
+}
+
+func TestFaultRuleMatching(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   repository.FaultRule
+		method string
+		want   bool
+	}{
+		{"any method", repository.FaultRule{}, repository.MethodClearSatellite, true},
+		{"same method", repository.FaultRule{Method: repository.MethodGetSatellite}, repository.MethodGetSatellite, true},
+		{"other method", repository.FaultRule{Method: repository.MethodGetSatellite}, repository.MethodClearSatellite, false},
+		{"same satellite", repository.FaultRule{Satellite: "kenobi"}, repository.MethodGetSatellite, true},
+		{"same method and satellite", repository.FaultRule{Method: repository.MethodSaveSatellite, Satellite: "kenobi"}, repository.MethodSaveSatellite, true},
+		{"other satellite", repository.FaultRule{Satellite: "sato"}, repository.MethodGetSatellite, false},
+		// Una regla de un satélite no rompe las operaciones sobre toda la colección
+		{"satellite rule on clear all", repository.FaultRule{Satellite: "kenobi"}, repository.MethodClearAllSatellites, false},
+		{"satellite rule on replace all", repository.FaultRule{Satellite: "kenobi"}, repository.MethodReplaceAllSatellites, false},
+		{"rule without satellite on clear all", repository.FaultRule{}, repository.MethodClearAllSatellites, true},
+		// En GetAllSatellites falla la lectura del satélite de la regla
+		{"satellite rule on get all", repository.FaultRule{Satellite: "kenobi"}, repository.MethodGetAllSatellites, true},
+		{"other satellite rule on get all", repository.FaultRule{Satellite: "yoda"}, repository.MethodGetAllSatellites, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := tt.rule
+			rule.ErrorRate = 1
+			repo := repository.NewFaultInjector(repository.New(), repository.FaultConfig{Rules: []repository.FaultRule{rule}})
+			err := calls[tt.method](context.Background(), repo)
+			if got := errors.Is(err, repository.ErrInjectedFault); got != tt.want {
+				t.Fatalf("%s = %v, want injected fault %v", tt.method, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFaultConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"valid", `{"rules":[{"method":"GetSatellite","satellite":"kenobi","error_rate":0.5,"partial_rate":0.1,"latency_ms":20}],"seed":7}`, false},
+		{"no rules", `{}`, false},
+		{"not json", `{`, true},
+		{"unknown method", `{"rules":[{"method":"DropTable"}]}`, true},
+		{"negative error rate", `{"rules":[{"error_rate":-0.1}]}`, true},
+		{"error rate above one", `{"rules":[{"error_rate":1.5}]}`, true},
+		{"negative partial rate", `{"rules":[{"partial_rate":-1}]}`, true},
+		{"partial rate above one", `{"rules":[{"partial_rate":2}]}`, true},
+		{"negative latency", `{"rules":[{"latency_ms":-5}]}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := repository.ParseFaultConfig([]byte(tt.data)); (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFaultConfig() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFaultInjectorSeed(t *testing.T) {
+	// Con la misma semilla se inyectan los mismos fallos
+	run := func() []bool {
+		repo := repository.NewFaultInjector(repository.New(), repository.FaultConfig{
+			Rules: []repository.FaultRule{{ErrorRate: 0.5}},
+			Seed:  42,
+		})
+		var failed []bool
+		for range 20 {
+			_, err := repo.GetSatellite(context.Background(), "kenobi")
+			failed = append(failed, err != nil)
+		}
+		return failed
+	}
+	if first, second := run(), run(); !slices.Equal(first, second) {
+		t.Fatalf("runs with the same seed differ: %v and %v", first, second)
+	}
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrSlowConsumer es el motivo por el que se cierra una suscripción que no
+// vació su buffer a tiempo
+var ErrSlowConsumer = errors.New("subscription dropped: consumer too slow")
+
+// ErrSubscriptionClosed es el motivo de cierre de una suscripción cerrada por
+// su dueño o por la cancelación de su contexto
+var ErrSubscriptionClosed = errors.New("subscription closed")
+
+// ChangeKind es el tipo de cambio notificado
+type ChangeKind string
+
+const (
+	// ChangeSaved indica que se guardó o creó un satélite
+	ChangeSaved ChangeKind = "saved"
+	// ChangeCleared indica que se borró la lectura de un satélite
+	ChangeCleared ChangeKind = "cleared"
+	// ChangeRemoved indica que un satélite dejó de existir
+	ChangeRemoved ChangeKind = "removed"
+)
+
+// Change describe un cambio sobre un satélite. Satellite es el estado tras el
+// cambio, salvo en ChangeRemoved, donde es el último estado que tuvo.
+type Change struct {
+	// Seq crece con cada cambio publicado por el mismo repositorio
+	Seq       uint64     `json:"seq"`
+	Time      time.Time  `json:"time"`
+	Kind      ChangeKind `json:"kind"`
+	Satellite Satellite  `json:"satellite"`
+}
+
+// Subscription recibe los cambios del repositorio por C en el orden en que se
+// producen.
+//
+// Política ante consumidores lentos: la publicación nunca bloquea al
+// repositorio. Si el buffer de C está lleno cuando llega un cambio, la
+// suscripción se da de baja, C se cierra y Err devuelve ErrSlowConsumer. El
+// consumidor debe entonces releer el estado actual y suscribirse de nuevo.
+type Subscription struct {
+	// C entrega los cambios; se cierra al terminar la suscripción
+	C <-chan Change
+
+	ch       chan Change
+	notifier *Notifier
+	stop     func() bool
+	err      error
+}
+
+// Close da de baja la suscripción y cierra C. Se puede llamar más de una vez.
+func (s *Subscription) Close() {
+	s.stop()
+	s.notifier.remove(s, ErrSubscriptionClosed)
+}
+
+// Err devuelve por qué terminó la suscripción, o nil si sigue activa
+func (s *Subscription) Err() error {
+	s.notifier.mutex.Lock()
+	defer s.notifier.mutex.Unlock()
+	return s.err
+}
+
+// Notifier reparte cambios entre suscriptores en proceso. Las implementaciones
+// de RepositoryService lo usan para cumplir Subscribe; su valor cero está
+// listo para usarse.
+type Notifier struct {
+	mutex       sync.Mutex
+	subscribers map[*Subscription]struct{}
+	seq         uint64
+}
+
+// Subscribe registra un suscriptor con un buffer de tamaño buffer (mínimo 1).
+// La suscripción termina al llamar a Close o al cancelarse ctx.
+func (n *Notifier) Subscribe(ctx context.Context, buffer int) (*Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ch := make(chan Change, max(buffer, 1))
+	sub := &Subscription{C: ch, ch: ch, notifier: n}
+
+	n.mutex.Lock()
+	if n.subscribers == nil {
+		n.subscribers = make(map[*Subscription]struct{})
+	}
+	n.subscribers[sub] = struct{}{}
+	n.mutex.Unlock()
+
+	sub.stop = context.AfterFunc(ctx, func() {
+		n.remove(sub, ErrSubscriptionClosed)
+	})
+	return sub, nil
+}
+
+// Publish numera los cambios y los entrega a todos los suscriptores sin bloquear
+func (n *Notifier) Publish(changes ...Change) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	now := time.Now().UTC()
+	for _, change := range changes {
+		n.seq++
+		change.Seq = n.seq
+		if change.Time.IsZero() {
+			change.Time = now
+		}
+		for sub := range n.subscribers {
+			// Cada suscriptor recibe su propia copia del mensaje
+			delivered := change
+			delivered.Satellite = snapshot(change.Satellite)
+			select {
+			case sub.ch <- delivered:
+			default:
+				n.removeLocked(sub, ErrSlowConsumer)
+			}
+		}
+	}
+}
+
+func (n *Notifier) remove(sub *Subscription, reason error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.removeLocked(sub, reason)
+}
+
+// removeLocked da de baja al suscriptor si sigue activo; requiere el mutex tomado
+func (n *Notifier) removeLocked(sub *Subscription, reason error) {
+	if _, active := n.subscribers[sub]; !active {
+		return
+	}
+	delete(n.subscribers, sub)
+	sub.err = reason
+	close(sub.ch)
+}
@@ -0,0 +1,517 @@
+// Package repositorytest contiene una batería de pruebas de conformidad que
+// cualquier implementación de repository.RepositoryService puede ejecutar
+// contra sí misma desde sus propios tests:
+//
+//	func TestConformance(t *testing.T) {
+//		repositorytest.Run(t, func(t *testing.T) repository.RepositoryService {
+//			return miBackend(t)
+//		})
+//	}
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fuegodequasar/internal/platform/repository"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Factory crea un repositorio nuevo e independiente para cada subtest. El
+// repositorio puede venir con satélites precargados; las pruebas solo usan
+// nombres propios con el prefijo "conformance-".
+type Factory func(t *testing.T) repository.RepositoryService
+
+// Run ejecuta todas las pruebas de conformidad contra los repositorios creados por newRepo
+func Run(t *testing.T, newRepo Factory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo repository.RepositoryService)
+	}{
+		{"NotFound", testNotFound},
+		{"SaveAndGet", testSaveAndGet},
+		{"Overwrite", testOverwrite},
+		{"SaveIfVersion", testSaveIfVersion},
+		{"Clear", testClear},
+		{"ClearAll", testClearAll},
+		{"ReplaceAll", testReplaceAll},
+		{"Ordering", testOrdering},
+		{"ConcurrentWriters", testConcurrentWriters},
+		{"SnapshotIsolation", testSnapshotIsolation},
+		{"CanceledContext", testCanceledContext},
+		{"Subscribe", testSubscribe},
+		{"SlowConsumer", testSlowConsumer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepo(t))
+		})
+	}
+}
+
+// satelliteName genera nombres que no chocan con satélites precargados
+func satelliteName(suffix string) string {
+	return "conformance-" + suffix
+}
+
+func sample(name string) repository.Satellite {
+	return repository.Satellite{
+		Name:     name,
+		Position: repository.Point{X: 10, Y: -20},
+		Distance: 100.5,
+		Message:  []string{"este", "", "mensaje"},
+	}
+}
+
+// mustSave guarda el satélite y falla el test si el repositorio devuelve error
+func mustSave(t *testing.T, repo repository.RepositoryService, satellite repository.Satellite) {
+	t.Helper()
+	if err := repo.SaveSatellite(context.Background(), satellite); err != nil {
+		t.Fatalf("SaveSatellite(%q) error = %v", satellite.Name, err)
+	}
+}
+
+// mustGet obtiene el satélite y falla el test si el repositorio devuelve error
+func mustGet(t *testing.T, repo repository.RepositoryService, name string) repository.Satellite {
+	t.Helper()
+	satellite, err := repo.GetSatellite(context.Background(), name)
+	if err != nil {
+		t.Fatalf("GetSatellite(%q) error = %v", name, err)
+	}
+	return satellite
+}
+
+func testNotFound(t *testing.T, repo repository.RepositoryService) {
+	ctx := context.Background()
+	name := satelliteName("missing")
+
+	if _, err := repo.GetSatellite(ctx, name); !errors.Is(err, repository.ErrSatelliteNotFound) {
+		t.Errorf("GetSatellite of unknown satellite error = %v, want ErrSatelliteNotFound", err)
+	}
+	if err := repo.ClearSatellite(ctx, name); !errors.Is(err, repository.ErrSatelliteNotFound) {
+		t.Errorf("ClearSatellite of unknown satellite error = %v, want ErrSatelliteNotFound", err)
+	}
+	// Un satélite inexistente no debe aparecer tras intentar borrarlo
+	if _, err := repo.GetSatellite(ctx, name); !errors.Is(err, repository.ErrSatelliteNotFound) {
+		t.Errorf("GetSatellite after failed clear error = %v, want ErrSatelliteNotFound", err)
+	}
+}
+
+func testSaveAndGet(t *testing.T, repo repository.RepositoryService) {
+	want := sample(satelliteName("kenobi"))
+	mustSave(t, repo, want)
+
+	got := mustGet(t, repo, want.Name)
+	if got.Name != want.Name || got.Position != want.Position || got.Distance != want.Distance {
+		t.Errorf("GetSatellite = %+v, want %+v", got, want)
+	}
+	if !slices.Equal(got.Message, want.Message) {
+		t.Errorf("GetSatellite message = %q, want %q", got.Message, want.Message)
+	}
+	if got.Version != 1 {
+		t.Errorf("version of new satellite = %d, want 1", got.Version)
+	}
+	if got.UpdatedAt.IsZero() {
+		t.Error("UpdatedAt of saved satellite is zero")
+	}
+}
+
+func testOverwrite(t *testing.T, repo repository.RepositoryService) {
+	first := sample(satelliteName("skywalker"))
+	mustSave(t, repo, first)
+	before := mustGet(t, repo, first.Name)
+
+	second := first
+	second.Distance = 250
+	second.Message = []string{"", "es", "", "secreto"}
+	mustSave(t, repo, second)
+
+	got := mustGet(t, repo, first.Name)
+	if got.Distance != second.Distance || !slices.Equal(got.Message, second.Message) {
+		t.Errorf("after overwrite got distance=%v message=%q, want distance=%v message=%q",
+			got.Distance, got.Message, second.Distance, second.Message)
+	}
+	if got.Version != before.Version+1 {
+		t.Errorf("version after overwrite = %d, want %d", got.Version, before.Version+1)
+	}
+	if got.UpdatedAt.Before(before.UpdatedAt) {
+		t.Errorf("UpdatedAt went backwards: %v -> %v", before.UpdatedAt, got.UpdatedAt)
+	}
+	// El número de satélites no cambia al sobrescribir
+	all, err := repo.GetAllSatellites(context.Background())
+	if err != nil {
+		t.Fatalf("GetAllSatellites error = %v", err)
+	}
+	count := 0
+	for _, satellite := range all {
+		if satellite.Name == first.Name {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("GetAllSatellites returned %d entries for %q, want 1", count, first.Name)
+	}
+}
+
+func testSaveIfVersion(t *testing.T, repo repository.RepositoryService) {
+	ctx := context.Background()
+	satellite := sample(satelliteName("sato"))
+
+	// Versión 0 significa "todavía no existe"
+	if err := repo.SaveSatelliteIfVersion(ctx, satellite, 1); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Errorf("SaveSatelliteIfVersion of new satellite with version 1 error = %v, want ErrVersionConflict", err)
+	}
+	if err := repo.SaveSatelliteIfVersion(ctx, satellite, 0); err != nil {
+		t.Fatalf("SaveSatelliteIfVersion of new satellite with version 0 error = %v", err)
+	}
+	if err := repo.SaveSatelliteIfVersion(ctx, satellite, 0); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Errorf("second SaveSatelliteIfVersion with version 0 error = %v, want ErrVersionConflict", err)
+	}
+
+	satellite.Distance = 42
+	if err := repo.SaveSatelliteIfVersion(ctx, satellite, 1); err != nil {
+		t.Fatalf("SaveSatelliteIfVersion with current version error = %v", err)
+	}
+	got := mustGet(t, repo, satellite.Name)
+	if got.Version != 2 || got.Distance != 42 {
+		t.Errorf("after compare-and-swap got version=%d distance=%v, want version=2 distance=42", got.Version, got.Distance)
+	}
+
+	// Una escritura con versión obsoleta no debe modificar nada
+	stale := satellite
+	stale.Distance = 7
+	if err := repo.SaveSatelliteIfVersion(ctx, stale, 1); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Errorf("SaveSatelliteIfVersion with stale version error = %v, want ErrVersionConflict", err)
+	}
+	if got := mustGet(t, repo, satellite.Name); got.Distance != 42 || got.Version != 2 {
+		t.Errorf("stale write modified satellite: version=%d distance=%v", got.Version, got.Distance)
+	}
+}
+
+func testClear(t *testing.T, repo repository.RepositoryService) {
+	satellite := sample(satelliteName("clear"))
+	mustSave(t, repo, satellite)
+	before := mustGet(t, repo, satellite.Name)
+
+	if err := repo.ClearSatellite(context.Background(), satellite.Name); err != nil {
+		t.Fatalf("ClearSatellite error = %v", err)
+	}
+	got := mustGet(t, repo, satellite.Name)
+	if got.Position != satellite.Position {
+		t.Errorf("ClearSatellite changed position to %+v, want %+v", got.Position, satellite.Position)
+	}
+	if got.Distance != 0 || len(got.Message) != 0 {
+		t.Errorf("ClearSatellite left distance=%v message=%q", got.Distance, got.Message)
+	}
+	if got.Version != before.Version+1 {
+		t.Errorf("version after clear = %d, want %d", got.Version, before.Version+1)
+	}
+}
+
+func testClearAll(t *testing.T, repo repository.RepositoryService) {
+	ctx := context.Background()
+	names := []string{satelliteName("a"), satelliteName("b"), satelliteName("c")}
+	for i, name := range names {
+		satellite := sample(name)
+		satellite.Position = repository.Point{X: float32(i), Y: float32(-i)}
+		mustSave(t, repo, satellite)
+	}
+
+	if err := repo.ClearAllSatellites(ctx); err != nil {
+		t.Fatalf("ClearAllSatellites error = %v", err)
+	}
+	for i, name := range names {
+		got := mustGet(t, repo, name)
+		if got.Distance != 0 || len(got.Message) != 0 {
+			t.Errorf("%s after ClearAllSatellites: distance=%v message=%q", name, got.Distance, got.Message)
+		}
+		if want := (repository.Point{X: float32(i), Y: float32(-i)}); got.Position != want {
+			t.Errorf("%s position after ClearAllSatellites = %+v, want %+v", name, got.Position, want)
+		}
+	}
+}
+
+func testReplaceAll(t *testing.T, repo repository.RepositoryService) {
+	ctx := context.Background()
+	kept := sample(satelliteName("kept"))
+	mustSave(t, repo, kept)
+	before := mustGet(t, repo, kept.Name)
+	mustSave(t, repo, sample(satelliteName("removed")))
+
+	replacement := []repository.Satellite{sample(kept.Name), sample(satelliteName("added"))}
+	replacement[0].Distance = 321
+	if err := repo.ReplaceAllSatellites(ctx, replacement); err != nil {
+		t.Fatalf("ReplaceAllSatellites error = %v", err)
+	}
+
+	all, err := repo.GetAllSatellites(ctx)
+	if err != nil {
+		t.Fatalf("GetAllSatellites error = %v", err)
+	}
+	if len(all) != len(replacement) {
+		t.Fatalf("GetAllSatellites after replace returned %d satellites, want %d", len(all), len(replacement))
+	}
+	if _, err := repo.GetSatellite(ctx, satelliteName("removed")); !errors.Is(err, repository.ErrSatelliteNotFound) {
+		t.Errorf("GetSatellite of replaced-away satellite error = %v, want ErrSatelliteNotFound", err)
+	}
+	got := mustGet(t, repo, kept.Name)
+	if got.Distance != 321 {
+		t.Errorf("replaced satellite distance = %v, want 321", got.Distance)
+	}
+	// Las versiones no retroceden para que un ETag antiguo no vuelva a ser válido
+	if got.Version <= before.Version {
+		t.Errorf("replaced satellite version = %d, want greater than %d", got.Version, before.Version)
+	}
+	if added := mustGet(t, repo, satelliteName("added")); added.Version == 0 {
+		t.Error("added satellite has version 0")
+	}
+}
+
+func testOrdering(t *testing.T, repo repository.RepositoryService) {
+	// Se guardan desordenados para que el orden no dependa del de inserción
+	for _, suffix := range []string{"zulu", "alpha", "mike", "bravo"} {
+		mustSave(t, repo, sample(satelliteName(suffix)))
+	}
+
+	all, err := repo.GetAllSatellites(context.Background())
+	if err != nil {
+		t.Fatalf("GetAllSatellites error = %v", err)
+	}
+	if !slices.IsSortedFunc(all, func(a, b repository.Satellite) int {
+		return strings.Compare(a.Name, b.Name)
+	}) {
+		names := make([]string, len(all))
+		for i, satellite := range all {
+			names[i] = satellite.Name
+		}
+		t.Errorf("GetAllSatellites not sorted by name: %q", names)
+	}
+}
+
+func testConcurrentWriters(t *testing.T, repo repository.RepositoryService) {
+	const writers = 16
+	const writesPerWriter = 25
+	ctx := context.Background()
+	name := satelliteName("concurrent")
+	mustSave(t, repo, sample(name))
+	start := mustGet(t, repo, name).Version
+
+	// Cada escritor hace lectura-modificación-escritura con compare-and-swap y
+	// reintenta ante conflicto; ninguna escritura puede perderse.
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < writesPerWriter; i++ {
+				for {
+					current, err := repo.GetSatellite(ctx, name)
+					if err != nil {
+						errs <- err
+						return
+					}
+					current.Message = []string{fmt.Sprintf("w%d-%d", w, i)}
+					err = repo.SaveSatelliteIfVersion(ctx, current, current.Version)
+					if err == nil {
+						break
+					}
+					if !errors.Is(err, repository.ErrVersionConflict) {
+						errs <- err
+						return
+					}
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("concurrent writer error = %v", err)
+	}
+
+	if got, want := mustGet(t, repo, name).Version, start+writers*writesPerWriter; got != want {
+		t.Errorf("version after concurrent writes = %d, want %d (lost updates)", got, want)
+	}
+}
+
+func testSnapshotIsolation(t *testing.T, repo repository.RepositoryService) {
+	ctx := context.Background()
+	satellite := sample(satelliteName("isolation"))
+	message := slices.Clone(satellite.Message)
+	mustSave(t, repo, satellite)
+
+	// Modificar el slice usado al guardar no debe afectar al repositorio
+	satellite.Message[0] = "mutated-input"
+
+	got := mustGet(t, repo, satellite.Name)
+	if !slices.Equal(got.Message, message) {
+		t.Errorf("stored message changed after mutating input: %q, want %q", got.Message, message)
+	}
+
+	// Tampoco modificar lo devuelto por GetSatellite o GetAllSatellites
+	got.Message[0] = "mutated-get"
+	all, err := repo.GetAllSatellites(ctx)
+	if err != nil {
+		t.Fatalf("GetAllSatellites error = %v", err)
+	}
+	for i := range all {
+		if all[i].Name == satellite.Name {
+			if !slices.Equal(all[i].Message, message) {
+				t.Errorf("stored message changed after mutating GetSatellite result: %q, want %q", all[i].Message, message)
+			}
+			all[i].Message[0] = "mutated-all"
+			all[i].Distance = -1
+		}
+	}
+	if got := mustGet(t, repo, satellite.Name); !slices.Equal(got.Message, message) || got.Distance != satellite.Distance {
+		t.Errorf("stored satellite changed after mutating GetAllSatellites result: %+v", got)
+	}
+}
+
+func testCanceledContext(t *testing.T, repo repository.RepositoryService) {
+	name := satelliteName("canceled")
+	mustSave(t, repo, sample(name))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := repo.GetSatellite(ctx, name); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetSatellite with canceled context error = %v, want context.Canceled", err)
+	}
+	if _, err := repo.GetAllSatellites(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetAllSatellites with canceled context error = %v, want context.Canceled", err)
+	}
+	changed := sample(name)
+	changed.Distance = 1
+	if err := repo.SaveSatellite(ctx, changed); !errors.Is(err, context.Canceled) {
+		t.Errorf("SaveSatellite with canceled context error = %v, want context.Canceled", err)
+	}
+	if err := repo.SaveSatelliteIfVersion(ctx, changed, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("SaveSatelliteIfVersion with canceled context error = %v, want context.Canceled", err)
+	}
+	if err := repo.ClearSatellite(ctx, name); !errors.Is(err, context.Canceled) {
+		t.Errorf("ClearSatellite with canceled context error = %v, want context.Canceled", err)
+	}
+	if err := repo.ClearAllSatellites(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("ClearAllSatellites with canceled context error = %v, want context.Canceled", err)
+	}
+	if err := repo.ReplaceAllSatellites(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("ReplaceAllSatellites with canceled context error = %v, want context.Canceled", err)
+	}
+
+	// Ninguna de las operaciones canceladas debe haber modificado el satélite
+	if got := mustGet(t, repo, name); got.Distance != sample(name).Distance || got.Version != 1 {
+		t.Errorf("canceled operations modified satellite: %+v", got)
+	}
+}
+
+// nextChange espera el siguiente cambio de la suscripción o falla el test
+func nextChange(t *testing.T, sub *repository.Subscription) repository.Change {
+	t.Helper()
+	select {
+	case change, ok := <-sub.C:
+		if !ok {
+			t.Fatalf("subscription closed unexpectedly: %v", sub.Err())
+		}
+		return change
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for change notification")
+	}
+	return repository.Change{}
+}
+
+func testSubscribe(t *testing.T, repo repository.RepositoryService) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sub, err := repo.Subscribe(ctx, 16)
+	if err != nil {
+		t.Fatalf("Subscribe error = %v", err)
+	}
+	defer sub.Close()
+
+	satellite := sample(satelliteName("subscribed"))
+	mustSave(t, repo, satellite)
+	saved := nextChange(t, sub)
+	if saved.Kind != repository.ChangeSaved || saved.Satellite.Name != satellite.Name || saved.Satellite.Distance != satellite.Distance {
+		t.Errorf("change after save = %+v, want saved %q", saved, satellite.Name)
+	}
+
+	if err := repo.ClearSatellite(ctx, satellite.Name); err != nil {
+		t.Fatalf("ClearSatellite error = %v", err)
+	}
+	cleared := nextChange(t, sub)
+	if cleared.Kind != repository.ChangeCleared || cleared.Satellite.Name != satellite.Name || cleared.Satellite.Distance != 0 {
+		t.Errorf("change after clear = %+v, want cleared %q", cleared, satellite.Name)
+	}
+	if cleared.Seq <= saved.Seq {
+		t.Errorf("change sequence did not increase: %d then %d", saved.Seq, cleared.Seq)
+	}
+	if cleared.Satellite.Version != saved.Satellite.Version+1 {
+		t.Errorf("notified version after clear = %d, want %d", cleared.Satellite.Version, saved.Satellite.Version+1)
+	}
+
+	// Tras cerrar no llegan más cambios y el canal queda cerrado
+	sub.Close()
+	mustSave(t, repo, satellite)
+	for range sub.C {
+	}
+	if !errors.Is(sub.Err(), repository.ErrSubscriptionClosed) {
+		t.Errorf("Err after Close = %v, want ErrSubscriptionClosed", sub.Err())
+	}
+
+	// Cancelar el contexto también termina la suscripción
+	other, err := repo.Subscribe(ctx, 1)
+	if err != nil {
+		t.Fatalf("Subscribe error = %v", err)
+	}
+	cancel()
+	select {
+	case <-closed(other):
+	case <-time.After(time.Second):
+		t.Fatal("subscription not closed after context cancellation")
+	}
+}
+
+func testSlowConsumer(t *testing.T, repo repository.RepositoryService) {
+	sub, err := repo.Subscribe(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Subscribe error = %v", err)
+	}
+	defer sub.Close()
+
+	// Sin leer, el segundo cambio desborda el buffer de uno
+	satellite := sample(satelliteName("slow"))
+	for i := 0; i < 3; i++ {
+		satellite.Distance = float32(100 + i)
+		mustSave(t, repo, satellite)
+	}
+
+	select {
+	case <-closed(sub):
+	case <-time.After(time.Second):
+		t.Fatal("slow subscription was not dropped")
+	}
+	if !errors.Is(sub.Err(), repository.ErrSlowConsumer) {
+		t.Errorf("Err of dropped subscription = %v, want ErrSlowConsumer", sub.Err())
+	}
+	// El repositorio sigue funcionando tras dar de baja al suscriptor
+	if got := mustGet(t, repo, satellite.Name); got.Distance != 102 {
+		t.Errorf("distance after dropping subscriber = %v, want 102", got.Distance)
+	}
+}
+
+// closed devuelve un canal que se cierra cuando la suscripción termina, descartando lo pendiente
+func closed(sub *repository.Subscription) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		for range sub.C {
+		}
+		close(done)
+	}()
+	return done
+}
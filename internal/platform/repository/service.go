@@ -1,13 +1,18 @@
 package repository
 
 import (
+	"context"
 	"errors"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 )
 
 // Errores del repositorio
 var (
 	ErrSatelliteNotFound = errors.New("satellite not found")
+	ErrVersionConflict   = errors.New("satellite version conflict")
 )
 
 // Satellite representa la información de un satélite
@@ -16,6 +21,50 @@ type Satellite struct {
 	Position Point    `json:"position"`
 	Message  []string `json:"message"`
 	Distance float32  `json:"distance"`
+	// UpdatedAt es el momento de la última escritura sobre el satélite
+	UpdatedAt time.Time `json:"updated_at"`
+	// Version se incrementa en uno con cada escritura; 0 indica que el satélite no existe
+	Version uint64 `json:"version"`
+	// Status es el estado operativo del receptor
+	Status Status `json:"status"`
+}
+
+// StatusState es el estado operativo de un satélite
+type StatusState string
+
+const (
+	// StatusActive participa normalmente en los cálculos; es el estado por defecto
+	StatusActive StatusState = "active"
+	// StatusDegraded participa en los cálculos con menos peso
+	StatusDegraded StatusState = "degraded"
+	// StatusMaintenance se excluye de los cálculos temporalmente
+	StatusMaintenance StatusState = "maintenance"
+	// StatusDecommissioned se excluye de los cálculos de forma permanente
+	StatusDecommissioned StatusState = "decommissioned"
+)
+
+// Status describe el estado operativo de un satélite, el motivo y desde cuándo rige
+type Status struct {
+	State  StatusState `json:"state"`
+	Reason string      `json:"reason,omitempty"`
+	Since  time.Time   `json:"since,omitempty"`
+}
+
+// Valid indica si el estado es uno de los conocidos; el vacío equivale a activo
+func (s StatusState) Valid() bool {
+	switch s {
+	case "", StatusActive, StatusDegraded, StatusMaintenance, StatusDecommissioned:
+		return true
+	}
+	return false
+}
+
+// Effective devuelve el estado aplicando el valor por defecto a un estado vacío
+func (s Status) Effective() StatusState {
+	if s.State == "" {
+		return StatusActive
+	}
+	return s.State
 }
 
 // Point representa una posición en coordenadas x,y
@@ -24,40 +73,57 @@ type Point struct {
 	Y float32 `json:"y"`
 }
 
-// INTERFAZ que debe implementar el repositorio y el mock
+// INTERFAZ que debe implementar el repositorio y el mock.
+// Todos los métodos reciben el contexto de la petición y deben abandonar la
+// operación devolviendo ctx.Err() si este se cancela o vence. Los satélites
+// devueltos son copias: modificarlos no altera lo almacenado.
 type RepositoryService interface {
 	// GetSatellite obtiene la información de un satélite por su nombre
-	GetSatellite(name string) (Satellite, error)
+	GetSatellite(ctx context.Context, name string) (Satellite, error)
 	// SaveSatellite guarda o actualiza la información de un satélite
-	SaveSatellite(satellite Satellite) error
-	// GetAllSatellites obtiene la información de todos los satélites
-	GetAllSatellites() ([]Satellite, error)
+	SaveSatellite(ctx context.Context, satellite Satellite) error
+	// SaveSatelliteIfVersion guarda el satélite solo si su versión actual es version
+	// (0 si todavía no existe); si no coincide devuelve ErrVersionConflict
+	SaveSatelliteIfVersion(ctx context.Context, satellite Satellite, version uint64) error
+	// GetAllSatellites obtiene la información de todos los satélites ordenados por nombre
+	GetAllSatellites(ctx context.Context) ([]Satellite, error)
+	// ClearSatellite borra la distancia y el mensaje de un satélite conservando su posición
+	ClearSatellite(ctx context.Context, name string) error
+	// ClearAllSatellites borra las lecturas de todos los satélites conservando sus posiciones
+	ClearAllSatellites(ctx context.Context) error
+	// ReplaceAllSatellites sustituye de forma atómica todos los satélites por los
+	// indicados; los que no aparecen dejan de existir
+	ReplaceAllSatellites(ctx context.Context, satellites []Satellite) error
+	// Subscribe entrega por un canal con buffer cada cambio posterior a la
+	// suscripción; ver Subscription para la política con consumidores lentos
+	Subscribe(ctx context.Context, buffer int) (*Subscription, error)
 }
 
 // Estructura que implementa RepositoryService
 type Service struct {
 	satellites map[string]Satellite
 	mutex      sync.RWMutex
+	notifier   Notifier
 }
 
-func New() *Service {
-	// Inicializamos con las posiciones conocidas de los satélites
-	initialSatellites := map[string]Satellite{
-		"kenobi": {
+// DefaultConstellation devuelve los satélites conocidos con sus posiciones, sin lecturas
+func DefaultConstellation() []Satellite {
+	return []Satellite{
+		{
 			Name: "kenobi",
 			Position: Point{
 				X: -500,
 				Y: -200,
 			},
 		},
-		"skywalker": {
+		{
 			Name: "skywalker",
 			Position: Point{
 				X: 100,
 				Y: -100,
 			},
 		},
-		"sato": {
+		{
 			Name: "sato",
 			Position: Point{
 				X: 500,
@@ -65,38 +131,158 @@ func New() *Service {
 			},
 		},
 	}
+}
 
-	return &Service{
-		satellites: initialSatellites,
+func New() *Service {
+	// Inicializamos con las posiciones conocidas de los satélites
+	initialSatellites := DefaultConstellation()
+
+	s := &Service{
+		satellites: make(map[string]Satellite, len(initialSatellites)),
 		mutex:      sync.RWMutex{},
 	}
+	for _, satellite := range initialSatellites {
+		s.store(satellite, ChangeSaved)
+	}
+	return s
 }
 
-func (s *Service) GetSatellite(name string) (Satellite, error) {
+func (s *Service) GetSatellite(ctx context.Context, name string) (Satellite, error) {
+	if err := ctx.Err(); err != nil {
+		return Satellite{}, err
+	}
+
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
 	if satellite, exists := s.satellites[name]; exists {
-		return satellite, nil
+		return snapshot(satellite), nil
 	}
 	return Satellite{}, ErrSatelliteNotFound
 }
 
-func (s *Service) SaveSatellite(satellite Satellite) error {
+func (s *Service) SaveSatellite(ctx context.Context, satellite Satellite) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	s.satellites[satellite.Name] = satellite
+	s.store(satellite, ChangeSaved)
+	return nil
+}
+
+func (s *Service) SaveSatelliteIfVersion(ctx context.Context, satellite Satellite, version uint64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.satellites[satellite.Name].Version != version {
+		return ErrVersionConflict
+	}
+	s.store(satellite, ChangeSaved)
 	return nil
 }
 
-func (s *Service) GetAllSatellites() ([]Satellite, error) {
+func (s *Service) GetAllSatellites(ctx context.Context) ([]Satellite, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
 	satellites := make([]Satellite, 0, len(s.satellites))
 	for _, satellite := range s.satellites {
-		satellites = append(satellites, satellite)
+		satellites = append(satellites, snapshot(satellite))
 	}
+	slices.SortFunc(satellites, func(a, b Satellite) int {
+		return strings.Compare(a.Name, b.Name)
+	})
 	return satellites, nil
 }
+
+func (s *Service) ClearSatellite(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	satellite, exists := s.satellites[name]
+	if !exists {
+		return ErrSatelliteNotFound
+	}
+	s.store(clearReading(satellite), ChangeCleared)
+	return nil
+}
+
+func (s *Service) ClearAllSatellites(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, satellite := range s.satellites {
+		s.store(clearReading(satellite), ChangeCleared)
+	}
+	return nil
+}
+
+func (s *Service) ReplaceAllSatellites(ctx context.Context, satellites []Satellite) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous := s.satellites
+	s.satellites = make(map[string]Satellite, len(satellites))
+	for _, satellite := range satellites {
+		// Se conserva la numeración de versiones de los que ya existían para no repetir ETags
+		if old, exists := previous[satellite.Name]; exists {
+			s.satellites[satellite.Name] = old
+			delete(previous, satellite.Name)
+		}
+		s.store(satellite, ChangeSaved)
+	}
+	for _, removed := range previous {
+		s.notifier.Publish(Change{Kind: ChangeRemoved, Satellite: removed})
+	}
+	return nil
+}
+
+// clearReading devuelve el satélite sin distancia ni mensaje, manteniendo su posición
+func clearReading(satellite Satellite) Satellite {
+	satellite.Distance = 0
+	satellite.Message = nil
+	return satellite
+}
+
+func (s *Service) Subscribe(ctx context.Context, buffer int) (*Subscription, error) {
+	return s.notifier.Subscribe(ctx, buffer)
+}
+
+// store guarda el satélite con la versión siguiente a la almacenada y publica
+// el cambio; requiere el mutex tomado
+func (s *Service) store(satellite Satellite, kind ChangeKind) {
+	satellite = snapshot(satellite)
+	satellite.Version = s.satellites[satellite.Name].Version + 1
+	satellite.UpdatedAt = time.Now().UTC()
+	s.satellites[satellite.Name] = satellite
+	s.notifier.Publish(Change{Kind: kind, Satellite: satellite})
+}
+
+// snapshot copia el mensaje para que el satélite no comparta memoria con el almacenado
+func snapshot(satellite Satellite) Satellite {
+	satellite.Message = slices.Clone(satellite.Message)
+	return satellite
+}
@@ -0,0 +1,13 @@
+package repository_test
+
+import (
+	"fuegodequasar/internal/platform/repository"
+	"fuegodequasar/internal/platform/repository/repositorytest"
+	"testing"
+)
+
+func TestServiceConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.RepositoryService {
+		return repository.New()
+	})
+}
@@ -0,0 +1,167 @@
+// Package tlsconfig prepara la configuración TLS del servidor: el certificado
+// se recarga cuando cambian sus ficheros y, opcionalmente, se verifican los
+// certificados de cliente con una CA propia (mTLS).
+package tlsconfig
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Config son los ficheros de la configuración TLS
+type Config struct {
+	// CertFile y KeyFile son el certificado (con la cadena) y la clave en PEM
+	CertFile string
+	KeyFile  string
+	// ClientCAFile es el PEM con las CA de los certificados de cliente; vacío
+	// desactiva mTLS
+	ClientCAFile string
+	// RequireClientCert rechaza las conexiones sin certificado de cliente; si
+	// no, el certificado es opcional y solo se verifica cuando se presenta
+	RequireClientCert bool
+}
+
+// fileState identifica una versión de un fichero para detectar cambios
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Reloader sirve el certificado del servidor y lo recarga cuando cambian sus
+// ficheros, sin cortar las conexiones abiertas
+type Reloader struct {
+	certFile, keyFile string
+
+	mutex  sync.RWMutex
+	cert   *tls.Certificate
+	states [2]fileState
+}
+
+// NewReloader carga el certificado y la clave; falla si no se pueden cargar
+func NewReloader(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate devuelve el certificado en vigor; se usa como
+// tls.Config.GetCertificate
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.cert, nil
+}
+
+// Watch comprueba cada interval si han cambiado los ficheros y, si es así,
+// recarga el certificado hasta que se cancela ctx. Si la nueva versión no se
+// puede cargar, p. ej. porque solo se ha copiado uno de los dos ficheros, se
+// sigue sirviendo la anterior y se reintenta en la siguiente comprobación.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		states, err := r.stat()
+		if err != nil {
+			log.Printf("tls: failed to check certificate files: %v", err)
+			continue
+		}
+		r.mutex.RLock()
+		changed := states != r.states
+		r.mutex.RUnlock()
+		if !changed {
+			continue
+		}
+		if err := r.reload(); err != nil {
+			log.Printf("tls: failed to reload certificate, keeping the previous one: %v", err)
+			continue
+		}
+		log.Printf("tls: reloaded certificate from %s", r.certFile)
+	}
+}
+
+// reload carga los ficheros y sustituye el certificado en vigor
+func (r *Reloader) reload() error {
+	// El estado se toma antes de leer: si los ficheros cambian mientras se
+	// leen, la siguiente comprobación lo detecta y se vuelven a cargar
+	states, err := r.stat()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.cert = &cert
+	r.states = states
+	return nil
+}
+
+// stat devuelve el estado actual del certificado y de la clave
+func (r *Reloader) stat() ([2]fileState, error) {
+	var states [2]fileState
+	for i, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return states, err
+		}
+		states[i] = fileState{modTime: info.ModTime(), size: info.Size()}
+	}
+	return states, nil
+}
+
+// LoadCertPool lee un fichero PEM con uno o más certificados de CA
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if len(bytes.TrimSpace(data)) == 0 || !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s has no PEM certificates", path)
+	}
+	return pool, nil
+}
+
+// ServerConfig crea la configuración TLS del servidor con el certificado del
+// reloader y, si config tiene CA de clientes, con mTLS
+func ServerConfig(config Config, reloader *Reloader) (*tls.Config, error) {
+	serverConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+	if config.ClientCAFile == "" {
+		if config.RequireClientCert {
+			return nil, errors.New("requiring client certificates needs a client CA file")
+		}
+		return serverConfig, nil
+	}
+
+	pool, err := LoadCertPool(config.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	serverConfig.ClientCAs = pool
+	serverConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	if config.RequireClientCert {
+		serverConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return serverConfig, nil
+}
@@ -0,0 +1,315 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// issued es un certificado con su clave, en PEM y ya decodificado
+type issued struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// issue crea un certificado para name firmado por parent o, sin parent, una CA
+// autofirmada
+func issue(t *testing.T, name string, parent *issued) *issued {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey: %v", err)
+	}
+	return &issued{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+// writeFile escribe data en dir/name con la fecha de modificación modTime
+func writeFile(t *testing.T, dir, name string, data []byte, modTime time.Time) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+	return path
+}
+
+// leafName devuelve el CN del certificado que sirve el reloader
+func leafName(t *testing.T, r *Reloader) string {
+	t.Helper()
+	cert, err := r.GetCertificate(nil)
+	if err != nil || cert == nil {
+		t.Fatalf("GetCertificate() = %v, %v", cert, err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("ParseCertificate: %v", err)
+	}
+	return leaf.Subject.CommonName
+}
+
+func TestNewReloader(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	server, other := issue(t, "server", nil), issue(t, "other", nil)
+	certFile := writeFile(t, dir, "server.crt", server.certPEM, now)
+	keyFile := writeFile(t, dir, "server.key", server.keyPEM, now)
+	otherKeyFile := writeFile(t, dir, "other.key", other.keyPEM, now)
+
+	tests := []struct {
+		name     string
+		certFile string
+		keyFile  string
+		wantErr  bool
+	}{
+		{"valid", certFile, keyFile, false},
+		{"missing certificate", filepath.Join(dir, "missing.crt"), keyFile, true},
+		{"missing key", certFile, filepath.Join(dir, "missing.key"), true},
+		{"key of another certificate", certFile, otherKeyFile, true},
+		{"key as certificate", keyFile, keyFile, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewReloader(tt.certFile, tt.keyFile)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewReloader() = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && leafName(t, r) != "server" {
+				t.Fatalf("GetCertificate() served %q", leafName(t, r))
+			}
+		})
+	}
+}
+
+func TestReloaderWatch(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().Add(-time.Hour)
+	first, second := issue(t, "first", nil), issue(t, "second", nil)
+	certFile := writeFile(t, dir, "server.crt", first.certPEM, start)
+	keyFile := writeFile(t, dir, "server.key", first.keyPEM, start)
+
+	r, err := NewReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewReloader() = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Watch(ctx, 10*time.Millisecond)
+
+	// waitFor espera a que el reloader sirva el certificado name
+	waitFor := func(name string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for leafName(t, r) != name {
+			if time.Now().After(deadline) {
+				t.Fatalf("reloader serves %q, want %q", leafName(t, r), name)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// Con solo el certificado nuevo copiado la pareja no casa y se sigue
+	// sirviendo la anterior
+	writeFile(t, dir, "server.crt", second.certPEM, start.Add(time.Minute))
+	time.Sleep(50 * time.Millisecond)
+	if name := leafName(t, r); name != "first" {
+		t.Fatalf("reloader serves %q with a half-copied pair, want the previous one", name)
+	}
+
+	writeFile(t, dir, "server.key", second.keyPEM, start.Add(time.Minute))
+	waitFor("second")
+
+	// Si desaparecen los ficheros también se sigue sirviendo el último
+	if err := os.Remove(keyFile); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if name := leafName(t, r); name != "second" {
+		t.Fatalf("reloader serves %q without the key file, want the previous one", name)
+	}
+}
+
+func TestLoadCertPool(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	ca, other := issue(t, "ca", nil), issue(t, "other-ca", nil)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"one ca", ca.certPEM, false},
+		{"two cas", append(append([]byte{}, ca.certPEM...), other.certPEM...), false},
+		{"empty", nil, true},
+		{"blank", []byte("\n  \n"), true},
+		{"not pem", []byte("not a certificate"), true},
+		{"only a key", ca.keyPEM, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, dir, "ca.pem", tt.data, now)
+			if _, err := LoadCertPool(path); (err != nil) != tt.wantErr {
+				t.Fatalf("LoadCertPool() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := LoadCertPool(filepath.Join(dir, "missing.pem")); err == nil {
+		t.Fatal("LoadCertPool() of a missing file succeeded")
+	}
+}
+
+func TestServerConfig(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	serverCA, clientCA, otherCA := issue(t, "server-ca", nil), issue(t, "client-ca", nil), issue(t, "other-ca", nil)
+	server := issue(t, "localhost", serverCA)
+	r, err := NewReloader(writeFile(t, dir, "server.crt", server.certPEM, now), writeFile(t, dir, "server.key", server.keyPEM, now))
+	if err != nil {
+		t.Fatalf("NewReloader() = %v", err)
+	}
+	clientCAFile := writeFile(t, dir, "clients.pem", clientCA.certPEM, now)
+
+	// clientCert es el certificado de cliente de name firmado por ca
+	clientCert := func(name string, ca *issued) *tls.Certificate {
+		c := issue(t, name, ca)
+		cert, err := tls.X509KeyPair(c.certPEM, c.keyPEM)
+		if err != nil {
+			t.Fatalf("X509KeyPair: %v", err)
+		}
+		return &cert
+	}
+	kenobi, forged := clientCert("kenobi", clientCA), clientCert("kenobi", otherCA)
+
+	tests := []struct {
+		name          string
+		config        Config
+		client        *tls.Certificate
+		wantConfigErr bool
+		wantHandshake bool
+		wantVerified  bool
+	}{
+		{"no mtls", Config{}, nil, false, true, false},
+		// Sin CA de clientes no se pide certificado ni se verifica el que llegue
+		{"no mtls with client certificate", Config{}, kenobi, false, true, false},
+		{"optional without certificate", Config{ClientCAFile: clientCAFile}, nil, false, true, false},
+		{"optional with certificate", Config{ClientCAFile: clientCAFile}, kenobi, false, true, true},
+		{"optional with certificate of another ca", Config{ClientCAFile: clientCAFile}, forged, false, false, false},
+		{"required without certificate", Config{ClientCAFile: clientCAFile, RequireClientCert: true}, nil, false, false, false},
+		{"required with certificate", Config{ClientCAFile: clientCAFile, RequireClientCert: true}, kenobi, false, true, true},
+		{"required without ca", Config{RequireClientCert: true}, nil, true, false, false},
+		{"missing ca file", Config{ClientCAFile: filepath.Join(dir, "missing.pem")}, nil, true, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverConfig, err := ServerConfig(tt.config, r)
+			if (err != nil) != tt.wantConfigErr {
+				t.Fatalf("ServerConfig() = %v, want error %v", err, tt.wantConfigErr)
+			}
+			if err != nil {
+				return
+			}
+			if serverConfig.MinVersion != tls.VersionTLS12 {
+				t.Fatalf("MinVersion = %x, want TLS 1.2", serverConfig.MinVersion)
+			}
+
+			roots := x509.NewCertPool()
+			roots.AddCert(serverCA.cert)
+			clientConfig := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+			// El cliente presenta su certificado aunque no lo haya emitido una
+			// de las CA que anuncia el servidor
+			clientConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				if tt.client == nil {
+					return &tls.Certificate{}, nil
+				}
+				return tt.client, nil
+			}
+			state, err := handshake(serverConfig, clientConfig)
+			if (err == nil) != tt.wantHandshake {
+				t.Fatalf("handshake = %v, want success %v", err, tt.wantHandshake)
+			}
+			if err == nil && (len(state.VerifiedChains) > 0) != tt.wantVerified {
+				t.Fatalf("verified chains = %d, want verified %v", len(state.VerifiedChains), tt.wantVerified)
+			}
+		})
+	}
+}
+
+// handshake conecta un cliente y un servidor en memoria y devuelve el estado
+// de la conexión visto por el servidor
+func handshake(serverConfig, clientConfig *tls.Config) (tls.ConnectionState, error) {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	serverConn.SetDeadline(deadline)
+	clientConn.SetDeadline(deadline)
+
+	server, client := tls.Server(serverConn, serverConfig), tls.Client(clientConn, clientConfig)
+	clientErr := make(chan error, 1)
+	go func() {
+		err := client.Handshake()
+		if err == nil {
+			// En TLS 1.3 el cliente termina antes de que el servidor verifique
+			// su certificado; leer recoge el rechazo del servidor
+			_, err = client.Read(make([]byte, 1))
+		}
+		clientErr <- err
+	}()
+	err := server.Handshake()
+	if err == nil {
+		_, err = server.Write([]byte{0})
+	}
+	if cerr := <-clientErr; err == nil {
+		err = cerr
+	}
+	return server.ConnectionState(), err
+}